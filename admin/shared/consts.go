@@ -10,6 +10,17 @@ const PathUserCreate = "user-create"
 const PathUserUpdate = "user-update"
 const PathUserDelete = "user-delete"
 const PathUserImpersonate = "user-impersonate"
+const PathUserImpersonateStop = "user-impersonate-stop"
+const PathUserAPIKeyCreate = "user-api-key-create"
+const PathUserAPIKeyRevoke = "user-api-key-revoke"
+const PathUserExport = "user-export"
+const PathUserPasskeyRevoke = "user-passkey-revoke"
+const PathUserRestore = "user-restore"
+const PathUserSessionRevoke = "user-session-revoke"
+const PathUserSessionRevokeAll = "user-session-revoke-all"
+const PathUserSuspensionLift = "user-suspension-lift"
+const PathUserUnlock = "user-unlock"
+const PathUserWebhookRedeliver = "user-webhook-redeliver"
 
 var ScriptHtmx = `setTimeout(async function() {
 	if (!window.htmx) {
@@ -0,0 +1,22 @@
+package shared
+
+import (
+	"context"
+
+	"github.com/gouniverse/userstore"
+)
+
+// AuditContext returns a context carrying the authenticated user
+// as the actor and the request metadata for the audit log
+func AuditContext(config Config) context.Context {
+	ctx := context.Background()
+
+	if config.AuthUserID != nil {
+		ctx = userstore.WithActor(ctx, config.AuthUserID(config.Request))
+	}
+
+	return userstore.WithAuditMetadata(ctx, map[string]string{
+		"ip":         config.Request.RemoteAddr,
+		"user_agent": config.Request.UserAgent(),
+	})
+}
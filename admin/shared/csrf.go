@@ -0,0 +1,92 @@
+package shared
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gouniverse/hb"
+)
+
+// CsrfTokenField is the name of the form field carrying the CSRF token
+const CsrfTokenField = "csrf_token"
+
+// CsrfTokenHeader is the name of the header carrying the CSRF token,
+// sent by htmx with every request of the admin pages
+const CsrfTokenHeader = "X-CSRF-Token"
+
+const csrfCookieName = "userstore_admin_csrf"
+
+// CsrfToken returns the CSRF token of the browser, kept in a cookie,
+// which is created on the first visit (double submit cookie)
+func CsrfToken(config Config) string {
+	if cookie, err := config.Request.Cookie(csrfCookieName); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+
+	bytes := make([]byte, 32)
+
+	if _, err := rand.Read(bytes); err != nil {
+		config.Logger.Error("At CsrfToken", "error", err.Error())
+		return ""
+	}
+
+	cookie := &http.Cookie{
+		Name:     csrfCookieName,
+		Value:    hex.EncodeToString(bytes),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   config.Request.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	}
+
+	http.SetCookie(config.ResponseWriter, cookie)
+
+	// the token is reused for the rest of the request
+	config.Request.AddCookie(cookie)
+
+	return cookie.Value
+}
+
+// CsrfTokenValid returns whether the request is a POST, which carries
+// the CSRF token of the cookie in the form or in the header
+func CsrfTokenValid(config Config) bool {
+	if config.Request.Method != http.MethodPost {
+		return false
+	}
+
+	cookie, err := config.Request.Cookie(csrfCookieName)
+
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	token := config.Request.Header.Get(CsrfTokenHeader)
+
+	if token == "" {
+		token = config.Request.FormValue(CsrfTokenField)
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(cookie.Value)) == 1
+}
+
+// CsrfHxHeaders returns the value of the hx-headers attribute, which
+// makes htmx send the CSRF token with the requests of the descendants
+func CsrfHxHeaders(config Config) string {
+	headers, _ := json.Marshal(map[string]string{
+		CsrfTokenHeader: CsrfToken(config),
+	})
+
+	return string(headers)
+}
+
+// CsrfInput returns the hidden input carrying the CSRF token,
+// for the forms submitted without htmx
+func CsrfInput(config Config) hb.TagInterface {
+	return hb.Input().
+		Type(hb.TYPE_HIDDEN).
+		Name(CsrfTokenField).
+		Value(CsrfToken(config))
+}
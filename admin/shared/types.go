@@ -26,6 +26,19 @@ type Config struct {
 	AdminHomeURL   string
 	WebsiteUrl     string
 
+	// AuthUserID returns the ID of the authenticated user,
+	// required if impersonation is enabled
+	AuthUserID func(r *http.Request) string
+
+	// Impersonate switches the session to the user, optional,
+	// the impersonation is enabled only if set
+	Impersonate func(w http.ResponseWriter, r *http.Request, userID string) error
+
+	// ImpersonateStop switches the session back to the impersonator,
+	// and returns the IDs of the impersonator and the impersonated user.
+	// It is called on the confirmed (POST) requests of the stop page only.
+	ImpersonateStop func(w http.ResponseWriter, r *http.Request) (impersonatorID string, userID string, err error)
+
 	TokenizedColumns []string
 	// TokenCreate      func(columnName, columnValue string) (token string, err error)
 	// TokenDelete      func(token string) (err error)
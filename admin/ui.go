@@ -30,6 +30,16 @@ func UI(config shared.Config) (hb.TagInterface, error) {
 		return nil, errors.New("Layout is required")
 	}
 
+	if config.Impersonate != nil {
+		if config.AuthUserID == nil {
+			return nil, errors.New("AuthUserID function is required for impersonation")
+		}
+
+		if config.ImpersonateStop == nil {
+			return nil, errors.New("ImpersonateStop function is required for impersonation")
+		}
+	}
+
 	if len(config.TokenizedColumns) > 0 {
 		if config.TokensBulk == nil {
 			return nil, errors.New("TokensBulk function is required")
@@ -54,6 +64,14 @@ func handler(config shared.Config) hb.TagInterface {
 		return NewHomeController().ToTag(config)
 	}
 
+	if controller == shared.PathUserAPIKeyCreate {
+		return adminUsers.NewUserAPIKeyCreateController().ToTag(config)
+	}
+
+	if controller == shared.PathUserAPIKeyRevoke {
+		return adminUsers.NewUserAPIKeyRevokeController().ToTag(config)
+	}
+
 	if controller == shared.PathUserCreate {
 		return adminUsers.NewUserCreateController().ToTag(config)
 	}
@@ -62,6 +80,42 @@ func handler(config shared.Config) hb.TagInterface {
 		return adminUsers.NewUserDeleteController().ToTag(config)
 	}
 
+	if controller == shared.PathUserExport {
+		return adminUsers.NewUserExportController().ToTag(config)
+	}
+
+	if controller == shared.PathUserImpersonate {
+		return adminUsers.NewUserImpersonateController().ToTag(config)
+	}
+
+	if controller == shared.PathUserImpersonateStop {
+		return adminUsers.NewUserImpersonateStopController().ToTag(config)
+	}
+
+	if controller == shared.PathUserPasskeyRevoke {
+		return adminUsers.NewUserPasskeyRevokeController().ToTag(config)
+	}
+
+	if controller == shared.PathUserRestore {
+		return adminUsers.NewUserRestoreController().ToTag(config)
+	}
+
+	if controller == shared.PathUserSessionRevoke {
+		return adminUsers.NewUserSessionRevokeController().ToTag(config)
+	}
+
+	if controller == shared.PathUserSessionRevokeAll {
+		return adminUsers.NewUserSessionRevokeAllController().ToTag(config)
+	}
+
+	if controller == shared.PathUserSuspensionLift {
+		return adminUsers.NewUserSuspensionLiftController().ToTag(config)
+	}
+
+	if controller == shared.PathUserUnlock {
+		return adminUsers.NewUserUnlockController().ToTag(config)
+	}
+
 	if controller == shared.PathUserUpdate {
 		return adminUsers.NewUserUpdateController().ToTag(config)
 	}
@@ -70,6 +124,10 @@ func handler(config shared.Config) hb.TagInterface {
 		return adminUsers.NewUserManagerController().ToTag(config)
 	}
 
+	if controller == shared.PathUserWebhookRedeliver {
+		return adminUsers.NewUserWebhookRedeliverController().ToTag(config)
+	}
+
 	html := config.Layout(config.ResponseWriter, config.Request, shared.LayoutOptions{
 		Title: "Path not found",
 		Body:  hb.H1().HTML(controller).ToHTML(),
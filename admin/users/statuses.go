@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"github.com/gouniverse/form"
+	"github.com/gouniverse/userstore"
+	"github.com/gouniverse/userstore/admin/shared"
+	"github.com/samber/lo"
+)
+
+// statusOptions returns the select options of the statuses in the registry
+func statusOptions(config shared.Config) []form.FieldOption {
+	return lo.Map(config.Store.Statuses(), func(status userstore.UserStatus, _ int) form.FieldOption {
+		return form.FieldOption{
+			Value: lo.Ternary(status.Label != "", status.Label, status.Key),
+			Key:   status.Key,
+		}
+	})
+}
+
+// statusLabel returns the label of the status from the registry,
+// or the status itself if not found
+func statusLabel(config shared.Config, key string) string {
+	status, found := lo.Find(config.Store.Statuses(), func(status userstore.UserStatus) bool {
+		return status.Key == key
+	})
+
+	if !found || status.Label == "" {
+		return key
+	}
+
+	return status.Label
+}
@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore"
+	"github.com/gouniverse/userstore/admin/shared"
+	"github.com/gouniverse/utils"
+)
+
+// userActionPrepare finds the user of an action changing its state,
+// which is accepted only as a POST carrying a valid CSRF token
+func userActionPrepare(config shared.Config) (user userstore.UserInterface, errorMessage string) {
+	if !shared.CsrfTokenValid(config) {
+		return nil, "Invalid request. Please reload the page and try again."
+	}
+
+	userID := utils.Req(config.Request, "user_id", "")
+
+	if userID == "" {
+		return nil, "User ID is required"
+	}
+
+	user, err := config.Store.UserFindByID(context.Background(), userID)
+
+	if err != nil {
+		config.Logger.Error("At userActionPrepare", "error", err.Error())
+		return nil, "User not found"
+	}
+
+	if user == nil {
+		return nil, "User not found"
+	}
+
+	return user, ""
+}
+
+// userActionError returns the alert of a failed action
+func userActionError(errorMessage string) hb.TagInterface {
+	return hb.Swal(hb.SwalOptions{
+		Icon: "error",
+		Text: errorMessage,
+	})
+}
+
+// userActionSuccess returns the alert of a successful action,
+// reloading the page to show the changes
+func userActionSuccess(successMessage string) hb.TagInterface {
+	return hb.Wrap().
+		Child(hb.Swal(hb.SwalOptions{
+			Icon: "success",
+			Text: successMessage,
+		})).
+		Child(hb.Script("setTimeout(() => {window.location.href = window.location.href}, 2000)"))
+}
@@ -0,0 +1,64 @@
+package admin
+
+import (
+	"strings"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore"
+	"github.com/gouniverse/userstore/admin/shared"
+	"github.com/gouniverse/utils"
+	"github.com/spf13/cast"
+)
+
+// == CONTROLLER ==============================================================
+
+// userAPIKeyCreateController creates an API key for a user
+type userAPIKeyCreateController struct{}
+
+var _ shared.PageInterface = (*userAPIKeyCreateController)(nil)
+
+// == CONSTRUCTOR =============================================================
+
+func NewUserAPIKeyCreateController() *userAPIKeyCreateController {
+	return &userAPIKeyCreateController{}
+}
+
+func (controller userAPIKeyCreateController) ToTag(config shared.Config) hb.TagInterface {
+	user, errorMessage := userActionPrepare(config)
+
+	if errorMessage != "" {
+		return userActionError(errorMessage)
+	}
+
+	name := strings.TrimSpace(utils.Req(config.Request, "api_key_name", ""))
+	scopes := strings.Split(utils.Req(config.Request, "api_key_scopes", ""), ",")
+	expiresInDays := cast.ToInt(utils.Req(config.Request, "api_key_expires_in_days", "0"))
+
+	if name == "" {
+		return userActionError("API key name is required.")
+	}
+
+	apiKey := userstore.NewAPIKey().
+		SetUserID(user.ID()).
+		SetName(name).
+		SetScopes(scopes)
+
+	if expiresInDays > 0 {
+		apiKey.SetExpiresAt(carbon.Now(carbon.UTC).AddDays(expiresInDays).ToDateTimeString(carbon.UTC))
+	}
+
+	presented, err := config.Store.UserAPIKeyCreate(shared.AuditContext(config), apiKey)
+
+	if err != nil {
+		config.Logger.Error("At userAPIKeyCreateController > ToTag", "error", err.Error())
+		return userActionError("Creating API key failed. Please contact an administrator.")
+	}
+
+	// the key is shown once, the page is reloaded only after it is closed
+	return hb.Script(`Swal.fire({
+	icon: 'success',
+	title: 'API key created',
+	html: 'Copy the key now, it will not be shown again:<br><code>` + presented + `</code>',
+}).then(() => {window.location.href = window.location.href})`)
+}
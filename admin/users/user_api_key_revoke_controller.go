@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore"
+	"github.com/gouniverse/userstore/admin/shared"
+	"github.com/gouniverse/utils"
+	"github.com/samber/lo"
+)
+
+// == CONTROLLER ==============================================================
+
+// userAPIKeyRevokeController revokes an API key of a user
+type userAPIKeyRevokeController struct{}
+
+var _ shared.PageInterface = (*userAPIKeyRevokeController)(nil)
+
+// == CONSTRUCTOR =============================================================
+
+func NewUserAPIKeyRevokeController() *userAPIKeyRevokeController {
+	return &userAPIKeyRevokeController{}
+}
+
+func (controller userAPIKeyRevokeController) ToTag(config shared.Config) hb.TagInterface {
+	user, errorMessage := userActionPrepare(config)
+
+	if errorMessage != "" {
+		return userActionError(errorMessage)
+	}
+
+	apiKeyID := utils.Req(config.Request, "api_key_id", "")
+
+	apiKeys, err := config.Store.UserAPIKeyList(context.Background(), user.ID())
+
+	if err != nil {
+		config.Logger.Error("At userAPIKeyRevokeController > ToTag", "error", err.Error())
+		return userActionError("API keys failed to be read.")
+	}
+
+	_, found := lo.Find(apiKeys, func(apiKey userstore.APIKeyInterface) bool {
+		return apiKey.ID() == apiKeyID
+	})
+
+	if !found {
+		return userActionError("API key not found.")
+	}
+
+	err = config.Store.UserAPIKeyRevoke(shared.AuditContext(config), apiKeyID)
+
+	if err != nil {
+		config.Logger.Error("At userAPIKeyRevokeController > ToTag", "error", err.Error())
+		return userActionError("Revoking API key failed. Please contact an administrator.")
+	}
+
+	return userActionSuccess("API key revoked successfully.")
+}
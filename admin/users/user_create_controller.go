@@ -1,7 +1,6 @@
 package admin
 
 import (
-	"context"
 	"net/http"
 	"strings"
 
@@ -160,7 +159,7 @@ func (controller *userCreateController) prepareDataAndValidate(config shared.Con
 	user.SetLastName(data.lastName)
 	user.SetEmail(data.email)
 
-	err := config.Store.UserCreate(context.Background(), user)
+	err := config.Store.UserCreate(shared.AuditContext(config), user)
 
 	if err != nil {
 		config.Logger.Error("Error. At userCreateController > prepareDataAndValidate", "error", err.Error())
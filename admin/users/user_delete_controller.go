@@ -143,7 +143,7 @@ func (controller *userDeleteController) prepareDataAndValidate(config shared.Con
 		return data, ""
 	}
 
-	err = config.Store.UserSoftDelete(context.Background(), user)
+	err = config.Store.UserSoftDelete(shared.AuditContext(config), user)
 
 	if err != nil {
 		config.Logger.Error("Error. At userDeleteController > prepareDataAndValidate", "error", err.Error())
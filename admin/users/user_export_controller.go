@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore"
+	"github.com/gouniverse/userstore/admin/shared"
+	"github.com/samber/lo"
+)
+
+// == CONTROLLER ==============================================================
+
+// userExportController sends the personal data of a user as a ZIP
+// download, with the tokenized columns detokenized
+type userExportController struct{}
+
+var _ shared.PageInterface = (*userExportController)(nil)
+
+// == CONSTRUCTOR =============================================================
+
+func NewUserExportController() *userExportController {
+	return &userExportController{}
+}
+
+func (controller userExportController) ToTag(config shared.Config) hb.TagInterface {
+	errorMessage := controller.checkAndProcess(config)
+
+	if errorMessage == "" {
+		return hb.Raw("") // the ZIP is already written
+	}
+
+	layout := config.Layout(config.ResponseWriter, config.Request, shared.LayoutOptions{
+		Title: `Export User | User Manager`,
+		Body: hb.Div().
+			Class("container").
+			Child(hb.Div().
+				Class("alert alert-danger").
+				Text(errorMessage)).
+			ToHTML(),
+	})
+
+	return hb.Raw(layout)
+}
+
+func (controller userExportController) checkAndProcess(config shared.Config) (errorMessage string) {
+	user, errorMessage := userActionPrepare(config)
+
+	if errorMessage != "" {
+		return errorMessage
+	}
+
+	errorMessage = "Exporting personal data failed. Please contact an administrator."
+
+	export, err := config.Store.UserExportPersonalData(shared.AuditContext(config), user.ID())
+
+	if err != nil {
+		config.Logger.Error("At userExportController > checkAndProcess", "error", err.Error())
+		return errorMessage
+	}
+
+	untokenized, err := userUntokenize(config, userstore.NewUserFromExistingData(export.User))
+
+	if err != nil {
+		config.Logger.Error("At userExportController > checkAndProcess", "error", err.Error())
+		return errorMessage
+	}
+
+	export.User = lo.Assign(export.User, untokenized)
+
+	exportZip, err := export.ToZip()
+
+	if err != nil {
+		config.Logger.Error("At userExportController > checkAndProcess", "error", err.Error())
+		return errorMessage
+	}
+
+	config.ResponseWriter.Header().Set("Content-Type", "application/zip")
+	config.ResponseWriter.Header().Set("Content-Disposition", `attachment; filename="personal_data_`+user.ID()+`.zip"`)
+
+	if _, err := config.ResponseWriter.Write(exportZip); err != nil {
+		config.Logger.Error("At userExportController > checkAndProcess", "error", err.Error())
+	}
+
+	return ""
+}
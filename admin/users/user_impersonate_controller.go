@@ -1,54 +1,112 @@
 package admin
 
-// import (
-// 	"net/http"
+import (
+	"errors"
+	"net/http"
 
-// 	"project/internal/authentication"
-// 	"project/internal/helpers"
-// 	"project/internal/links"
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore"
+	"github.com/gouniverse/userstore/admin/shared"
+	"github.com/gouniverse/utils"
+)
 
-// 	"github.com/gouniverse/router"
-// 	"github.com/gouniverse/utils"
-// )
+// == CONTROLLER ==============================================================
 
-// // == CONTROLLER ==============================================================
+// userImpersonateController starts the impersonation of a user
+type userImpersonateController struct{}
 
-// // userImpersonateController represents a controller for handling user impersonation.
-// type userImpersonateController struct{}
+var _ shared.PageInterface = (*userImpersonateController)(nil)
 
-// // == CONSTRUCTOR =============================================================
+// == CONSTRUCTOR =============================================================
 
-// func NewUserImpersonateController() *userImpersonateController {
-// 	return &userImpersonateController{}
-// }
+func NewUserImpersonateController() *userImpersonateController {
+	return &userImpersonateController{}
+}
 
-// var _ router.HTMLControllerInterface = (*userImpersonateController)(nil)
+func (c *userImpersonateController) ToTag(config shared.Config) hb.TagInterface {
+	html := c.checkAndProcess(config)
 
-// // == PUBLIC METHODS ==========================================================
+	if html == "" {
+		return hb.Raw("") // redirected to the website
+	}
 
-// func (c *userImpersonateController) Handler(w http.ResponseWriter, r *http.Request) string {
-// 	authUser := helpers.GetAuthUser(r)
+	layout := config.Layout(config.ResponseWriter, config.Request, shared.LayoutOptions{
+		Title: `Impersonate User | User Manager`,
+		Body:  html,
+	})
 
-// 	if authUser == nil {
-// 		return helpers.ToFlashError(w, r, "User not found", links.NewAdminLinks().Users(map[string]string{}), 15)
-// 	}
+	return hb.Raw(layout)
+}
 
-// 	if !authUser.IsAdministrator() {
-// 		return helpers.ToFlashError(w, r, "Not authorized", links.NewAdminLinks().Users(map[string]string{}), 15)
-// 	}
+func (controller userImpersonateController) checkAndProcess(config shared.Config) string {
+	if config.Impersonate == nil || config.AuthUserID == nil {
+		return controller.alertError(config, "Impersonation is not enabled").ToHTML()
+	}
 
-// 	userID := utils.Req(r, "user_id", "")
+	// the session is switched only by the forms of the admin pages
+	if !shared.CsrfTokenValid(config) {
+		return controller.alertError(config, "Invalid request. Please reload the page and try again.").ToHTML()
+	}
 
-// 	if userID == "" {
-// 		return helpers.ToFlashError(w, r, "User ID not found", links.NewAdminLinks().Users(map[string]string{}), 15)
-// 	}
+	authUserID := config.AuthUserID(config.Request)
 
-// 	err := authentication.Impersonate(w, r, userID)
+	if authUserID == "" {
+		return controller.alertError(config, "Not authorized").ToHTML()
+	}
 
-// 	if err != nil {
-// 		return helpers.ToFlashError(w, r, err.Error(), links.NewAdminLinks().Users(map[string]string{}), 15)
-// 	}
+	userID := utils.Req(config.Request, "user_id", "")
 
-// 	return helpers.ToFlashSuccess(w, r, "Impersonation is successful", links.NewUserLinks().Home(map[string]string{}), 15)
+	if userID == "" {
+		return controller.alertError(config, "User ID is required").ToHTML()
+	}
 
-// }
+	err := config.Store.UserImpersonationStart(shared.AuditContext(config), authUserID, userID)
+
+	if errors.Is(err, userstore.ErrImpersonationNotAllowed) {
+		return controller.alertError(config, "Not authorized to impersonate this user").ToHTML()
+	}
+
+	if errors.Is(err, userstore.ErrUserNotFound) {
+		return controller.alertError(config, "User not found").ToHTML()
+	}
+
+	if err != nil {
+		config.Logger.Error("At userImpersonateController > checkAndProcess", "error", err.Error())
+		return controller.alertError(config, "Impersonation failed. Please contact an administrator.").ToHTML()
+	}
+
+	err = config.Impersonate(config.ResponseWriter, config.Request, userID)
+
+	if err != nil {
+		config.Logger.Error("At userImpersonateController > checkAndProcess", "error", err.Error())
+
+		// the session was not switched, close the audit trail
+		if errStop := config.Store.UserImpersonationStop(shared.AuditContext(config), authUserID, userID); errStop != nil {
+			config.Logger.Error("At userImpersonateController > checkAndProcess", "error", errStop.Error())
+		}
+
+		return controller.alertError(config, "Impersonation failed. Please contact an administrator.").ToHTML()
+	}
+
+	redirectURL := config.WebsiteUrl
+
+	if redirectURL == "" {
+		redirectURL = "/"
+	}
+
+	http.Redirect(config.ResponseWriter, config.Request, redirectURL, http.StatusSeeOther)
+
+	return ""
+}
+
+func (controller userImpersonateController) alertError(config shared.Config, errorMessage string) hb.TagInterface {
+	return hb.Div().
+		Class("alert alert-danger").
+		Text(errorMessage).
+		Child(hb.Div().
+			Class("mt-3").
+			Child(hb.Hyperlink().
+				Class("btn btn-secondary").
+				HTML("Back to users").
+				Href(shared.Url(config.Request, shared.PathUsers, nil))))
+}
@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore/admin/shared"
+)
+
+// == CONTROLLER ==============================================================
+
+// userImpersonateStopController stops the impersonation and switches
+// back to the impersonator
+type userImpersonateStopController struct{}
+
+var _ shared.PageInterface = (*userImpersonateStopController)(nil)
+
+// == CONSTRUCTOR =============================================================
+
+func NewUserImpersonateStopController() *userImpersonateStopController {
+	return &userImpersonateStopController{}
+}
+
+func (c *userImpersonateStopController) ToTag(config shared.Config) hb.TagInterface {
+	html := c.checkAndProcess(config)
+
+	if html == "" {
+		return hb.Raw("") // redirected to the users
+	}
+
+	layout := config.Layout(config.ResponseWriter, config.Request, shared.LayoutOptions{
+		Title: `Stop Impersonation | User Manager`,
+		Body:  html,
+	})
+
+	return hb.Raw(layout)
+}
+
+func (controller userImpersonateStopController) checkAndProcess(config shared.Config) string {
+	if config.ImpersonateStop == nil {
+		return hb.Div().
+			Class("alert alert-danger").
+			Text("Impersonation is not enabled").
+			ToHTML()
+	}
+
+	// a link (i.e. in the website banner) only shows the confirmation,
+	// the session is switched back by its form
+	if config.Request.Method != http.MethodPost {
+		return controller.formConfirm(config).ToHTML()
+	}
+
+	if !shared.CsrfTokenValid(config) {
+		return hb.Div().
+			Class("alert alert-danger").
+			Text("Invalid request. Please reload the page and try again.").
+			ToHTML()
+	}
+
+	impersonatorID, userID, err := config.ImpersonateStop(config.ResponseWriter, config.Request)
+
+	if err != nil {
+		config.Logger.Error("At userImpersonateStopController > checkAndProcess", "error", err.Error())
+		return hb.Div().
+			Class("alert alert-danger").
+			Text("Stopping impersonation failed. Please contact an administrator.").
+			ToHTML()
+	}
+
+	err = config.Store.UserImpersonationStop(shared.AuditContext(config), impersonatorID, userID)
+
+	if err != nil {
+		// the session is already switched back, only the audit failed
+		config.Logger.Error("At userImpersonateStopController > checkAndProcess", "error", err.Error())
+	}
+
+	http.Redirect(config.ResponseWriter, config.Request, shared.Url(config.Request, shared.PathUsers, nil), http.StatusSeeOther)
+
+	return ""
+}
+
+func (controller userImpersonateStopController) formConfirm(config shared.Config) hb.TagInterface {
+	// a plain form submit, as the whole page is switched back
+	return hb.Form().
+		Attr("method", http.MethodPost).
+		Attr("action", shared.Url(config.Request, shared.PathUserImpersonateStop, nil)).
+		Child(shared.CsrfInput(config)).
+		Child(hb.Paragraph().
+			Text("Stop impersonating the user and switch back to your account?")).
+		Child(hb.Button().
+			Type("submit").
+			Class("btn btn-warning").
+			Child(hb.I().Class("bi bi-shuffle me-2")).
+			HTML("Stop Impersonation"))
+}
@@ -100,28 +100,12 @@ func (controller *userManagerController) onModalUserFilterShow(data userManagerC
 				Type:  form.FORM_FIELD_TYPE_SELECT,
 				Help:  `The status of the user.`,
 				Value: data.formStatus,
-				Options: []form.FieldOption{
+				Options: append([]form.FieldOption{
 					{
 						Value: "",
 						Key:   "",
 					},
-					{
-						Value: "Active",
-						Key:   userstore.USER_STATUS_ACTIVE,
-					},
-					{
-						Value: "Inactive",
-						Key:   userstore.USER_STATUS_INACTIVE,
-					},
-					{
-						Value: "Unverified",
-						Key:   userstore.USER_STATUS_UNVERIFIED,
-					},
-					{
-						Value: "Deleted",
-						Key:   userstore.USER_STATUS_DELETED,
-					},
-				},
+				}, statusOptions(data.config)...),
 			}),
 			form.NewField(form.FieldOptions{
 				Label: "First Name",
@@ -225,6 +209,7 @@ func (controller *userManagerController) page(data userManagerControllerData) hb
 
 	return hb.Div().
 		Class("container").
+		Attr("hx-headers", shared.CsrfHxHeaders(data.config)).
 		Child(breadcrumbs).
 		Child(hb.HR()).
 		Child(title).
@@ -299,7 +284,7 @@ func (controller *userManagerController) tableUsers(data userManagerControllerDa
 					StyleIf(user.IsSoftDeleted(), `color:silver;`).
 					StyleIf(user.IsUnverified(), `color:blue;`).
 					StyleIf(user.IsInactive(), `color:red;`).
-					HTML(user.Status())
+					Text(statusLabel(data.config, user.Status()))
 
 				buttonEdit := hb.Hyperlink().
 					Class("btn btn-primary me-2").
@@ -316,11 +301,52 @@ func (controller *userManagerController) tableUsers(data userManagerControllerDa
 					HxTarget("body").
 					HxSwap("beforeend")
 
-				buttonImpersonate := hb.Hyperlink().
-					Class("btn btn-warning me-2").
-					Child(hb.I().Class("bi bi-shuffle")).
-					Title("Impersonate").
-					Href(shared.Url(data.config.Request, shared.PathUserImpersonate, map[string]string{"user_id": user.ID()}))
+				// a plain form submit, as the whole page is switched to the website
+				formImpersonate := hb.Form().
+					Class("d-inline").
+					Attr("method", http.MethodPost).
+					Attr("action", shared.Url(data.config.Request, shared.PathUserImpersonate, map[string]string{
+						"user_id": user.ID(),
+					})).
+					Child(shared.CsrfInput(data.config)).
+					Child(hb.Button().
+						Type("submit").
+						Class("btn btn-warning me-2").
+						Child(hb.I().Class("bi bi-shuffle")).
+						Title("Impersonate"))
+
+				buttonRestore := hb.Button().
+					Class("btn btn-success").
+					Child(hb.I().Class("bi bi-arrow-counterclockwise")).
+					Title("Restore").
+					HxPost(shared.Url(data.config.Request, shared.PathUserRestore, map[string]string{
+						"user_id": user.ID(),
+					})).
+					HxTarget("body").
+					HxSwap("beforeend")
+
+				actions := hb.TD().
+					Child(buttonEdit)
+
+				if user.IsSoftDeleted() {
+					actions.Child(buttonRestore)
+				} else {
+					// impersonation is available only if enabled by the application
+					if data.config.Impersonate != nil {
+						actions.Child(formImpersonate)
+					}
+
+					actions.Child(buttonDelete)
+				}
+
+				statusCell := hb.TD().
+					Child(status)
+
+				if user.IsSoftDeleted() {
+					statusCell.Child(hb.Div().
+						Style("font-size: 11px;").
+						Text("Deleted: " + user.SoftDeletedAtCarbon().Format("d M Y")))
+				}
 
 				return hb.TR().Children([]hb.TagInterface{
 					hb.TD().
@@ -329,8 +355,7 @@ func (controller *userManagerController) tableUsers(data userManagerControllerDa
 							Style("font-size: 11px;").
 							HTML("Ref: ").
 							HTML(user.ID())),
-					hb.TD().
-						Child(status),
+					statusCell,
 					hb.TD().
 						Child(hb.Div().
 							Style("font-size: 13px;white-space: nowrap;").
@@ -343,10 +368,7 @@ func (controller *userManagerController) tableUsers(data userManagerControllerDa
 						Child(hb.Div().
 							Style("font-size: 13px;white-space: nowrap;").
 							HTML(user.UpdatedAtCarbon().Format("d M Y"))),
-					hb.TD().
-						Child(buttonEdit).
-						Child(buttonImpersonate).
-						Child(buttonDelete),
+					actions,
 				})
 			})),
 		})
@@ -374,13 +396,14 @@ func (controller *userManagerController) sortableColumnLabel(
 	}
 
 	link := shared.Url(data.config.Request, shared.PathUsers, map[string]string{
-		"page":      "0",
-		"by":        columnName,
-		"sort":      direction,
-		"date_from": data.formCreatedFrom,
-		"date_to":   data.formCreatedTo,
-		"status":    data.formStatus,
-		"user_id":   data.formUserID,
+		"page":         "0",
+		"by":           columnName,
+		"sort":         direction,
+		"date_from":    data.formCreatedFrom,
+		"date_to":      data.formCreatedTo,
+		"status":       data.formStatus,
+		"user_id":      data.formUserID,
+		"show_deleted": data.formShowDeleted,
 	})
 	return hb.Hyperlink().
 		HTML(tableLabel).
@@ -423,16 +446,32 @@ func (controller *userManagerController) tableFilter(data userManagerControllerD
 			"user_id":      data.formUserID,
 			"created_from": data.formCreatedFrom,
 			"created_to":   data.formCreatedTo,
+			"show_deleted": data.formShowDeleted,
 		})).
 		HxTarget("body").
 		HxSwap("beforeend")
 
+	buttonShowDeleted := hb.Hyperlink().
+		Class(lo.Ternary(data.formShowDeleted == "yes", "btn btn-sm btn-secondary me-2", "btn btn-sm btn-outline-secondary me-2")).
+		Style("margin-bottom: 2px; margin-left:2px; margin-right:2px;").
+		Child(hb.I().Class("bi bi-trash me-2")).
+		Text(lo.Ternary(data.formShowDeleted == "yes", "Hide deleted", "Show deleted")).
+		Href(shared.Url(data.config.Request, shared.PathUsers, map[string]string{
+			"first_name":   data.formFirstName,
+			"last_name":    data.formLastName,
+			"email":        data.formEmail,
+			"status":       data.formStatus,
+			"created_from": data.formCreatedFrom,
+			"created_to":   data.formCreatedTo,
+			"show_deleted": lo.Ternary(data.formShowDeleted == "yes", "", "yes"),
+		}))
+
 	description := []string{
 		hb.Span().HTML("Showing users").Text(" ").ToHTML(),
 	}
 
 	if data.formStatus != "" {
-		description = append(description, hb.Span().Text("with status: "+data.formStatus).ToHTML())
+		description = append(description, hb.Span().Text("with status: "+statusLabel(data.config, data.formStatus)).ToHTML())
 	} else {
 		description = append(description, hb.Span().Text("with status: any").ToHTML())
 	}
@@ -453,6 +492,10 @@ func (controller *userManagerController) tableFilter(data userManagerControllerD
 		description = append(description, hb.Span().Text("and last name: "+data.formLastName).ToHTML())
 	}
 
+	if data.formShowDeleted == "yes" {
+		description = append(description, hb.Span().Text("including deleted").ToHTML())
+	}
+
 	if data.formCreatedFrom != "" && data.formCreatedTo != "" {
 		description = append(description, hb.Span().Text("and created between: "+data.formCreatedFrom+" and "+data.formCreatedTo).ToHTML())
 	} else if data.formCreatedFrom != "" {
@@ -467,6 +510,7 @@ func (controller *userManagerController) tableFilter(data userManagerControllerD
 		Children([]hb.TagInterface{
 			hb.Div().Class("card-body").
 				Child(buttonFilter).
+				Child(buttonShowDeleted).
 				Child(hb.Span().
 					HTML(strings.Join(description, " "))),
 		})
@@ -480,6 +524,7 @@ func (controller *userManagerController) tablePagination(data userManagerControl
 		"email":        data.formEmail,
 		"created_from": data.formCreatedFrom,
 		"created_to":   data.formCreatedTo,
+		"show_deleted": data.formShowDeleted,
 		"by":           data.sortBy,
 		"order":        data.sortOrder,
 	})
@@ -514,6 +559,7 @@ func (controller *userManagerController) prepareData(config shared.Config) (data
 	data.formStatus = utils.Req(config.Request, "status", "")
 	data.formCreatedFrom = utils.Req(config.Request, "created_from", "")
 	data.formCreatedTo = utils.Req(config.Request, "created_to", "")
+	data.formShowDeleted = utils.Req(config.Request, "show_deleted", "")
 
 	userList, userCount, err := controller.fetchUserList(data)
 
@@ -595,6 +641,10 @@ func (controller *userManagerController) fetchUserList(data userManagerControlle
 		query = query.SetStatus(data.formStatus)
 	}
 
+	if data.formShowDeleted == "yes" {
+		query = query.SetSoftDeletedIncluded(true)
+	}
+
 	query = query.SetSortDirection(data.sortOrder)
 
 	query = query.SetOrderBy(data.sortBy)
@@ -642,6 +692,7 @@ type userManagerControllerData struct {
 	formLastName    string
 	formCreatedFrom string
 	formCreatedTo   string
+	formShowDeleted string
 	formUserID      string
 	userList        []userstore.UserInterface
 	userCount       int64
@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore"
+	"github.com/gouniverse/userstore/admin/shared"
+	"github.com/gouniverse/utils"
+	"github.com/samber/lo"
+)
+
+// == CONTROLLER ==============================================================
+
+// userPasskeyRevokeController revokes a passkey of a user
+type userPasskeyRevokeController struct{}
+
+var _ shared.PageInterface = (*userPasskeyRevokeController)(nil)
+
+// == CONSTRUCTOR =============================================================
+
+func NewUserPasskeyRevokeController() *userPasskeyRevokeController {
+	return &userPasskeyRevokeController{}
+}
+
+func (controller userPasskeyRevokeController) ToTag(config shared.Config) hb.TagInterface {
+	user, errorMessage := userActionPrepare(config)
+
+	if errorMessage != "" {
+		return userActionError(errorMessage)
+	}
+
+	passkeyID := utils.Req(config.Request, "passkey_id", "")
+
+	passkeys, err := config.Store.UserPasskeyList(context.Background(), user.ID())
+
+	if err != nil {
+		config.Logger.Error("At userPasskeyRevokeController > ToTag", "error", err.Error())
+		return userActionError("Passkeys failed to be read.")
+	}
+
+	_, found := lo.Find(passkeys, func(passkey userstore.PasskeyInterface) bool {
+		return passkey.ID() == passkeyID
+	})
+
+	if !found {
+		return userActionError("Passkey not found.")
+	}
+
+	err = config.Store.UserPasskeyRevoke(shared.AuditContext(config), passkeyID)
+
+	if err != nil {
+		config.Logger.Error("At userPasskeyRevokeController > ToTag", "error", err.Error())
+		return userActionError("Revoking passkey failed. Please contact an administrator.")
+	}
+
+	return userActionSuccess("Passkey revoked successfully.")
+}
@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"errors"
+
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore"
+	"github.com/gouniverse/userstore/admin/shared"
+	"github.com/gouniverse/utils"
+)
+
+// == CONTROLLER ==============================================================
+
+// userRestoreController restores a soft deleted user
+type userRestoreController struct{}
+
+var _ shared.PageInterface = (*userRestoreController)(nil)
+
+// == CONSTRUCTOR =============================================================
+
+func NewUserRestoreController() *userRestoreController {
+	return &userRestoreController{}
+}
+
+func (controller userRestoreController) ToTag(config shared.Config) hb.TagInterface {
+	// the soft deleted users are not found by userActionPrepare
+	if !shared.CsrfTokenValid(config) {
+		return userActionError("Invalid request. Please reload the page and try again.")
+	}
+
+	userID := utils.Req(config.Request, "user_id", "")
+
+	if userID == "" {
+		return userActionError("User ID is required")
+	}
+
+	err := config.Store.UserRestoreByID(shared.AuditContext(config), userID)
+
+	if errors.Is(err, userstore.ErrEmailTaken) {
+		return userActionError("The email of the user is taken by another user. Change the email of the other user first.")
+	}
+
+	if errors.Is(err, userstore.ErrUserNotFound) {
+		return userActionError("User not found")
+	}
+
+	if err != nil {
+		config.Logger.Error("At userRestoreController > ToTag", "error", err.Error())
+		return userActionError("Restoring user failed. Please contact an administrator.")
+	}
+
+	return userActionSuccess("User restored successfully.")
+}
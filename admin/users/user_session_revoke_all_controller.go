@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore/admin/shared"
+)
+
+// == CONTROLLER ==============================================================
+
+// userSessionRevokeAllController ends all the sessions of a user
+type userSessionRevokeAllController struct{}
+
+var _ shared.PageInterface = (*userSessionRevokeAllController)(nil)
+
+// == CONSTRUCTOR =============================================================
+
+func NewUserSessionRevokeAllController() *userSessionRevokeAllController {
+	return &userSessionRevokeAllController{}
+}
+
+func (controller userSessionRevokeAllController) ToTag(config shared.Config) hb.TagInterface {
+	user, errorMessage := userActionPrepare(config)
+
+	if errorMessage != "" {
+		return userActionError(errorMessage)
+	}
+
+	err := config.Store.UserSessionRevokeAllExcept(shared.AuditContext(config), user.ID(), "")
+
+	if err != nil {
+		config.Logger.Error("At userSessionRevokeAllController > ToTag", "error", err.Error())
+		return userActionError("Ending sessions failed. Please contact an administrator.")
+	}
+
+	return userActionSuccess("All sessions ended successfully.")
+}
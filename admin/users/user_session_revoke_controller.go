@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore"
+	"github.com/gouniverse/userstore/admin/shared"
+	"github.com/gouniverse/utils"
+	"github.com/samber/lo"
+)
+
+// == CONTROLLER ==============================================================
+
+// userSessionRevokeController ends a session of a user
+type userSessionRevokeController struct{}
+
+var _ shared.PageInterface = (*userSessionRevokeController)(nil)
+
+// == CONSTRUCTOR =============================================================
+
+func NewUserSessionRevokeController() *userSessionRevokeController {
+	return &userSessionRevokeController{}
+}
+
+func (controller userSessionRevokeController) ToTag(config shared.Config) hb.TagInterface {
+	user, errorMessage := userActionPrepare(config)
+
+	if errorMessage != "" {
+		return userActionError(errorMessage)
+	}
+
+	sessionID := utils.Req(config.Request, "session_id", "")
+
+	sessions, err := config.Store.UserSessionList(context.Background(), user.ID())
+
+	if err != nil {
+		config.Logger.Error("At userSessionRevokeController > ToTag", "error", err.Error())
+		return userActionError("Sessions failed to be read.")
+	}
+
+	_, found := lo.Find(sessions, func(session userstore.SessionInterface) bool {
+		return session.ID() == sessionID
+	})
+
+	if !found {
+		return userActionError("Session not found.")
+	}
+
+	err = config.Store.UserSessionRevoke(shared.AuditContext(config), sessionID)
+
+	if err != nil {
+		config.Logger.Error("At userSessionRevokeController > ToTag", "error", err.Error())
+		return userActionError("Ending session failed. Please contact an administrator.")
+	}
+
+	return userActionSuccess("Session ended successfully.")
+}
@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore/admin/shared"
+)
+
+// == CONTROLLER ==============================================================
+
+// userSuspensionLiftController lifts the suspension of a user
+type userSuspensionLiftController struct{}
+
+var _ shared.PageInterface = (*userSuspensionLiftController)(nil)
+
+// == CONSTRUCTOR =============================================================
+
+func NewUserSuspensionLiftController() *userSuspensionLiftController {
+	return &userSuspensionLiftController{}
+}
+
+func (controller userSuspensionLiftController) ToTag(config shared.Config) hb.TagInterface {
+	user, errorMessage := userActionPrepare(config)
+
+	if errorMessage != "" {
+		return userActionError(errorMessage)
+	}
+
+	err := config.Store.UserSuspensionLift(shared.AuditContext(config), user)
+
+	if err != nil {
+		config.Logger.Error("At userSuspensionLiftController > ToTag", "error", err.Error())
+		return userActionError("Lifting suspension failed. Please contact an administrator.")
+	}
+
+	return userActionSuccess("Suspension lifted successfully.")
+}
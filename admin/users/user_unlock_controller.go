@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore/admin/shared"
+)
+
+// == CONTROLLER ==============================================================
+
+// userUnlockController lifts the lockout of a user after failed logins
+type userUnlockController struct{}
+
+var _ shared.PageInterface = (*userUnlockController)(nil)
+
+// == CONSTRUCTOR =============================================================
+
+func NewUserUnlockController() *userUnlockController {
+	return &userUnlockController{}
+}
+
+func (controller userUnlockController) ToTag(config shared.Config) hb.TagInterface {
+	user, errorMessage := userActionPrepare(config)
+
+	if errorMessage != "" {
+		return userActionError(errorMessage)
+	}
+
+	err := config.Store.UserUnlock(shared.AuditContext(config), user)
+
+	if err != nil {
+		config.Logger.Error("At userUnlockController > ToTag", "error", err.Error())
+		return userActionError("Unlocking user failed. Please contact an administrator.")
+	}
+
+	return userActionSuccess("User unlocked successfully.")
+}
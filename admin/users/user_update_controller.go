@@ -10,12 +10,17 @@ import (
 	"github.com/asaskevich/govalidator"
 	"github.com/gouniverse/form"
 	"github.com/gouniverse/hb"
+	"github.com/gouniverse/sb"
 	"github.com/gouniverse/userstore"
 	"github.com/gouniverse/userstore/admin/shared"
 	"github.com/gouniverse/utils"
 	"github.com/samber/lo"
+	"github.com/spf13/cast"
 )
 
+const ViewHistory = "history"
+const ViewWebhooks = "webhooks"
+
 // == CONTROLLER ==============================================================
 
 type userUpdateController struct{}
@@ -64,6 +69,58 @@ func (controller userUpdateController) checkAndProcess(config shared.Config) (ht
 	return controller.page(data).ToHTML(), true
 }
 
+func (controller userUpdateController) lockedAlert(data userUpdateControllerData) hb.TagInterface {
+	buttonUnlock := hb.Button().
+		Class("btn btn-sm btn-warning float-end").
+		Child(hb.I().Class("bi bi-unlock me-2")).
+		HTML("Unlock").
+		HxPost(shared.Url(data.config.Request, shared.PathUserUnlock, map[string]string{
+			"user_id": data.userID,
+		})).
+		HxTarget("body").
+		HxSwap("beforeend")
+
+	return hb.Div().
+		Class("alert alert-warning").
+		Child(buttonUnlock).
+		Text("This user is locked out after too many failed logins until ").
+		Text(data.user.LockedUntilCarbon().Format("d M Y H:i:s")).
+		Text(" (UTC).")
+}
+
+func (controller userUpdateController) suspendedAlert(data userUpdateControllerData) hb.TagInterface {
+	buttonLift := hb.Button().
+		Class("btn btn-sm btn-danger float-end").
+		Child(hb.I().Class("bi bi-person-check me-2")).
+		HTML("Lift Suspension").
+		HxPost(shared.Url(data.config.Request, shared.PathUserSuspensionLift, map[string]string{
+			"user_id": data.userID,
+		})).
+		HxTarget("body").
+		HxSwap("beforeend")
+
+	until := lo.Ternary(strings.Contains(data.user.SuspendedUntil(), sb.MAX_DATETIME),
+		"indefinitely",
+		"until "+data.user.SuspendedUntilCarbon().Format("d M Y H:i:s")+" (UTC)")
+
+	alert := hb.Div().
+		Class("alert alert-danger").
+		Child(buttonLift).
+		Text("This user is suspended ").
+		Text(until).
+		Text(".")
+
+	if data.user.SuspensionReason() != "" {
+		alert.Child(hb.Div().Text("Reason: " + data.user.SuspensionReason()))
+	}
+
+	if data.user.SuspendedBy() != "" {
+		alert.Child(hb.Div().Text("Suspended by: " + data.user.SuspendedBy()))
+	}
+
+	return alert
+}
+
 func (controller userUpdateController) page(data userUpdateControllerData) hb.TagInterface {
 	breadcrumbs := shared.Breadcrumbs(data.config, []shared.Breadcrumb{
 		{
@@ -93,10 +150,26 @@ func (controller userUpdateController) page(data userUpdateControllerData) hb.Ta
 		HTML("Back").
 		Href(shared.Url(data.config.Request, shared.PathUsers, nil))
 
+	// the download is a plain form submit, as htmx does not save files
+	formExport := hb.Form().
+		Class("d-inline").
+		Attr("method", http.MethodPost).
+		Attr("action", shared.Url(data.config.Request, shared.PathUserExport, map[string]string{
+			"user_id": data.userID,
+		})).
+		Child(shared.CsrfInput(data.config)).
+		Child(hb.Button().
+			Type("submit").
+			Class("btn btn-info ms-2 float-end").
+			Child(hb.I().Class("bi bi-download").Style("margin-top:-4px;margin-right:8px;font-size:16px;")).
+			HTML("Export Data").
+			Title("Download the personal data of the user"))
+
 	heading := hb.Heading1().
 		HTML("Edit User").
 		// Child(buttonSave).
-		Child(buttonCancel)
+		Child(buttonCancel).
+		Child(formExport)
 
 	card := hb.Div().
 		Class("card").
@@ -121,13 +194,624 @@ func (controller userUpdateController) page(data userUpdateControllerData) hb.Ta
 		Text(" ").
 		Text(data.userLastName)
 
-	return hb.Div().
+	container := hb.Div().
 		Class("container").
+		Attr("hx-headers", shared.CsrfHxHeaders(data.config)).
 		Child(breadcrumbs).
 		Child(hb.HR()).
 		Child(heading).
-		Child(subheading).
-		Child(card)
+		Child(subheading)
+
+	if data.user.IsLocked() {
+		container.Child(controller.lockedAlert(data))
+	}
+
+	if data.user.IsSuspended() {
+		container.Child(controller.suspendedAlert(data))
+	}
+
+	container.Child(controller.tabs(data))
+
+	if data.view == ViewHistory {
+		return container.Child(controller.historyCard(data))
+	}
+
+	if data.view == ViewWebhooks {
+		return container.Child(controller.webhooksCard(data))
+	}
+
+	return container.
+		Child(card).
+		Child(controller.securityCard(data)).
+		Child(controller.sessionsCard(data)).
+		Child(controller.apiKeysCard(data))
+}
+
+func (controller userUpdateController) tabs(data userUpdateControllerData) hb.TagInterface {
+	isDetails := data.view != ViewHistory && data.view != ViewWebhooks
+
+	linkDetails := hb.Hyperlink().
+		Class(lo.Ternary(isDetails, "nav-link active", "nav-link")).
+		HTML("Details").
+		Href(shared.Url(data.config.Request, shared.PathUserUpdate, map[string]string{
+			"user_id": data.userID,
+		}))
+
+	linkHistory := hb.Hyperlink().
+		Class(lo.Ternary(data.view == ViewHistory, "nav-link active", "nav-link")).
+		HTML("History").
+		Href(shared.Url(data.config.Request, shared.PathUserUpdate, map[string]string{
+			"user_id": data.userID,
+			"view":    ViewHistory,
+		}))
+
+	linkWebhooks := hb.Hyperlink().
+		Class(lo.Ternary(data.view == ViewWebhooks, "nav-link active", "nav-link")).
+		HTML("Webhooks").
+		Href(shared.Url(data.config.Request, shared.PathUserUpdate, map[string]string{
+			"user_id": data.userID,
+			"view":    ViewWebhooks,
+		}))
+
+	return hb.NewUL().
+		Class("nav nav-tabs mb-3").
+		Child(hb.LI().Class("nav-item").Child(linkDetails)).
+		Child(hb.LI().Class("nav-item").Child(linkHistory)).
+		Child(hb.LI().Class("nav-item").Child(linkWebhooks))
+}
+
+func (controller userUpdateController) historyCard(data userUpdateControllerData) hb.TagInterface {
+	return hb.Div().
+		Class("card").
+		Child(
+			hb.Div().
+				Class("card-header").
+				Child(hb.Heading4().
+					HTML("History").
+					Style("margin-bottom:0;display:inline-block;")),
+		).
+		Child(
+			hb.Div().
+				Class("card-body").
+				Child(controller.tableHistory(data)))
+}
+
+func (controller userUpdateController) tableHistory(data userUpdateControllerData) hb.TagInterface {
+	if len(data.audits) < 1 {
+		return hb.Div().
+			Class("text-muted").
+			Text("No changes recorded.")
+	}
+
+	return hb.Table().
+		Class("table table-striped table-hover table-bordered").
+		Children([]hb.TagInterface{
+			hb.Thead().Children([]hb.TagInterface{
+				hb.TR().Children([]hb.TagInterface{
+					hb.TH().
+						HTML("Date").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Action").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Actor").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Changes"),
+				}),
+			}),
+			hb.Tbody().Children(lo.Map(data.audits, func(audit userstore.AuditInterface, _ int) hb.TagInterface {
+				actor := lo.Ternary(audit.ActorID() != "", audit.ActorID(), "System")
+
+				diff := audit.DiffMap()
+				columns := lo.Keys(diff)
+				slices.Sort(columns)
+
+				changes := hb.Div().Style("font-size: 13px;")
+
+				for _, column := range columns {
+					changes.Child(hb.Div().
+						Child(hb.Span().Style("font-weight: bold;").Text(column)).
+						Text(": ").
+						Text(diff[column]["before"]).
+						Text(" → ").
+						Text(diff[column]["after"]))
+				}
+
+				return hb.TR().Children([]hb.TagInterface{
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;white-space: nowrap;").
+							Text(audit.CreatedAtCarbon().Format("d M Y H:i:s"))),
+					hb.TD().
+						Child(hb.Span().
+							Class("badge bg-secondary").
+							Text(audit.Action())),
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;white-space: nowrap;").
+							Text(actor)),
+					hb.TD().
+						Child(changes),
+				})
+			})),
+		})
+}
+
+func (controller userUpdateController) webhooksCard(data userUpdateControllerData) hb.TagInterface {
+	return hb.Div().
+		Class("card").
+		Child(
+			hb.Div().
+				Class("card-header").
+				Child(hb.Heading4().
+					HTML("Webhook Deliveries").
+					Style("margin-bottom:0;display:inline-block;")),
+		).
+		Child(
+			hb.Div().
+				Class("card-body").
+				Child(controller.tableWebhookDeliveries(data)))
+}
+
+func (controller userUpdateController) tableWebhookDeliveries(data userUpdateControllerData) hb.TagInterface {
+	if len(data.webhookDeliveries) < 1 {
+		return hb.Div().
+			Class("text-muted").
+			Text("No webhook deliveries.")
+	}
+
+	return hb.Table().
+		Class("table table-striped table-hover table-bordered").
+		Children([]hb.TagInterface{
+			hb.Thead().Children([]hb.TagInterface{
+				hb.TR().Children([]hb.TagInterface{
+					hb.TH().
+						HTML("Date").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Event").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Status").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Attempts").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Last Error"),
+					hb.TH().
+						HTML("Actions").
+						Style("width: 1px;"),
+				}),
+			}),
+			hb.Tbody().Children(lo.Map(data.webhookDeliveries, func(delivery userstore.WebhookDeliveryInterface, _ int) hb.TagInterface {
+				badgeClass := "badge bg-warning"
+
+				if delivery.IsDelivered() {
+					badgeClass = "badge bg-success"
+				}
+
+				if delivery.IsDead() {
+					badgeClass = "badge bg-danger"
+				}
+
+				buttonRedeliver := hb.Button().
+					Class("btn btn-sm btn-primary").
+					Child(hb.I().Class("bi bi-arrow-repeat")).
+					Title("Redeliver").
+					HxPost(shared.Url(data.config.Request, shared.PathUserWebhookRedeliver, map[string]string{
+						"user_id":     data.userID,
+						"delivery_id": delivery.ID(),
+					})).
+					HxTarget("body").
+					HxSwap("beforeend")
+
+				return hb.TR().Children([]hb.TagInterface{
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;white-space: nowrap;").
+							Text(delivery.CreatedAtCarbon().Format("d M Y H:i:s"))),
+					hb.TD().
+						Child(hb.Span().
+							Class("badge bg-secondary").
+							Text(delivery.Type())),
+					hb.TD().
+						Child(hb.Span().
+							Class(badgeClass).
+							Text(delivery.Status())),
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;").
+							Text(cast.ToString(delivery.Attempts()))),
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;").
+							Text(delivery.LastError())),
+					hb.TD().
+						HTMLIf(!delivery.IsPending(), buttonRedeliver.ToHTML()),
+				})
+			})),
+		})
+}
+
+func (controller userUpdateController) apiKeysCard(data userUpdateControllerData) hb.TagInterface {
+	formAPIKeyCreate := form.NewForm(form.FormOptions{
+		ID: "FormAPIKeyCreate",
+	})
+
+	formAPIKeyCreate.SetFields([]form.FieldInterface{
+		form.NewField(form.FieldOptions{
+			Label: "Name",
+			Name:  "api_key_name",
+			Type:  form.FORM_FIELD_TYPE_STRING,
+			Help:  `What the key is used for, i.e. "CI server".`,
+		}),
+		form.NewField(form.FieldOptions{
+			Label: "Scopes",
+			Name:  "api_key_scopes",
+			Type:  form.FORM_FIELD_TYPE_STRING,
+			Help:  `Comma separated, i.e. "orders:read,orders:write".`,
+		}),
+		form.NewField(form.FieldOptions{
+			Label: "Expires",
+			Name:  "api_key_expires_in_days",
+			Type:  form.FORM_FIELD_TYPE_SELECT,
+			Value: "90",
+			Options: []form.FieldOption{
+				{
+					Value: "In 30 days",
+					Key:   "30",
+				},
+				{
+					Value: "In 90 days",
+					Key:   "90",
+				},
+				{
+					Value: "In 1 year",
+					Key:   "365",
+				},
+				{
+					Value: "Never",
+					Key:   "0",
+				},
+			},
+		}),
+	})
+
+	buttonCreate := hb.Button().
+		Class("btn btn-success").
+		Child(hb.I().Class("bi bi-plus-circle me-2")).
+		HTML("Create API Key").
+		HxInclude("#FormAPIKeyCreate").
+		HxPost(shared.Url(data.config.Request, shared.PathUserAPIKeyCreate, map[string]string{
+			"user_id": data.userID,
+		})).
+		HxTarget("body").
+		HxSwap("beforeend")
+
+	return hb.Div().
+		Class("card mt-3").
+		Child(
+			hb.Div().
+				Class("card-header").
+				Child(hb.Heading4().
+					HTML("API Keys").
+					Style("margin-bottom:0;display:inline-block;")),
+		).
+		Child(
+			hb.Div().
+				Class("card-body").
+				Child(controller.tableAPIKeys(data)).
+				Child(hb.HR()).
+				Child(hb.Heading5().
+					HTML("New API Key")).
+				Child(formAPIKeyCreate.Build()).
+				Child(buttonCreate))
+}
+
+func (controller userUpdateController) tableAPIKeys(data userUpdateControllerData) hb.TagInterface {
+	if len(data.apiKeys) < 1 {
+		return hb.Div().
+			Class("text-muted").
+			Text("No API keys created.")
+	}
+
+	return hb.Table().
+		Class("table table-striped table-hover table-bordered").
+		Children([]hb.TagInterface{
+			hb.Thead().Children([]hb.TagInterface{
+				hb.TR().Children([]hb.TagInterface{
+					hb.TH().
+						HTML("Name"),
+					hb.TH().
+						HTML("Scopes"),
+					hb.TH().
+						HTML("Expires").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Last Used").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Actions").
+						Style("width: 1px;"),
+				}),
+			}),
+			hb.Tbody().Children(lo.Map(data.apiKeys, func(apiKey userstore.APIKeyInterface, _ int) hb.TagInterface {
+				expires := "Never"
+
+				if !strings.Contains(apiKey.ExpiresAt(), sb.MAX_DATETIME) {
+					expires = apiKey.ExpiresAtCarbon().Format("d M Y")
+				}
+
+				if apiKey.IsExpired() {
+					expires = "Expired"
+				}
+
+				lastUsed := "Never"
+
+				if !strings.Contains(apiKey.LastUsedAt(), sb.NULL_DATETIME) {
+					lastUsed = apiKey.LastUsedAtCarbon().Format("d M Y H:i")
+				}
+
+				buttonRevoke := hb.Button().
+					Class("btn btn-sm btn-danger").
+					Child(hb.I().Class("bi bi-trash")).
+					Title("Revoke").
+					HxPost(shared.Url(data.config.Request, shared.PathUserAPIKeyRevoke, map[string]string{
+						"user_id":    data.userID,
+						"api_key_id": apiKey.ID(),
+					})).
+					HxTarget("body").
+					HxSwap("beforeend")
+
+				return hb.TR().Children([]hb.TagInterface{
+					hb.TD().
+						Child(hb.Div().Text(apiKey.Name())).
+						Child(hb.Div().
+							Style("font-size: 11px;").
+							Text("Prefix: usk_").
+							Text(apiKey.KeyPrefix())),
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;").
+							Text(strings.Join(apiKey.Scopes(), ", "))),
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;white-space: nowrap;").
+							Text(expires)),
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;white-space: nowrap;").
+							Text(lastUsed)),
+					hb.TD().
+						Child(buttonRevoke),
+				})
+			})),
+		})
+}
+
+func (controller userUpdateController) securityCard(data userUpdateControllerData) hb.TagInterface {
+	mfaStatus := hb.Span().
+		Class("badge bg-secondary").
+		Text("Not configured")
+
+	if data.mfaEnabled {
+		mfaStatus = hb.Span().
+			Class("badge bg-success").
+			Text("Enabled")
+	}
+
+	recoveryCodesStatus := hb.Span().
+		Class("badge bg-secondary").
+		Text("Not configured")
+
+	if data.recoveryCodesRemaining > 0 {
+		recoveryCodesStatus = hb.Span().
+			Class("badge bg-success").
+			Text(cast.ToString(data.recoveryCodesRemaining) + " remaining")
+	}
+
+	return hb.Div().
+		Class("card mt-3").
+		Child(
+			hb.Div().
+				Class("card-header").
+				Child(hb.Heading4().
+					HTML("Security").
+					Style("margin-bottom:0;display:inline-block;")),
+		).
+		Child(
+			hb.Div().
+				Class("card-body").
+				Child(hb.Div().
+					Class("mb-2").
+					Text("Two-factor authentication (TOTP): ").
+					Child(mfaStatus)).
+				Child(hb.Div().
+					Class("mb-3").
+					Text("Recovery codes: ").
+					Child(recoveryCodesStatus)).
+				Child(hb.Heading5().
+					HTML("Passkeys")).
+				Child(controller.tablePasskeys(data)))
+}
+
+func (controller userUpdateController) tablePasskeys(data userUpdateControllerData) hb.TagInterface {
+	if len(data.passkeys) < 1 {
+		return hb.Div().
+			Class("text-muted").
+			Text("No passkeys registered.")
+	}
+
+	return hb.Table().
+		Class("table table-striped table-hover table-bordered").
+		Children([]hb.TagInterface{
+			hb.Thead().Children([]hb.TagInterface{
+				hb.TR().Children([]hb.TagInterface{
+					hb.TH().
+						HTML("Nickname"),
+					hb.TH().
+						HTML("Transports").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Last Used").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Created").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Actions").
+						Style("width: 1px;"),
+				}),
+			}),
+			hb.Tbody().Children(lo.Map(data.passkeys, func(passkey userstore.PasskeyInterface, _ int) hb.TagInterface {
+				nickname := lo.Ternary(passkey.Nickname() != "", passkey.Nickname(), "Unnamed passkey")
+
+				lastUsed := "Never"
+
+				if !strings.Contains(passkey.LastUsedAt(), sb.NULL_DATETIME) {
+					lastUsed = passkey.LastUsedAtCarbon().Format("d M Y H:i")
+				}
+
+				buttonRevoke := hb.Button().
+					Class("btn btn-sm btn-danger").
+					Child(hb.I().Class("bi bi-trash")).
+					Title("Revoke").
+					HxPost(shared.Url(data.config.Request, shared.PathUserPasskeyRevoke, map[string]string{
+						"user_id":    data.userID,
+						"passkey_id": passkey.ID(),
+					})).
+					HxTarget("body").
+					HxSwap("beforeend")
+
+				return hb.TR().Children([]hb.TagInterface{
+					hb.TD().
+						Child(hb.Div().Text(nickname)).
+						Child(hb.Div().
+							Style("font-size: 11px;").
+							Text("AAGUID: ").
+							Text(passkey.AAGUID())),
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;white-space: nowrap;").
+							Text(strings.Join(passkey.Transports(), ", "))),
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;white-space: nowrap;").
+							Text(lastUsed)),
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;white-space: nowrap;").
+							Text(passkey.CreatedAtCarbon().Format("d M Y"))),
+					hb.TD().
+						Child(buttonRevoke),
+				})
+			})),
+		})
+}
+
+func (controller userUpdateController) sessionsCard(data userUpdateControllerData) hb.TagInterface {
+	cardHeader := hb.Div().
+		Class("card-header").
+		Style(`display:flex;justify-content:space-between;align-items:center;`).
+		Child(hb.Heading4().
+			HTML("Active Sessions").
+			Style("margin-bottom:0;display:inline-block;"))
+
+	if len(data.sessions) > 0 {
+		cardHeader.Child(hb.Button().
+			Class("btn btn-sm btn-danger").
+			Child(hb.I().Class("bi bi-box-arrow-right me-2")).
+			HTML("End All Sessions").
+			HxPost(shared.Url(data.config.Request, shared.PathUserSessionRevokeAll, map[string]string{
+				"user_id": data.userID,
+			})).
+			HxTarget("body").
+			HxSwap("beforeend"))
+	}
+
+	return hb.Div().
+		Class("card mt-3").
+		Child(cardHeader).
+		Child(
+			hb.Div().
+				Class("card-body").
+				Child(controller.tableSessions(data)))
+}
+
+func (controller userUpdateController) tableSessions(data userUpdateControllerData) hb.TagInterface {
+	if len(data.sessions) < 1 {
+		return hb.Div().
+			Class("text-muted").
+			Text("No active sessions.")
+	}
+
+	return hb.Table().
+		Class("table table-striped table-hover table-bordered").
+		Children([]hb.TagInterface{
+			hb.Thead().Children([]hb.TagInterface{
+				hb.TR().Children([]hb.TagInterface{
+					hb.TH().
+						HTML("Device"),
+					hb.TH().
+						HTML("IP Address").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Last Seen").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Expires").
+						Style("width: 1px;"),
+					hb.TH().
+						HTML("Actions").
+						Style("width: 1px;"),
+				}),
+			}),
+			hb.Tbody().Children(lo.Map(data.sessions, func(session userstore.SessionInterface, _ int) hb.TagInterface {
+				device := lo.Ternary(session.Device() != "", session.Device(), "Unknown device")
+
+				expires := "Never"
+
+				if !strings.Contains(session.ExpiresAt(), sb.MAX_DATETIME) {
+					expires = session.ExpiresAtCarbon().Format("d M Y H:i")
+				}
+
+				buttonRevoke := hb.Button().
+					Class("btn btn-sm btn-danger").
+					Child(hb.I().Class("bi bi-x-circle")).
+					Title("End session").
+					HxPost(shared.Url(data.config.Request, shared.PathUserSessionRevoke, map[string]string{
+						"user_id":    data.userID,
+						"session_id": session.ID(),
+					})).
+					HxTarget("body").
+					HxSwap("beforeend")
+
+				return hb.TR().Children([]hb.TagInterface{
+					hb.TD().
+						Child(hb.Div().Text(device)).
+						Child(hb.Div().
+							Style("font-size: 11px;").
+							Text(session.UserAgent())),
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;white-space: nowrap;").
+							Text(session.IPAddress())),
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;white-space: nowrap;").
+							Text(session.LastSeenAtCarbon().Format("d M Y H:i"))),
+					hb.TD().
+						Child(hb.Div().
+							Style("font-size: 13px;white-space: nowrap;").
+							Text(expires)),
+					hb.TD().
+						Child(buttonRevoke),
+				})
+			})),
+		})
 }
 
 func (controller userUpdateController) form(data userUpdateControllerData) hb.TagInterface {
@@ -137,28 +821,12 @@ func (controller userUpdateController) form(data userUpdateControllerData) hb.Ta
 		Type:  form.FORM_FIELD_TYPE_SELECT,
 		Value: data.formStatus,
 		Help:  `The status of the user.`,
-		Options: []form.FieldOption{
+		Options: append([]form.FieldOption{
 			{
 				Value: "- not selected -",
 				Key:   "",
 			},
-			{
-				Value: "Active",
-				Key:   userstore.USER_STATUS_ACTIVE,
-			},
-			{
-				Value: "Unverified",
-				Key:   userstore.USER_STATUS_UNVERIFIED,
-			},
-			{
-				Value: "Inactive",
-				Key:   userstore.USER_STATUS_INACTIVE,
-			},
-			{
-				Value: "In Trash Bin",
-				Key:   userstore.USER_STATUS_DELETED,
-			},
-		},
+		}, statusOptions(data.config)...),
 	})
 
 	fieldFirstName := form.NewField(form.FieldOptions{
@@ -296,6 +964,37 @@ func (controller userUpdateController) saveUser(r *http.Request, data userUpdate
 		return data, ""
 	}
 
+	if data.formPhone != "" {
+		phone, err := userstore.PhoneNormalize(data.formPhone, data.user.Country())
+
+		if errors.Is(err, userstore.ErrPhoneRegionUnknown) {
+			data.formErrorMessage = "Phone must be in international format (i.e. +44...), as the user has no country"
+			return data, ""
+		}
+
+		if err != nil {
+			data.formErrorMessage = "Invalid phone number"
+			return data, ""
+		}
+
+		data.formPhone = phone
+	}
+
+	if data.formStatus != data.user.Status() {
+		err := data.config.Store.UserTransitionStatus(shared.AuditContext(data.config), data.user, data.formStatus, "Changed in the user manager")
+
+		if errors.Is(err, userstore.ErrStatusTransitionNotAllowed) || errors.Is(err, userstore.ErrStatusInvalid) {
+			data.formErrorMessage = "Status cannot be changed from " + data.user.Status() + " to " + data.formStatus
+			return data, ""
+		}
+
+		if err != nil {
+			data.config.Logger.Error("At userUpdateController > saveUser", "error", err.Error())
+			data.formErrorMessage = "System error. Saving user failed at status"
+			return data, ""
+		}
+	}
+
 	tokenizedColumns, regularColumns := controller.prepareColumnsForUpdate(data)
 
 	err := controller.saveTokenizedColumns(data, tokenizedColumns)
@@ -378,7 +1077,7 @@ func (controller userUpdateController) saveTokenizedColumns(data userUpdateContr
 		data.user.Set(columnName, createdToken)
 	}
 
-	err = data.config.Store.UserUpdate(context.Background(), data.user)
+	err = data.config.Store.UserUpdate(shared.AuditContext(data.config), data.user)
 
 	if err != nil {
 		data.config.Logger.Error("At userUpdateController > saveTokenizedColumns", "error", err.Error())
@@ -393,12 +1092,20 @@ func (controller userUpdateController) saveRegularColumns(data userUpdateControl
 		switch key {
 		case userstore.COLUMN_FIRST_NAME:
 			data.user.SetFirstName(value)
+		case userstore.COLUMN_MIDDLE_NAMES:
+			data.user.SetMiddleNames(value)
 		case userstore.COLUMN_LAST_NAME:
 			data.user.SetLastName(value)
 		case userstore.COLUMN_BUSINESS_NAME:
 			data.user.SetBusinessName(value)
 		case userstore.COLUMN_EMAIL:
 			data.user.SetEmail(value)
+		case userstore.COLUMN_PHONE:
+			if value == "" {
+				data.user.SetPhone("").SetPhoneVerifiedAt(sb.NULL_DATETIME)
+			} else if err := data.user.SetPhoneAndNormalize(value); err != nil {
+				return err
+			}
 		case userstore.COLUMN_STATUS:
 			data.user.SetStatus(value)
 		case userstore.COLUMN_MEMO:
@@ -408,7 +1115,7 @@ func (controller userUpdateController) saveRegularColumns(data userUpdateControl
 		}
 	}
 
-	err := data.config.Store.UserUpdate(context.Background(), data.user)
+	err := data.config.Store.UserUpdate(shared.AuditContext(data.config), data.user)
 
 	if err != nil {
 		data.config.Logger.Error("At userUpdateController > saveRegularColumns", "error", err.Error())
@@ -447,7 +1154,7 @@ func (controller userUpdateController) prepareColumnsForUpdate(data userUpdateCo
 
 func (controller userUpdateController) prepareDataAndValidate(config shared.Config) (data userUpdateControllerData, errorMessage string) {
 	data.config = config
-	data.action = utils.Req(config.Request, "action", "")
+	data.view = utils.Req(config.Request, "view", "")
 	data.userID = utils.Req(config.Request, "user_id", "")
 
 	if data.userID == "" {
@@ -467,6 +1174,64 @@ func (controller userUpdateController) prepareDataAndValidate(config shared.Conf
 
 	data.user = user
 
+	data.mfaEnabled, err = config.Store.UserMFAIsEnabled(context.Background(), data.userID)
+
+	if err != nil {
+		config.Logger.Error("At userUpdateController > prepareDataAndValidate", "error", err.Error())
+		return data, "MFA status failed to be read"
+	}
+
+	data.recoveryCodesRemaining, err = config.Store.UserRecoveryCodesRemaining(context.Background(), data.userID)
+
+	if err != nil {
+		config.Logger.Error("At userUpdateController > prepareDataAndValidate", "error", err.Error())
+		return data, "Recovery codes failed to be read"
+	}
+
+	data.apiKeys, err = config.Store.UserAPIKeyList(context.Background(), data.userID)
+
+	if err != nil {
+		config.Logger.Error("At userUpdateController > prepareDataAndValidate", "error", err.Error())
+		return data, "API keys failed to be read"
+	}
+
+	data.passkeys, err = config.Store.UserPasskeyList(context.Background(), data.userID)
+
+	if err != nil {
+		config.Logger.Error("At userUpdateController > prepareDataAndValidate", "error", err.Error())
+		return data, "Passkeys failed to be read"
+	}
+
+	if data.view == ViewHistory {
+		data.audits, err = config.Store.AuditList(context.Background(), userstore.NewAuditQuery().
+			SetEntity(userstore.AUDIT_ENTITY_USER).
+			SetEntityID(data.userID).
+			SetLimit(100))
+
+		if err != nil {
+			config.Logger.Error("At userUpdateController > prepareDataAndValidate", "error", err.Error())
+			return data, "History failed to be read"
+		}
+	}
+
+	if data.view == ViewWebhooks {
+		data.webhookDeliveries, err = config.Store.WebhookDeliveryList(context.Background(), userstore.NewWebhookDeliveryQuery().
+			SetUserID(data.userID).
+			SetLimit(100))
+
+		if err != nil {
+			config.Logger.Error("At userUpdateController > prepareDataAndValidate", "error", err.Error())
+			return data, "Webhook deliveries failed to be read"
+		}
+	}
+
+	data.sessions, err = config.Store.UserSessionList(context.Background(), data.userID)
+
+	if err != nil {
+		config.Logger.Error("At userUpdateController > prepareDataAndValidate", "error", err.Error())
+		return data, "Sessions failed to be read"
+	}
+
 	untokenized, err := userUntokenize(data.config, data.user)
 
 	if err != nil {
@@ -516,17 +1281,30 @@ func (controller userUpdateController) prepareDataAndValidate(config shared.Conf
 		return data, ""
 	}
 
+	if !shared.CsrfTokenValid(config) {
+		data.formErrorMessage = "Invalid request. Please reload the page and try again."
+		return data, ""
+	}
+
 	return controller.saveUser(config.Request, data)
 }
 
 type userUpdateControllerData struct {
 	config        shared.Config
-	action        string
+	view          string
 	userID        string
 	userFirstName string
 	userLastName  string
 	user          userstore.UserInterface
 
+	mfaEnabled             bool
+	recoveryCodesRemaining int64
+	passkeys               []userstore.PasskeyInterface
+	apiKeys                []userstore.APIKeyInterface
+	sessions               []userstore.SessionInterface
+	audits                 []userstore.AuditInterface
+	webhookDeliveries      []userstore.WebhookDeliveryInterface
+
 	formErrorMessage   string
 	formSuccessMessage string
 	formBusinessName   string
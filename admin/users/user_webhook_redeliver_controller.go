@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/gouniverse/hb"
+	"github.com/gouniverse/userstore"
+	"github.com/gouniverse/userstore/admin/shared"
+	"github.com/gouniverse/utils"
+)
+
+// == CONTROLLER ==============================================================
+
+// userWebhookRedeliverController queues a webhook delivery
+// of a user for redelivery
+type userWebhookRedeliverController struct{}
+
+var _ shared.PageInterface = (*userWebhookRedeliverController)(nil)
+
+// == CONSTRUCTOR =============================================================
+
+func NewUserWebhookRedeliverController() *userWebhookRedeliverController {
+	return &userWebhookRedeliverController{}
+}
+
+func (controller userWebhookRedeliverController) ToTag(config shared.Config) hb.TagInterface {
+	user, errorMessage := userActionPrepare(config)
+
+	if errorMessage != "" {
+		return userActionError(errorMessage)
+	}
+
+	deliveryID := utils.Req(config.Request, "delivery_id", "")
+
+	if deliveryID == "" {
+		return userActionError("Webhook delivery not found.")
+	}
+
+	deliveries, err := config.Store.WebhookDeliveryList(context.Background(), userstore.NewWebhookDeliveryQuery().
+		SetID(deliveryID).
+		SetUserID(user.ID()).
+		SetLimit(1))
+
+	if err != nil {
+		config.Logger.Error("At userWebhookRedeliverController > ToTag", "error", err.Error())
+		return userActionError("Webhook deliveries failed to be read.")
+	}
+
+	if len(deliveries) < 1 {
+		return userActionError("Webhook delivery not found.")
+	}
+
+	err = config.Store.WebhookRedeliver(shared.AuditContext(config), deliveryID)
+
+	if err != nil {
+		config.Logger.Error("At userWebhookRedeliverController > ToTag", "error", err.Error())
+		return userActionError("Redelivering webhook failed. Please contact an administrator.")
+	}
+
+	return userActionSuccess("Webhook queued for redelivery.")
+}
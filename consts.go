@@ -4,31 +4,115 @@ const ERROR_EMPTY_ARRAY = "array cannot be empty"
 const ERROR_EMPTY_STRING = "string cannot be empty"
 const ERROR_NEGATIVE_NUMBER = "number cannot be negative"
 
+const AUDIT_ACTION_CREATE = "create"
+const AUDIT_ACTION_DELETE = "delete"
+const AUDIT_ACTION_EMAIL_CHANGE = "email_change"
+const AUDIT_ACTION_IMPERSONATION_START = "impersonation_start"
+const AUDIT_ACTION_IMPERSONATION_STOP = "impersonation_stop"
+const AUDIT_ACTION_PASSWORD_RESET = "password_reset"
+const AUDIT_ACTION_PURGE = "purge"
+const AUDIT_ACTION_RESTORE = "restore"
+const AUDIT_ACTION_SOFT_DELETE = "soft_delete"
+const AUDIT_ACTION_UPDATE = "update"
+
+const AUDIT_ENTITY_USER = "user"
+
+const COLUMN_AAGUID = "aaguid"
+const COLUMN_ACTION = "action"
+const COLUMN_ACTOR_ID = "actor_id"
+const COLUMN_ATTEMPTS = "attempts"
 const COLUMN_BUSINESS_NAME = "business_name"
+const COLUMN_CODE_HASH = "code_hash"
 const COLUMN_CREATED_AT = "created_at"
 const COLUMN_COUNTRY = "country"
+const COLUMN_CREDENTIAL_ID = "credential_id"
+const COLUMN_DELIVERED_AT = "delivered_at"
+const COLUMN_DEVICE = "device"
+const COLUMN_DIFF = "diff"
 const COLUMN_EMAIL = "email"
+const COLUMN_ENABLED_AT = "enabled_at"
+const COLUMN_ENTITY = "entity"
+const COLUMN_ENTITY_ID = "entity_id"
+const COLUMN_EVENT_ID = "event_id"
+const COLUMN_EVENT_TYPES = "event_types"
+const COLUMN_EXPIRES_AT = "expires_at"
+const COLUMN_FAILED_LOGIN_COUNT = "failed_login_count"
+const COLUMN_FAILED_LOGIN_FIRST_AT = "failed_login_first_at"
+const COLUMN_FROM_STATUS = "from_status"
 const COLUMN_FIRST_NAME = "first_name"
 const COLUMN_HANDLE = "handle"
 const COLUMN_ID = "id"
+const COLUMN_IP_ADDRESS = "ip_address"
+const COLUMN_KEY_HASH = "key_hash"
+const COLUMN_KEY_PREFIX = "key_prefix"
+const COLUMN_LAST_ERROR = "last_error"
+const COLUMN_LAST_SEEN_AT = "last_seen_at"
+const COLUMN_LAST_USED_AT = "last_used_at"
+const COLUMN_LOCKED_UNTIL = "locked_until"
+const COLUMN_LOCKOUT_COUNT = "lockout_count"
 const COLUMN_MEMO = "memo"
+const COLUMN_METADATA = "metadata"
 const COLUMN_METAS = "metas"
 const COLUMN_MIDDLE_NAMES = "middle_names"
 const COLUMN_LAST_NAME = "last_name"
+const COLUMN_LAST_USED_STEP = "last_used_step"
 const COLUMN_NAME = "name"
+const COLUMN_NEXT_ATTEMPT_AT = "next_attempt_at"
+const COLUMN_NICKNAME = "nickname"
 const COLUMN_PASSWORD = "password"
+const COLUMN_PAYLOAD = "payload"
 const COLUMN_PHONE = "phone"
+const COLUMN_PHONE_VERIFIED_AT = "phone_verified_at"
 const COLUMN_PROFILE_IMAGE_URL = "profile_image_url"
+const COLUMN_PROVIDER = "provider"
+const COLUMN_PUBLIC_KEY = "public_key"
+const COLUMN_REASON = "reason"
+const COLUMN_REVISION = "revision"
+const COLUMN_SCOPES = "scopes"
+const COLUMN_SIGN_COUNT = "sign_count"
+const COLUMN_SNAPSHOT = "snapshot"
 const COLUMN_STATUS = "status"
 const COLUMN_ROLE = "role"
+const COLUMN_SECRET = "secret"
+const COLUMN_SUBJECT = "subject"
+const COLUMN_SUSPENDED_BY = "suspended_by"
+const COLUMN_SUSPENDED_UNTIL = "suspended_until"
+const COLUMN_SUSPENSION_REASON = "suspension_reason"
 const COLUMN_TIMEZONE = "timezone"
 const COLUMN_SOFT_DELETED_AT = "soft_deleted_at"
+const COLUMN_TO_STATUS = "to_status"
+const COLUMN_TOKEN_HASH = "token_hash"
+const COLUMN_TRANSPORTS = "transports"
+const COLUMN_TYPE = "type"
 const COLUMN_UPDATED_AT = "updated_at"
+const COLUMN_URL = "url"
+const COLUMN_USER_AGENT = "user_agent"
+const COLUMN_USER_ID = "user_id"
+const COLUMN_VERIFIED_AT = "verified_at"
+const COLUMN_WEBHOOK_ID = "webhook_id"
+
+const IDENTITY_EMAIL_MATCH_ALWAYS = "always"
+const IDENTITY_EMAIL_MATCH_NEVER = "never"
+const IDENTITY_EMAIL_MATCH_VERIFIED = "verified"
+
+const MFA_STATUS_ENABLED = "enabled"
+const MFA_STATUS_PENDING = "pending"
+
+const OUTBOX_EVENT_USER_CREATED = "user.created"
+const OUTBOX_EVENT_USER_DELETED = "user.deleted"
+const OUTBOX_EVENT_USER_PURGED = "user.purged"
+const OUTBOX_EVENT_USER_STATUS_CHANGED = "user.status_changed"
+const OUTBOX_EVENT_USER_UPDATED = "user.updated"
 
 const ROLE_STATUS_ACTIVE = "active"
 const ROLE_STATUS_INACTIVE = "inactive"
 const ROLE_STATUS_DELETED = "deleted"
 
+const TOKEN_TYPE_EMAIL_CHANGE = "email_change"
+const TOKEN_TYPE_EMAIL_VERIFICATION = "email_verification"
+const TOKEN_TYPE_PASSWORD_RESET = "password_reset"
+const TOKEN_TYPE_PHONE_VERIFICATION = "phone_verification"
+
 const USER_ROLE_SUPERUSER = "superuser"
 const USER_ROLE_ADMINISTRATOR = "administrator"
 const USER_ROLE_MANAGER = "manager"
@@ -38,3 +122,10 @@ const USER_STATUS_ACTIVE = "active"
 const USER_STATUS_INACTIVE = "inactive"
 const USER_STATUS_DELETED = "deleted"
 const USER_STATUS_UNVERIFIED = "unverified"
+
+const WEBHOOK_DELIVERY_STATUS_DEAD = "dead"
+const WEBHOOK_DELIVERY_STATUS_DELIVERED = "delivered"
+const WEBHOOK_DELIVERY_STATUS_PENDING = "pending"
+
+const WEBHOOK_STATUS_ACTIVE = "active"
+const WEBHOOK_STATUS_INACTIVE = "inactive"
@@ -0,0 +1,64 @@
+package userstore
+
+import (
+	"context"
+
+	"github.com/gouniverse/base/database"
+)
+
+type contextKey string
+
+const contextKeyActor contextKey = "userstore_actor"
+const contextKeyAuditMetadata contextKey = "userstore_audit_metadata"
+const contextKeyStatusReason contextKey = "userstore_status_reason"
+
+// WithActor returns a copy of the context carrying the ID of the actor
+// (i.e. the logged in user or an administrator) performing the changes,
+// which is recorded in the audit log
+func WithActor(ctx context.Context, actorID string) context.Context {
+	return withContextValue(ctx, contextKeyActor, actorID)
+}
+
+// ActorFromContext returns the ID of the actor set with WithActor,
+// or an empty string if not set
+func ActorFromContext(ctx context.Context) string {
+	actorID, _ := ctx.Value(contextKeyActor).(string)
+	return actorID
+}
+
+// WithAuditMetadata returns a copy of the context carrying the request
+// metadata (i.e. IP address, user agent), which is recorded in the audit log
+func WithAuditMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	return withContextValue(ctx, contextKeyAuditMetadata, metadata)
+}
+
+// AuditMetadataFromContext returns the metadata set with WithAuditMetadata,
+// or nil if not set
+func AuditMetadataFromContext(ctx context.Context) map[string]string {
+	metadata, _ := ctx.Value(contextKeyAuditMetadata).(map[string]string)
+	return metadata
+}
+
+// withStatusReason returns a copy of the context carrying the reason
+// of the status change, which is recorded in the status history
+func withStatusReason(ctx context.Context, reason string) context.Context {
+	return withContextValue(ctx, contextKeyStatusReason, reason)
+}
+
+// statusReasonFromContext returns the reason set with withStatusReason,
+// or an empty string if not set
+func statusReasonFromContext(ctx context.Context) string {
+	reason, _ := ctx.Value(contextKeyStatusReason).(string)
+	return reason
+}
+
+// withContextValue adds the value to the context, keeping the database
+// of a database.QueryableContext, so it can be used inside transactions
+func withContextValue(ctx context.Context, key contextKey, value any) context.Context {
+	if database.IsQueryableContext(ctx) {
+		queryableContext := ctx.(database.QueryableContext)
+		return database.Context(context.WithValue(queryableContext.Context, key, value), queryableContext.Queryable())
+	}
+
+	return context.WithValue(ctx, key, value)
+}
@@ -0,0 +1,80 @@
+package userstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// encryptString encrypts the value with AES-256-GCM, using a key
+// derived from the specified key. The nonce is prepended to the
+// ciphertext, and the result is base64 encoded.
+func encryptString(value string, key string) (string, error) {
+	if key == "" {
+		return "", errors.New("userstore: encryption key is empty")
+	}
+
+	gcm, err := encryptionCipher(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(value), nil)
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptString decrypts a value encrypted with encryptString
+func decryptString(encrypted string, key string) (string, error) {
+	if key == "" {
+		return "", errors.New("userstore: encryption key is empty")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+
+	if err != nil {
+		return "", errors.New("userstore: encrypted value is not valid base64")
+	}
+
+	gcm, err := encryptionCipher(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("userstore: encrypted value is too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	value, err := gcm.Open(nil, nonce, ciphertext, nil)
+
+	if err != nil {
+		return "", errors.New("userstore: decryption failed")
+	}
+
+	return string(value), nil
+}
+
+func encryptionCipher(key string) (cipher.AEAD, error) {
+	derivedKey := sha256.Sum256([]byte(key))
+
+	block, err := aes.NewCipher(derivedKey[:])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
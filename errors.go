@@ -0,0 +1,45 @@
+package userstore
+
+import "errors"
+
+var ErrUserNotFound = errors.New("userstore: user not found")
+
+var ErrAPIKeyInvalid = errors.New("userstore: api key is invalid, expired or revoked")
+
+var ErrEmailTaken = errors.New("userstore: email is already taken")
+var ErrEmailDuplicates = errors.New("userstore: emails shared by several users, which must be changed or soft deleted before the unique email index is created")
+
+var ErrIdentityAlreadyLinked = errors.New("userstore: identity is already linked")
+
+var ErrImpersonationNotAllowed = errors.New("userstore: impersonation is not allowed")
+
+var ErrMfaAlreadyEnabled = errors.New("userstore: mfa is already enabled")
+var ErrMfaCodeInvalid = errors.New("userstore: mfa code is invalid")
+var ErrMfaNotEnabled = errors.New("userstore: mfa is not enabled")
+var ErrMfaNotEnrolled = errors.New("userstore: mfa enrollment not found")
+
+var ErrPasskeyAlreadyRegistered = errors.New("userstore: passkey is already registered")
+var ErrPasskeyCloneDetected = errors.New("userstore: passkey sign count did not increase, the authenticator may be cloned")
+var ErrPasskeyNotFound = errors.New("userstore: passkey not found")
+
+var ErrPasswordEmpty = errors.New("userstore: password is empty")
+var ErrPasswordResetLimitReached = errors.New("userstore: too many outstanding password reset tokens")
+
+var ErrPhoneInvalid = errors.New("userstore: phone number is invalid")
+var ErrPhoneRegionUnknown = errors.New("userstore: phone number region is unknown")
+var ErrPhoneVerificationAttemptsExceeded = errors.New("userstore: too many phone verification attempts")
+
+var ErrRoleInvalid = errors.New("userstore: role is not valid")
+
+var ErrRevisionNotFound = errors.New("userstore: revision not found")
+
+var ErrSessionNotFound = errors.New("userstore: session not found, expired or revoked")
+
+var ErrStatusInvalid = errors.New("userstore: status is not valid")
+var ErrStatusTransitionNotAllowed = errors.New("userstore: status transition is not allowed")
+
+var ErrSuspensionUntilInvalid = errors.New("userstore: suspension end must be in the future")
+
+var ErrTokenInvalid = errors.New("userstore: token is invalid or expired")
+
+var ErrWebhookDeliveryNotFound = errors.New("userstore: webhook delivery not found")
@@ -0,0 +1,42 @@
+package userstore
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// emailNormalize returns the email in the form the emails are compared
+// in, trimmed and lowercased
+func emailNormalize(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// randomFromAlphabet returns a cryptographically secure random string
+// of the specified length, consisting of the characters in the alphabet
+func randomFromAlphabet(length int, alphabet string) (string, error) {
+	if length < 1 {
+		return "", errors.New("length must be greater than 0")
+	}
+
+	if alphabet == "" {
+		return "", errors.New("alphabet " + ERROR_EMPTY_STRING)
+	}
+
+	runes := []rune(alphabet)
+	max := big.NewInt(int64(len(runes)))
+	out := make([]rune, length)
+
+	for i := range out {
+		index, err := rand.Int(rand.Reader, max)
+
+		if err != nil {
+			return "", err
+		}
+
+		out[i] = runes[index.Int64()]
+	}
+
+	return string(out), nil
+}
@@ -3,6 +3,7 @@ package userstore
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/dromara/carbon/v2"
 )
@@ -12,6 +13,15 @@ type StoreInterface interface {
 	EnableDebug(debug bool)
 	DB() *sql.DB
 
+	Roles() []UserRole
+	Statuses() []UserStatus
+
+	AuditCount(ctx context.Context, query AuditQueryInterface) (int64, error)
+	AuditList(ctx context.Context, query AuditQueryInterface) ([]AuditInterface, error)
+
+	OutboxAck(ctx context.Context, ids []string) error
+	OutboxFetch(ctx context.Context, limit int) ([]OutboxEventInterface, error)
+
 	// RoleCreate(ctx context.Context, role RoleInterface) error
 	// RoleDelete(ctx context.Context, role RoleInterface) error
 	// RoleDeleteByID(ctx context.Context, id string) error
@@ -21,16 +31,402 @@ type StoreInterface interface {
 	// RoleSoftDeleteByID(ctx context.Context, id string) error
 	// RoleUpdate(ctx context.Context, role RoleInterface) error
 
+	UserAPIKeyCreate(ctx context.Context, apiKey APIKeyInterface) (string, error)
+	UserAPIKeyList(ctx context.Context, userID string) ([]APIKeyInterface, error)
+	UserAPIKeyRevoke(ctx context.Context, apiKeyID string) error
+	UserAPIKeyVerify(ctx context.Context, presented string) (UserInterface, APIKeyInterface, error)
+	UserAtRevision(ctx context.Context, userID string, revision int) (UserInterface, error)
+	UserCanLogin(user UserInterface) bool
 	UserCreate(ctx context.Context, user UserInterface) error
 	UserCount(ctx context.Context, options UserQueryInterface) (int64, error)
 	UserDelete(ctx context.Context, user UserInterface) error
 	UserDeleteByID(ctx context.Context, id string) error
+	UserEmailChangeConfirm(ctx context.Context, token string) (UserInterface, error)
+	UserEmailChangeRequest(ctx context.Context, userID string, newEmail string) (string, error)
+	UserEmailHistory(ctx context.Context, userID string) ([]string, error)
+	UserExportPersonalData(ctx context.Context, userID string) (*PersonalDataExport, error)
 	UserFindByEmail(ctx context.Context, email string) (UserInterface, error)
+	UserFindByEmailOrCreate(ctx context.Context, email string, createStatus string) (UserInterface, error)
 	UserFindByID(ctx context.Context, userID string) (UserInterface, error)
+	UserFindByIdentity(ctx context.Context, provider string, subject string) (UserInterface, error)
+	UserFindByIdentityOrCreate(ctx context.Context, options UserFindByIdentityOrCreateOptions) (UserInterface, error)
+	UserImpersonationStart(ctx context.Context, impersonatorID string, userID string) error
+	UserImpersonationStop(ctx context.Context, impersonatorID string, userID string) error
+	UserIdentityLink(ctx context.Context, identity IdentityInterface) error
+	UserIdentityList(ctx context.Context, userID string) ([]IdentityInterface, error)
+	UserIdentityUnlink(ctx context.Context, userID string, provider string, subject string) error
 	UserList(ctx context.Context, query UserQueryInterface) ([]UserInterface, error)
+	UserMFAConfirm(ctx context.Context, userID string, code string) error
+	UserMFADisable(ctx context.Context, userID string) error
+	UserMFAEnroll(ctx context.Context, userID string) (secret string, provisioningURI string, err error)
+	UserMFAIsEnabled(ctx context.Context, userID string) (bool, error)
+	UserMFAVerify(ctx context.Context, userID string, code string) (bool, error)
+	UserPasskeyFindByCredentialID(ctx context.Context, credentialID string) (PasskeyInterface, error)
+	UserPasskeyList(ctx context.Context, userID string) ([]PasskeyInterface, error)
+	UserPasskeyRegister(ctx context.Context, passkey PasskeyInterface) error
+	UserPasskeyRevoke(ctx context.Context, passkeyID string) error
+	UserPasskeyUpdateSignCount(ctx context.Context, credentialID string, signCount int64) error
+	UserPasswordReset(ctx context.Context, token string, newPassword string) (UserInterface, error)
+	UserPasswordResetTokenCreate(ctx context.Context, userID string, ttl time.Duration) (string, error)
+	UserPhoneVerificationStart(ctx context.Context, userID string) error
+	UserPhoneVerify(ctx context.Context, userID string, code string) (bool, error)
+	UserPurgeSoftDeleted(ctx context.Context, olderThan time.Duration, batchSize int) (PurgeReport, error)
+	UserPurgeSoftDeletedDryRun(ctx context.Context, olderThan time.Duration) (PurgeReport, error)
+	UserRecordLoginFailure(ctx context.Context, user UserInterface) error
+	UserRecordLoginSuccess(ctx context.Context, user UserInterface) error
+	UserRecoveryCodeConsume(ctx context.Context, userID string, code string) (bool, error)
+	UserRecoveryCodesGenerate(ctx context.Context, userID string, count int) ([]string, error)
+	UserRecoveryCodesRemaining(ctx context.Context, userID string) (int64, error)
+	UserReinstateExpiredSuspensions(ctx context.Context) (int, error)
+	UserRestore(ctx context.Context, user UserInterface) error
+	UserRestoreByID(ctx context.Context, id string) error
+	UserRestoreRevision(ctx context.Context, userID string, revision int) (UserInterface, error)
+	UserRevisions(ctx context.Context, userID string) ([]RevisionInterface, error)
+	UserSessionCreate(ctx context.Context, session SessionInterface) (string, error)
+	UserSessionFindByID(ctx context.Context, sessionID string) (SessionInterface, error)
+	UserSessionFindByToken(ctx context.Context, token string) (SessionInterface, error)
+	UserSessionList(ctx context.Context, userID string) ([]SessionInterface, error)
+	UserSessionRevoke(ctx context.Context, sessionID string) error
+	UserSessionRevokeAllExcept(ctx context.Context, userID string, exceptSessionID string) error
+	UserSessionTouch(ctx context.Context, sessionID string) error
 	UserSoftDelete(ctx context.Context, user UserInterface) error
 	UserSoftDeleteByID(ctx context.Context, id string) error
+	UserStatusHistory(ctx context.Context, userID string) ([]StatusHistoryInterface, error)
+	UserSuspend(ctx context.Context, user UserInterface, until string, reason string) error
+	UserSuspensionLift(ctx context.Context, user UserInterface) error
+	UserTransitionStatus(ctx context.Context, user UserInterface, newStatus string, reason string) error
+	UserUnlock(ctx context.Context, user UserInterface) error
 	UserUpdate(ctx context.Context, user UserInterface) error
+	UserVerificationTokenCreate(ctx context.Context, userID string, ttl time.Duration) (string, error)
+	UserVerifyEmail(ctx context.Context, token string) (UserInterface, error)
+
+	WebhookCreate(ctx context.Context, webhook WebhookInterface) (string, error)
+	WebhookDelete(ctx context.Context, webhookID string) error
+	WebhookDeliver(ctx context.Context, limit int) (int, error)
+	WebhookDeliveryList(ctx context.Context, query WebhookDeliveryQueryInterface) ([]WebhookDeliveryInterface, error)
+	WebhookList(ctx context.Context) ([]WebhookInterface, error)
+	WebhookRedeliver(ctx context.Context, deliveryID string) error
+}
+
+type APIKeyInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	HasScope(scope string) bool
+	IsExpired() bool
+	IsRevoked() bool
+
+	// setters and getters
+
+	CreatedAt() string
+	CreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) APIKeyInterface
+
+	ExpiresAt() string
+	ExpiresAtCarbon() *carbon.Carbon
+	SetExpiresAt(expiresAt string) APIKeyInterface
+
+	ID() string
+	SetID(id string) APIKeyInterface
+
+	KeyHash() string
+	SetKeyHash(keyHash string) APIKeyInterface
+
+	KeyPrefix() string
+	SetKeyPrefix(keyPrefix string) APIKeyInterface
+
+	LastUsedAt() string
+	LastUsedAtCarbon() *carbon.Carbon
+	SetLastUsedAt(lastUsedAt string) APIKeyInterface
+
+	Name() string
+	SetName(name string) APIKeyInterface
+
+	Scopes() []string
+	SetScopes(scopes []string) APIKeyInterface
+
+	SoftDeletedAt() string
+	SoftDeletedAtCarbon() *carbon.Carbon
+	SetSoftDeletedAt(softDeletedAt string) APIKeyInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) APIKeyInterface
+
+	UserID() string
+	SetUserID(userID string) APIKeyInterface
+}
+
+type AuditInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+
+	// methods
+
+	DiffMap() map[string]map[string]string
+	MetadataMap() map[string]string
+
+	// getters
+
+	Action() string
+	ActorID() string
+	CreatedAt() string
+	CreatedAtCarbon() *carbon.Carbon
+	Diff() string
+	Entity() string
+	EntityID() string
+	ID() string
+	Metadata() string
+}
+
+type MfaInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	IsEnabled() bool
+	IsPending() bool
+
+	// setters and getters
+
+	CreatedAt() string
+	CreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) MfaInterface
+
+	EnabledAt() string
+	EnabledAtCarbon() *carbon.Carbon
+	SetEnabledAt(enabledAt string) MfaInterface
+
+	ID() string
+	SetID(id string) MfaInterface
+
+	LastUsedStep() int64
+	SetLastUsedStep(lastUsedStep int64) MfaInterface
+
+	Secret() string
+	SetSecret(secret string) MfaInterface
+
+	Status() string
+	SetStatus(status string) MfaInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) MfaInterface
+
+	UserID() string
+	SetUserID(userID string) MfaInterface
+}
+
+type IdentityInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// setters and getters
+
+	CreatedAt() string
+	CreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) IdentityInterface
+
+	Email() string
+	SetEmail(email string) IdentityInterface
+
+	ID() string
+	SetID(id string) IdentityInterface
+
+	Provider() string
+	SetProvider(provider string) IdentityInterface
+
+	Subject() string
+	SetSubject(subject string) IdentityInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) IdentityInterface
+
+	UserID() string
+	SetUserID(userID string) IdentityInterface
+}
+
+type OutboxEventInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+
+	// methods
+
+	PayloadMap() map[string]any
+
+	// getters
+
+	CreatedAt() string
+	CreatedAtCarbon() *carbon.Carbon
+	ID() string
+	Payload() string
+	Type() string
+	UserID() string
+}
+
+type PasskeyInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	IsRevoked() bool
+
+	// setters and getters
+
+	AAGUID() string
+	SetAAGUID(aaguid string) PasskeyInterface
+
+	CreatedAt() string
+	CreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) PasskeyInterface
+
+	CredentialID() string
+	SetCredentialID(credentialID string) PasskeyInterface
+
+	ID() string
+	SetID(id string) PasskeyInterface
+
+	LastUsedAt() string
+	LastUsedAtCarbon() *carbon.Carbon
+	SetLastUsedAt(lastUsedAt string) PasskeyInterface
+
+	Nickname() string
+	SetNickname(nickname string) PasskeyInterface
+
+	PublicKey() string
+	SetPublicKey(publicKey string) PasskeyInterface
+
+	SignCount() int64
+	SetSignCount(signCount int64) PasskeyInterface
+
+	SoftDeletedAt() string
+	SoftDeletedAtCarbon() *carbon.Carbon
+	SetSoftDeletedAt(softDeletedAt string) PasskeyInterface
+
+	Transports() []string
+	SetTransports(transports []string) PasskeyInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) PasskeyInterface
+
+	UserID() string
+	SetUserID(userID string) PasskeyInterface
+}
+
+type RevisionInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+
+	// methods
+
+	SnapshotMap() map[string]string
+
+	// getters
+
+	CreatedAt() string
+	CreatedAtCarbon() *carbon.Carbon
+	ID() string
+	Revision() int
+	Snapshot() string
+	UserID() string
+}
+
+type SessionInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	IsActive() bool
+	IsExpired() bool
+	IsRevoked() bool
+
+	// setters and getters
+
+	CreatedAt() string
+	CreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) SessionInterface
+
+	Device() string
+	SetDevice(device string) SessionInterface
+
+	ExpiresAt() string
+	ExpiresAtCarbon() *carbon.Carbon
+	SetExpiresAt(expiresAt string) SessionInterface
+
+	ID() string
+	SetID(id string) SessionInterface
+
+	IPAddress() string
+	SetIPAddress(ipAddress string) SessionInterface
+
+	LastSeenAt() string
+	LastSeenAtCarbon() *carbon.Carbon
+	SetLastSeenAt(lastSeenAt string) SessionInterface
+
+	SoftDeletedAt() string
+	SoftDeletedAtCarbon() *carbon.Carbon
+	SetSoftDeletedAt(softDeletedAt string) SessionInterface
+
+	TokenHash() string
+	SetTokenHash(tokenHash string) SessionInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) SessionInterface
+
+	UserAgent() string
+	SetUserAgent(userAgent string) SessionInterface
+
+	UserID() string
+	SetUserID(userID string) SessionInterface
+}
+
+type StatusHistoryInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+
+	// getters
+
+	ActorID() string
+	CreatedAt() string
+	CreatedAtCarbon() *carbon.Carbon
+	FromStatus() string
+	ID() string
+	Reason() string
+	ToStatus() string
+	UserID() string
+}
+
+// SmsSenderInterface delivers text messages, it is implemented
+// by the application with the SMS provider of its choice
+type SmsSenderInterface interface {
+	Send(ctx context.Context, phone string, message string) error
 }
 
 type RoleInterface interface {
@@ -88,7 +484,9 @@ type UserInterface interface {
 
 	IsActive() bool
 	IsInactive() bool
+	IsLocked() bool
 	IsSoftDeleted() bool
+	IsSuspended() bool
 	IsUnverified() bool
 
 	IsAdministrator() bool
@@ -115,12 +513,26 @@ type UserInterface interface {
 	ID() string
 	SetID(id string) UserInterface
 
+	FailedLoginCount() int
+	SetFailedLoginCount(failedLoginCount int) UserInterface
+
+	FailedLoginFirstAt() string
+	FailedLoginFirstAtCarbon() *carbon.Carbon
+	SetFailedLoginFirstAt(failedLoginFirstAt string) UserInterface
+
 	FirstName() string
 	SetFirstName(firstName string) UserInterface
 
 	LastName() string
 	SetLastName(lastName string) UserInterface
 
+	LockedUntil() string
+	LockedUntilCarbon() *carbon.Carbon
+	SetLockedUntil(lockedUntil string) UserInterface
+
+	LockoutCount() int
+	SetLockoutCount(lockoutCount int) UserInterface
+
 	Memo() string
 	SetMemo(memo string) UserInterface
 
@@ -140,6 +552,11 @@ type UserInterface interface {
 
 	Phone() string
 	SetPhone(phone string) UserInterface
+	SetPhoneAndNormalize(phone string) error
+
+	PhoneVerifiedAt() string
+	PhoneVerifiedAtCarbon() *carbon.Carbon
+	SetPhoneVerifiedAt(phoneVerifiedAt string) UserInterface
 
 	ProfileImageUrl() string
 	SetProfileImageUrl(profileImageUrl string) UserInterface
@@ -151,6 +568,16 @@ type UserInterface interface {
 	SoftDeletedAtCarbon() *carbon.Carbon
 	SetSoftDeletedAt(deletedAt string) UserInterface
 
+	SuspendedBy() string
+	SetSuspendedBy(suspendedBy string) UserInterface
+
+	SuspendedUntil() string
+	SuspendedUntilCarbon() *carbon.Carbon
+	SetSuspendedUntil(suspendedUntil string) UserInterface
+
+	SuspensionReason() string
+	SetSuspensionReason(suspensionReason string) UserInterface
+
 	Timezone() string
 	SetTimezone(timezone string) UserInterface
 
@@ -160,4 +587,78 @@ type UserInterface interface {
 	UpdatedAt() string
 	UpdatedAtCarbon() *carbon.Carbon
 	SetUpdatedAt(updatedAt string) UserInterface
+
+	VerifiedAt() string
+	VerifiedAtCarbon() *carbon.Carbon
+	SetVerifiedAt(verifiedAt string) UserInterface
+}
+
+type WebhookInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	HasEventType(eventType string) bool
+	IsActive() bool
+
+	// setters and getters
+
+	CreatedAt() string
+	CreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) WebhookInterface
+
+	EventTypes() []string
+	SetEventTypes(eventTypes []string) WebhookInterface
+
+	ID() string
+	SetID(id string) WebhookInterface
+
+	Secret() string
+	SetSecret(secret string) WebhookInterface
+
+	Status() string
+	SetStatus(status string) WebhookInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) WebhookInterface
+
+	URL() string
+	SetURL(url string) WebhookInterface
+}
+
+type WebhookDeliveryInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+
+	// methods
+
+	IsDead() bool
+	IsDelivered() bool
+	IsPending() bool
+
+	// getters
+
+	Attempts() int
+	CreatedAt() string
+	CreatedAtCarbon() *carbon.Carbon
+	DeliveredAt() string
+	DeliveredAtCarbon() *carbon.Carbon
+	EventID() string
+	ID() string
+	LastError() string
+	NextAttemptAt() string
+	NextAttemptAtCarbon() *carbon.Carbon
+	Payload() string
+	Status() string
+	Type() string
+	UpdatedAt() string
+	UpdatedAtCarbon() *carbon.Carbon
+	UserID() string
+	WebhookID() string
 }
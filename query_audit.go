@@ -0,0 +1,262 @@
+package userstore
+
+import "errors"
+
+type AuditQueryInterface interface {
+	Validate() error
+
+	HasActorID() bool
+	ActorID() string
+	SetActorID(actorID string) AuditQueryInterface
+
+	HasAction() bool
+	Action() string
+	SetAction(action string) AuditQueryInterface
+
+	HasCreatedAtGte() bool
+	CreatedAtGte() string
+	SetCreatedAtGte(createdAtGte string) AuditQueryInterface
+
+	HasCreatedAtLte() bool
+	CreatedAtLte() string
+	SetCreatedAtLte(createdAtLte string) AuditQueryInterface
+
+	HasEntity() bool
+	Entity() string
+	SetEntity(entity string) AuditQueryInterface
+
+	HasEntityID() bool
+	EntityID() string
+	SetEntityID(entityID string) AuditQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) AuditQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) AuditQueryInterface
+
+	HasSortDirection() bool
+	SortDirection() string
+	SetSortDirection(sortDirection string) AuditQueryInterface
+
+	hasProperty(name string) bool
+}
+
+func NewAuditQuery() AuditQueryInterface {
+	return &auditQueryImplementation{
+		properties: map[string]any{},
+	}
+}
+
+type auditQueryImplementation struct {
+	properties map[string]any
+}
+
+func (c *auditQueryImplementation) Validate() error {
+	if c.HasActorID() && c.ActorID() == "" {
+		return errors.New("audit query. actor_id cannot be empty")
+	}
+
+	if c.HasAction() && c.Action() == "" {
+		return errors.New("audit query. action cannot be empty")
+	}
+
+	if c.HasCreatedAtGte() && c.CreatedAtGte() == "" {
+		return errors.New("audit query. created_at_gte cannot be empty")
+	}
+
+	if c.HasCreatedAtLte() && c.CreatedAtLte() == "" {
+		return errors.New("audit query. created_at_lte cannot be empty")
+	}
+
+	if c.HasEntity() && c.Entity() == "" {
+		return errors.New("audit query. entity cannot be empty")
+	}
+
+	if c.HasEntityID() && c.EntityID() == "" {
+		return errors.New("audit query. entity_id cannot be empty")
+	}
+
+	if c.HasSortDirection() && c.SortDirection() == "" {
+		return errors.New("audit query. sort_direction cannot be empty")
+	}
+
+	if c.HasLimit() && c.Limit() <= 0 {
+		return errors.New("audit query. limit must be greater than 0")
+	}
+
+	if c.HasOffset() && c.Offset() < 0 {
+		return errors.New("audit query. offset must be greater than or equal to 0")
+	}
+
+	return nil
+}
+
+func (c *auditQueryImplementation) HasActorID() bool {
+	return c.hasProperty("actor_id")
+}
+
+func (c *auditQueryImplementation) ActorID() string {
+	if !c.HasActorID() {
+		return ""
+	}
+
+	return c.properties["actor_id"].(string)
+}
+
+func (c *auditQueryImplementation) SetActorID(actorID string) AuditQueryInterface {
+	c.properties["actor_id"] = actorID
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasAction() bool {
+	return c.hasProperty("action")
+}
+
+func (c *auditQueryImplementation) Action() string {
+	if !c.HasAction() {
+		return ""
+	}
+
+	return c.properties["action"].(string)
+}
+
+func (c *auditQueryImplementation) SetAction(action string) AuditQueryInterface {
+	c.properties["action"] = action
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasCreatedAtGte() bool {
+	return c.hasProperty("created_at_gte")
+}
+
+func (c *auditQueryImplementation) CreatedAtGte() string {
+	if !c.HasCreatedAtGte() {
+		return ""
+	}
+
+	return c.properties["created_at_gte"].(string)
+}
+
+func (c *auditQueryImplementation) SetCreatedAtGte(createdAtGte string) AuditQueryInterface {
+	c.properties["created_at_gte"] = createdAtGte
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasCreatedAtLte() bool {
+	return c.hasProperty("created_at_lte")
+}
+
+func (c *auditQueryImplementation) CreatedAtLte() string {
+	if !c.HasCreatedAtLte() {
+		return ""
+	}
+
+	return c.properties["created_at_lte"].(string)
+}
+
+func (c *auditQueryImplementation) SetCreatedAtLte(createdAtLte string) AuditQueryInterface {
+	c.properties["created_at_lte"] = createdAtLte
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasEntity() bool {
+	return c.hasProperty("entity")
+}
+
+func (c *auditQueryImplementation) Entity() string {
+	if !c.HasEntity() {
+		return ""
+	}
+
+	return c.properties["entity"].(string)
+}
+
+func (c *auditQueryImplementation) SetEntity(entity string) AuditQueryInterface {
+	c.properties["entity"] = entity
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasEntityID() bool {
+	return c.hasProperty("entity_id")
+}
+
+func (c *auditQueryImplementation) EntityID() string {
+	if !c.HasEntityID() {
+		return ""
+	}
+
+	return c.properties["entity_id"].(string)
+}
+
+func (c *auditQueryImplementation) SetEntityID(entityID string) AuditQueryInterface {
+	c.properties["entity_id"] = entityID
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasLimit() bool {
+	return c.hasProperty("limit")
+}
+
+func (c *auditQueryImplementation) Limit() int {
+	if !c.HasLimit() {
+		return 0
+	}
+
+	return c.properties["limit"].(int)
+}
+
+func (c *auditQueryImplementation) SetLimit(limit int) AuditQueryInterface {
+	c.properties["limit"] = limit
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasOffset() bool {
+	return c.hasProperty("offset")
+}
+
+func (c *auditQueryImplementation) Offset() int {
+	if !c.HasOffset() {
+		return 0
+	}
+
+	return c.properties["offset"].(int)
+}
+
+func (c *auditQueryImplementation) SetOffset(offset int) AuditQueryInterface {
+	c.properties["offset"] = offset
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasSortDirection() bool {
+	return c.hasProperty("sort_direction")
+}
+
+func (c *auditQueryImplementation) SortDirection() string {
+	if !c.HasSortDirection() {
+		return ""
+	}
+
+	return c.properties["sort_direction"].(string)
+}
+
+func (c *auditQueryImplementation) SetSortDirection(sortDirection string) AuditQueryInterface {
+	c.properties["sort_direction"] = sortDirection
+
+	return c
+}
+
+func (c *auditQueryImplementation) hasProperty(name string) bool {
+	_, ok := c.properties[name]
+	return ok
+}
@@ -44,6 +44,10 @@ type UserQueryInterface interface {
 	Offset() int
 	SetOffset(offset int) UserQueryInterface
 
+	HasOnlySoftDeleted() bool
+	OnlySoftDeleted() bool
+	SetOnlySoftDeleted(onlySoftDeleted bool) UserQueryInterface
+
 	HasOrderBy() bool
 	OrderBy() string
 	SetOrderBy(orderBy string) UserQueryInterface
@@ -56,6 +60,14 @@ type UserQueryInterface interface {
 	SoftDeletedIncluded() bool
 	SetSoftDeletedIncluded(softDeletedIncluded bool) UserQueryInterface
 
+	HasSoftDeletedAtGte() bool
+	SoftDeletedAtGte() string
+	SetSoftDeletedAtGte(softDeletedAtGte string) UserQueryInterface
+
+	HasSoftDeletedAtLte() bool
+	SoftDeletedAtLte() string
+	SetSoftDeletedAtLte(softDeletedAtLte string) UserQueryInterface
+
 	HasStatus() bool
 	Status() string
 	SetStatus(status string) UserQueryInterface
@@ -102,6 +114,14 @@ func (c *userQueryImplementation) Validate() error {
 		return errors.New("user query. meta_like cannot be empty")
 	}
 
+	if c.HasSoftDeletedAtGte() && c.SoftDeletedAtGte() == "" {
+		return errors.New("user query. soft_deleted_at_gte cannot be empty")
+	}
+
+	if c.HasSoftDeletedAtLte() && c.SoftDeletedAtLte() == "" {
+		return errors.New("user query. soft_deleted_at_lte cannot be empty")
+	}
+
 	if c.HasStatus() && c.Status() == "" {
 		return errors.New("user query. status cannot be empty")
 	}
@@ -309,6 +329,26 @@ func (c *userQueryImplementation) SetOffset(offset int) UserQueryInterface {
 	return c
 }
 
+func (c *userQueryImplementation) HasOnlySoftDeleted() bool {
+	return c.hasProperty("only_soft_deleted")
+}
+
+// OnlySoftDeleted returns whether only the soft deleted users are
+// requested. It takes precedence over SoftDeletedIncluded.
+func (c *userQueryImplementation) OnlySoftDeleted() bool {
+	if !c.HasOnlySoftDeleted() {
+		return false
+	}
+
+	return c.properties["only_soft_deleted"].(bool)
+}
+
+func (c *userQueryImplementation) SetOnlySoftDeleted(onlySoftDeleted bool) UserQueryInterface {
+	c.properties["only_soft_deleted"] = onlySoftDeleted
+
+	return c
+}
+
 func (c *userQueryImplementation) HasOrderBy() bool {
 	return c.hasProperty("order_by")
 }
@@ -363,6 +403,42 @@ func (c *userQueryImplementation) SetSoftDeletedIncluded(softDeletedIncluded boo
 	return c
 }
 
+func (c *userQueryImplementation) HasSoftDeletedAtGte() bool {
+	return c.hasProperty("soft_deleted_at_gte")
+}
+
+func (c *userQueryImplementation) SoftDeletedAtGte() string {
+	if !c.HasSoftDeletedAtGte() {
+		return ""
+	}
+
+	return c.properties["soft_deleted_at_gte"].(string)
+}
+
+func (c *userQueryImplementation) SetSoftDeletedAtGte(softDeletedAtGte string) UserQueryInterface {
+	c.properties["soft_deleted_at_gte"] = softDeletedAtGte
+
+	return c
+}
+
+func (c *userQueryImplementation) HasSoftDeletedAtLte() bool {
+	return c.hasProperty("soft_deleted_at_lte")
+}
+
+func (c *userQueryImplementation) SoftDeletedAtLte() string {
+	if !c.HasSoftDeletedAtLte() {
+		return ""
+	}
+
+	return c.properties["soft_deleted_at_lte"].(string)
+}
+
+func (c *userQueryImplementation) SetSoftDeletedAtLte(softDeletedAtLte string) UserQueryInterface {
+	c.properties["soft_deleted_at_lte"] = softDeletedAtLte
+
+	return c
+}
+
 func (c *userQueryImplementation) HasStatus() bool {
 	return c.hasProperty("status")
 }
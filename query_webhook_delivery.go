@@ -0,0 +1,184 @@
+package userstore
+
+import "errors"
+
+type WebhookDeliveryQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) WebhookDeliveryQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) WebhookDeliveryQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) WebhookDeliveryQueryInterface
+
+	HasStatus() bool
+	Status() string
+	SetStatus(status string) WebhookDeliveryQueryInterface
+
+	HasUserID() bool
+	UserID() string
+	SetUserID(userID string) WebhookDeliveryQueryInterface
+
+	HasWebhookID() bool
+	WebhookID() string
+	SetWebhookID(webhookID string) WebhookDeliveryQueryInterface
+
+	hasProperty(name string) bool
+}
+
+func NewWebhookDeliveryQuery() WebhookDeliveryQueryInterface {
+	return &webhookDeliveryQueryImplementation{
+		properties: map[string]any{},
+	}
+}
+
+type webhookDeliveryQueryImplementation struct {
+	properties map[string]any
+}
+
+func (c *webhookDeliveryQueryImplementation) Validate() error {
+	if c.HasID() && c.ID() == "" {
+		return errors.New("webhook delivery query. id cannot be empty")
+	}
+
+	if c.HasStatus() && c.Status() == "" {
+		return errors.New("webhook delivery query. status cannot be empty")
+	}
+
+	if c.HasUserID() && c.UserID() == "" {
+		return errors.New("webhook delivery query. user_id cannot be empty")
+	}
+
+	if c.HasWebhookID() && c.WebhookID() == "" {
+		return errors.New("webhook delivery query. webhook_id cannot be empty")
+	}
+
+	if c.HasLimit() && c.Limit() <= 0 {
+		return errors.New("webhook delivery query. limit must be greater than 0")
+	}
+
+	if c.HasOffset() && c.Offset() < 0 {
+		return errors.New("webhook delivery query. offset must be greater than or equal to 0")
+	}
+
+	return nil
+}
+
+func (c *webhookDeliveryQueryImplementation) HasID() bool {
+	return c.hasProperty("id")
+}
+
+func (c *webhookDeliveryQueryImplementation) ID() string {
+	if !c.HasID() {
+		return ""
+	}
+
+	return c.properties["id"].(string)
+}
+
+func (c *webhookDeliveryQueryImplementation) SetID(id string) WebhookDeliveryQueryInterface {
+	c.properties["id"] = id
+
+	return c
+}
+
+func (c *webhookDeliveryQueryImplementation) HasLimit() bool {
+	return c.hasProperty("limit")
+}
+
+func (c *webhookDeliveryQueryImplementation) Limit() int {
+	if !c.HasLimit() {
+		return 0
+	}
+
+	return c.properties["limit"].(int)
+}
+
+func (c *webhookDeliveryQueryImplementation) SetLimit(limit int) WebhookDeliveryQueryInterface {
+	c.properties["limit"] = limit
+
+	return c
+}
+
+func (c *webhookDeliveryQueryImplementation) HasOffset() bool {
+	return c.hasProperty("offset")
+}
+
+func (c *webhookDeliveryQueryImplementation) Offset() int {
+	if !c.HasOffset() {
+		return 0
+	}
+
+	return c.properties["offset"].(int)
+}
+
+func (c *webhookDeliveryQueryImplementation) SetOffset(offset int) WebhookDeliveryQueryInterface {
+	c.properties["offset"] = offset
+
+	return c
+}
+
+func (c *webhookDeliveryQueryImplementation) HasStatus() bool {
+	return c.hasProperty("status")
+}
+
+func (c *webhookDeliveryQueryImplementation) Status() string {
+	if !c.HasStatus() {
+		return ""
+	}
+
+	return c.properties["status"].(string)
+}
+
+func (c *webhookDeliveryQueryImplementation) SetStatus(status string) WebhookDeliveryQueryInterface {
+	c.properties["status"] = status
+
+	return c
+}
+
+func (c *webhookDeliveryQueryImplementation) HasUserID() bool {
+	return c.hasProperty("user_id")
+}
+
+func (c *webhookDeliveryQueryImplementation) UserID() string {
+	if !c.HasUserID() {
+		return ""
+	}
+
+	return c.properties["user_id"].(string)
+}
+
+func (c *webhookDeliveryQueryImplementation) SetUserID(userID string) WebhookDeliveryQueryInterface {
+	c.properties["user_id"] = userID
+
+	return c
+}
+
+func (c *webhookDeliveryQueryImplementation) HasWebhookID() bool {
+	return c.hasProperty("webhook_id")
+}
+
+func (c *webhookDeliveryQueryImplementation) WebhookID() string {
+	if !c.HasWebhookID() {
+		return ""
+	}
+
+	return c.properties["webhook_id"].(string)
+}
+
+func (c *webhookDeliveryQueryImplementation) SetWebhookID(webhookID string) WebhookDeliveryQueryInterface {
+	c.properties["webhook_id"] = webhookID
+
+	return c
+}
+
+func (c *webhookDeliveryQueryImplementation) hasProperty(name string) bool {
+	_, ok := c.properties[name]
+	return ok
+}
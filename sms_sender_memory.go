@@ -0,0 +1,38 @@
+package userstore
+
+import (
+	"context"
+	"sync"
+)
+
+// SmsSenderMemory is an SmsSenderInterface which keeps the messages
+// in memory instead of sending them, for use in tests and development
+type SmsSenderMemory struct {
+	mu       sync.Mutex
+	messages map[string][]string
+}
+
+var _ SmsSenderInterface = (*SmsSenderMemory)(nil)
+
+func NewSmsSenderMemory() *SmsSenderMemory {
+	return &SmsSenderMemory{
+		messages: map[string][]string{},
+	}
+}
+
+func (s *SmsSenderMemory) Send(_ context.Context, phone string, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages[phone] = append(s.messages[phone], message)
+
+	return nil
+}
+
+// Messages returns the messages sent to the phone number, oldest first
+func (s *SmsSenderMemory) Messages(phone string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.messages[phone]...)
+}
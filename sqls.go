@@ -1,7 +1,10 @@
 package userstore
 
 import (
+	"strings"
+
 	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
 )
 
 // sqlRoleTableCreate returns a SQL string for creating the role table
@@ -114,6 +117,43 @@ func (st *store) sqlUserTableCreate() string {
 			Type:   sb.COLUMN_TYPE_STRING,
 			Length: 255,
 		}).
+		Column(sb.Column{
+			Name: COLUMN_FAILED_LOGIN_COUNT,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_FAILED_LOGIN_FIRST_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_LOCKOUT_COUNT,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_LOCKED_UNTIL,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_SUSPENDED_UNTIL,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_SUSPENDED_BY,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_SUSPENSION_REASON,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_VERIFIED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_PHONE_VERIFIED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
 		Column(sb.Column{
 			Name: COLUMN_METAS,
 			Type: sb.COLUMN_TYPE_TEXT,
@@ -138,3 +178,795 @@ func (st *store) sqlUserTableCreate() string {
 
 	return sql
 }
+
+// sqlMfaTableCreate returns a SQL string for creating the MFA table
+func (st *store) sqlMfaTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.mfaTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+			Unique: true,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_STATUS,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_SECRET,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_LAST_USED_STEP,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_ENABLED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_UPDATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlRecoveryCodeTableCreate returns a SQL string for creating the recovery code table
+func (st *store) sqlRecoveryCodeTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.recoveryCodeTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CODE_HASH,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 64,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlPasskeyTableCreate returns a SQL string for creating the passkey table
+func (st *store) sqlPasskeyTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.passkeyTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CREDENTIAL_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 255,
+			Unique: true,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_PUBLIC_KEY,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_SIGN_COUNT,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_AAGUID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_TRANSPORTS,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 255,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_NICKNAME,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 255,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_LAST_USED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_UPDATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_SOFT_DELETED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlStatusHistoryTableCreate returns a SQL string for creating the status history table
+func (st *store) sqlStatusHistoryTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.statusHistoryTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_FROM_STATUS,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_TO_STATUS,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_REASON,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ACTOR_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlTokenTableCreate returns a SQL string for creating the token table,
+// which holds the one-time tokens of all the flows (verification, reset, etc)
+func (st *store) sqlTokenTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.tokenTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_TOKEN_HASH,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 64,
+			Unique: true,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_PAYLOAD,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_ATTEMPTS,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_EXPIRES_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlAuditTableCreate returns a SQL string for creating the audit table
+func (st *store) sqlAuditTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.auditTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ACTOR_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ACTION,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENTITY,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENTITY_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_DIFF,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_METADATA,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlEmailHistoryTableCreate returns a SQL string for creating the email history table
+func (st *store) sqlEmailHistoryTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.emailHistoryTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_EMAIL,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 255,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlAPIKeyTableCreate returns a SQL string for creating the API key table
+func (st *store) sqlAPIKeyTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.apiKeyTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_NAME,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 100,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_KEY_PREFIX,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+			Unique: true,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_KEY_HASH,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 64,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_SCOPES,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_EXPIRES_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_LAST_USED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_UPDATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_SOFT_DELETED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlIdentityTableCreate returns a SQL string for creating the identity table
+func (st *store) sqlIdentityTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.identityTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_PROVIDER,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_SUBJECT,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 191,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_EMAIL,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 255,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_UPDATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlIdentityIndexCreate returns a SQL string for creating the unique
+// index on the provider and the subject of the identity table
+func (st *store) sqlIdentityIndexCreate() string {
+	return st.sqlUniqueIndexCreate(st.identityTableName, st.identityTableName+"_provider_subject_unique", []string{COLUMN_PROVIDER, COLUMN_SUBJECT}, "")
+}
+
+// sqlOutboxTableCreate returns a SQL string for creating the outbox table
+func (st *store) sqlOutboxTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.outboxTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 50,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_PAYLOAD,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlRevisionTableCreate returns a SQL string for creating the revision table
+func (st *store) sqlRevisionTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.revisionTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_REVISION,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_SNAPSHOT,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlRevisionIndexCreate returns a SQL string for creating the unique
+// index on the user ID and the revision number of the revision table
+func (st *store) sqlRevisionIndexCreate() string {
+	return st.sqlUniqueIndexCreate(st.revisionTableName, st.revisionTableName+"_user_id_revision_unique", []string{COLUMN_USER_ID, COLUMN_REVISION}, "")
+}
+
+// sqlSessionTableCreate returns a SQL string for creating the session table
+func (st *store) sqlSessionTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.sessionTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_TOKEN_HASH,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 64,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_IP_ADDRESS,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 45,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_AGENT,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 512,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_DEVICE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 100,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_LAST_SEEN_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_EXPIRES_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_UPDATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_SOFT_DELETED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlSessionTokenIndexCreate returns a SQL string for creating the unique
+// index on the token hash of the session table, the sessions are found by it
+func (st *store) sqlSessionTokenIndexCreate() string {
+	return st.sqlUniqueIndexCreate(st.sessionTableName, st.sessionTableName+"_token_hash_unique", []string{COLUMN_TOKEN_HASH}, "")
+}
+
+// sqlUserEmailIndexCreate returns a SQL string for creating the unique
+// index on the normalized (trimmed and lowercased) email of the user table.
+// Only the users, which are not soft deleted and have an email, are included.
+//
+// MySQL does not support partial indexes, a functional index (MySQL 8.0.13+)
+// is used instead, the NULLs it produces for the excluded users do not collide
+func (st *store) sqlUserEmailIndexCreate() string {
+	indexName := st.userTableName + "_email_unique"
+
+	if st.dbDriverName == sb.DIALECT_MYSQL {
+		return "CREATE UNIQUE INDEX `" + indexName + "` ON `" + st.userTableName + "` " +
+			"((CASE WHEN `" + COLUMN_EMAIL + "` <> '' AND `" + COLUMN_SOFT_DELETED_AT + "` = '" + sb.MAX_DATETIME + "' THEN LOWER(TRIM(`" + COLUMN_EMAIL + "`)) END));"
+	}
+
+	return `CREATE UNIQUE INDEX IF NOT EXISTS "` + indexName + `" ON "` + st.userTableName + `" (LOWER(TRIM("` + COLUMN_EMAIL + `")))` +
+		` WHERE "` + COLUMN_EMAIL + `" <> '' AND "` + COLUMN_SOFT_DELETED_AT + `" = '` + sb.MAX_DATETIME + `';`
+}
+
+// sqlUserColumnsLockout returns the columns of the failed login lockout,
+// added to the user table after its first release
+func (st *store) sqlUserColumnsLockout() []sb.Column {
+	return []sb.Column{
+		{
+			Name:     COLUMN_FAILED_LOGIN_COUNT,
+			Type:     sb.COLUMN_TYPE_INTEGER,
+			Nullable: true,
+			Default:  "0",
+		},
+		{
+			Name:     COLUMN_FAILED_LOGIN_FIRST_AT,
+			Type:     sb.COLUMN_TYPE_DATETIME,
+			Nullable: true,
+			Default:  sb.NULL_DATETIME,
+		},
+		{
+			Name:     COLUMN_LOCKOUT_COUNT,
+			Type:     sb.COLUMN_TYPE_INTEGER,
+			Nullable: true,
+			Default:  "0",
+		},
+		{
+			Name:     COLUMN_LOCKED_UNTIL,
+			Type:     sb.COLUMN_TYPE_DATETIME,
+			Nullable: true,
+			Default:  sb.NULL_DATETIME,
+		},
+	}
+}
+
+// sqlUserColumnsVerification returns the column of the email verification,
+// added to the user table after its first release
+func (st *store) sqlUserColumnsVerification() []sb.Column {
+	return []sb.Column{
+		{
+			Name:     COLUMN_VERIFIED_AT,
+			Type:     sb.COLUMN_TYPE_DATETIME,
+			Nullable: true,
+			Default:  sb.NULL_DATETIME,
+		},
+	}
+}
+
+// sqlUserColumnsPhoneVerification returns the column of the phone
+// verification, added to the user table after its first release
+func (st *store) sqlUserColumnsPhoneVerification() []sb.Column {
+	return []sb.Column{
+		{
+			Name:     COLUMN_PHONE_VERIFIED_AT,
+			Type:     sb.COLUMN_TYPE_DATETIME,
+			Nullable: true,
+			Default:  sb.NULL_DATETIME,
+		},
+	}
+}
+
+// sqlUserColumnsSuspension returns the columns of the suspension,
+// added to the user table after its first release
+func (st *store) sqlUserColumnsSuspension() []sb.Column {
+	return []sb.Column{
+		{
+			Name:     COLUMN_SUSPENDED_UNTIL,
+			Type:     sb.COLUMN_TYPE_DATETIME,
+			Nullable: true,
+			Default:  sb.NULL_DATETIME,
+		},
+		{
+			Name:     COLUMN_SUSPENDED_BY,
+			Type:     sb.COLUMN_TYPE_STRING,
+			Length:   40,
+			Nullable: true,
+			Default:  "",
+		},
+		{
+			Name:     COLUMN_SUSPENSION_REASON,
+			Type:     sb.COLUMN_TYPE_TEXT,
+			Nullable: true,
+			Default:  "",
+		},
+	}
+}
+
+// sqlUniqueIndexCreate returns a SQL string for creating a unique index,
+// optionally a partial one, if the where condition is not empty (not for MySQL).
+// MySQL does not support "IF NOT EXISTS" for indexes, the error for an
+// existing index is ignored by AutoMigrate instead.
+func (st *store) sqlUniqueIndexCreate(tableName string, indexName string, columnNames []string, where string) string {
+	quote := func(name string) string {
+		if st.dbDriverName == sb.DIALECT_MYSQL {
+			return "`" + name + "`"
+		}
+
+		return `"` + name + `"`
+	}
+
+	columns := lo.Map(columnNames, func(columnName string, _ int) string {
+		return quote(columnName)
+	})
+
+	ifNotExists := lo.Ternary(st.dbDriverName == sb.DIALECT_MYSQL, "", "IF NOT EXISTS ")
+
+	sqlStr := "CREATE UNIQUE INDEX " + ifNotExists + quote(indexName) + " ON " + quote(tableName) + " (" + strings.Join(columns, ",") + ")"
+
+	if where != "" {
+		sqlStr += " WHERE " + where
+	}
+
+	return sqlStr + ";"
+}
+
+// sqlWebhookTableCreate returns a SQL string for creating the webhook table
+func (st *store) sqlWebhookTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.webhookTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_URL,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 510,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_EVENT_TYPES,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_SECRET,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 255,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_STATUS,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_UPDATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlWebhookDeliveryTableCreate returns a SQL string for creating the webhook delivery table
+func (st *store) sqlWebhookDeliveryTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.webhookDeliveryTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_WEBHOOK_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_EVENT_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 50,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_USER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_PAYLOAD,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_STATUS,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_ATTEMPTS,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_NEXT_ATTEMPT_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_LAST_ERROR,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_DELIVERED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_CREATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_UPDATED_AT,
+			Type: sb.COLUMN_TYPE_DATETIME,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
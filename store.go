@@ -4,19 +4,71 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
 
+	"github.com/doug-martin/goqu/v9"
 	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
 )
 
 // == TYPE ====================================================================
 
 type store struct {
 	// roleTableName      string
-	userTableName      string
-	db                 *sql.DB
-	dbDriverName       string
-	automigrateEnabled bool
-	debugEnabled       bool
+	userTableName            string
+	apiKeyTableName          string
+	auditTableName           string
+	emailHistoryTableName    string
+	identityTableName        string
+	mfaTableName             string
+	outboxTableName          string
+	passkeyTableName         string
+	recoveryCodeTableName    string
+	revisionTableName        string
+	sessionTableName         string
+	statusHistoryTableName   string
+	tokenTableName           string
+	webhookDeliveryTableName string
+	webhookTableName         string
+	db                       *sql.DB
+	dbDriverName             string
+	automigrateEnabled       bool
+	debugEnabled             bool
+	encryptionKey            string
+	lockoutPolicy            LockoutPolicy
+	mfaIssuer                string
+	mfaDriftSteps            int
+
+	emailChangeTokenTTL time.Duration
+	identityEmailMatch  string
+
+	phoneVerificationCodeTTL time.Duration
+	smsSender                SmsSenderInterface
+
+	passwordResetMaxOutstanding int
+	revisionRetention           int
+	sessionTTL                  time.Duration
+	statusTransitions           StatusTransitions
+	statuses                    []UserStatus
+	roles                       []UserRole
+	passwordValidator           func(password string) error
+	consentsProvider            func(ctx context.Context, userID string) (any, error)
+
+	webhookBackoff     time.Duration
+	webhookHTTPClient  *http.Client
+	webhookMaxAttempts int
+
+	beforeUserCreate func(ctx context.Context, user UserInterface) error
+	afterUserCreate  func(ctx context.Context, user UserInterface) error
+	beforeUserUpdate func(ctx context.Context, user UserInterface, dataChanged map[string]string) error
+	afterUserUpdate  func(ctx context.Context, user UserInterface, dataChanged map[string]string) error
+	beforeUserDelete func(ctx context.Context, user UserInterface) error
+	afterUserDelete  func(ctx context.Context, user UserInterface) error
 }
 
 // == INTERFACE ===============================================================
@@ -27,22 +79,80 @@ var _ StoreInterface = (*store)(nil) // verify it extends the interface
 
 // AutoMigrate auto migrate
 func (store *store) AutoMigrate() error {
-	sqlStr := store.sqlUserTableCreate()
+	if store.db == nil {
+		return errors.New("userstore: database is nil")
+	}
 
-	if sqlStr == "" {
-		return errors.New("user table create sql is empty")
+	tableCreateSqls := []string{
+		store.sqlUserTableCreate(),
+		store.sqlMfaTableCreate(),
+		store.sqlRecoveryCodeTableCreate(),
+		store.sqlPasskeyTableCreate(),
+		store.sqlTokenTableCreate(),
+		store.sqlAuditTableCreate(),
+		store.sqlEmailHistoryTableCreate(),
+		store.sqlAPIKeyTableCreate(),
+		store.sqlIdentityTableCreate(),
+		store.sqlSessionTableCreate(),
+		store.sqlRevisionTableCreate(),
+		store.sqlOutboxTableCreate(),
+		store.sqlWebhookTableCreate(),
+		store.sqlWebhookDeliveryTableCreate(),
+		store.sqlStatusHistoryTableCreate(),
 	}
 
-	if store.db == nil {
-		return errors.New("userstore: database is nil")
+	for _, sqlStr := range tableCreateSqls {
+		if sqlStr == "" {
+			return errors.New("table create sql is empty")
+		}
+
+		_, err := store.db.Exec(sqlStr)
+
+		if err != nil {
+			return err
+		}
 	}
 
-	_, err := store.db.Exec(sqlStr)
+	// the columns added to the tables after they were first released,
+	// which are missing in the tables created by the earlier versions
+	columnsAdded := [][]sb.Column{
+		store.sqlUserColumnsLockout(),
+		store.sqlUserColumnsVerification(),
+		store.sqlUserColumnsPhoneVerification(),
+		store.sqlUserColumnsSuspension(),
+	}
 
-	if err != nil {
+	for _, columns := range columnsAdded {
+		if err := store.tableColumnsAdd(store.userTableName, columns); err != nil {
+			return err
+		}
+	}
+
+	// the unique email index cannot be created, while users share an email
+	if err := store.userEmailDuplicatesCheck(); err != nil {
 		return err
 	}
 
+	indexCreateSqls := []string{
+		store.sqlUserEmailIndexCreate(),
+		store.sqlIdentityIndexCreate(),
+		store.sqlRevisionIndexCreate(),
+		store.sqlSessionTokenIndexCreate(),
+	}
+
+	for _, sqlStr := range indexCreateSqls {
+		_, err := store.db.Exec(sqlStr)
+
+		// MySQL has no "IF NOT EXISTS" for indexes
+		if err != nil && strings.Contains(err.Error(), "Duplicate key name") {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -56,6 +166,164 @@ func (st *store) EnableDebug(debug bool) {
 	st.debugEnabled = debug
 }
 
+// withTransaction executes fn within a transaction, which is committed
+// if fn succeeds, and rolled back otherwise. If the context already
+// carries a transaction, fn joins it and the caller stays in charge
+// of committing or rolling it back.
+func (store *store) withTransaction(ctx context.Context, fn func(txCtx database.QueryableContext) error) error {
+	var beginner interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	}
+
+	if store.db != nil {
+		beginner = store.db
+	}
+
+	if database.IsQueryableContext(ctx) {
+		queryableContext := ctx.(database.QueryableContext)
+
+		if queryableContext.IsTx() {
+			return fn(queryableContext)
+		}
+
+		if conn, ok := queryableContext.Queryable().(*sql.Conn); ok {
+			beginner = conn
+		}
+
+		if db, ok := queryableContext.Queryable().(*sql.DB); ok {
+			beginner = db
+		}
+	}
+
+	if beginner == nil {
+		return errors.New("userstore: database is nil")
+	}
+
+	tx, err := beginner.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	if err := fn(database.Context(ctx, tx)); err != nil {
+		if errRollback := tx.Rollback(); errRollback != nil {
+			return errors.Join(err, errRollback)
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// userEmailDuplicatesCheck returns ErrEmailDuplicates, if the users,
+// which are not soft deleted, share a (normalized) email
+func (store *store) userEmailDuplicatesCheck() error {
+	email := goqu.Func("LOWER", goqu.Func("TRIM", goqu.C(COLUMN_EMAIL)))
+
+	sqlStr, _, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.userTableName).
+		Select(email.As(COLUMN_EMAIL)).
+		Where(
+			goqu.C(COLUMN_EMAIL).Neq(""),
+			goqu.C(COLUMN_SOFT_DELETED_AT).Eq(sb.MAX_DATETIME),
+		).
+		GroupBy(email).
+		Having(goqu.COUNT("*").Gt(1)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	duplicates, err := database.SelectToMapString(database.Context(context.Background(), store.db), sqlStr)
+
+	if err != nil {
+		return err
+	}
+
+	if len(duplicates) > 0 {
+		return fmt.Errorf("%w (%d emails)", ErrEmailDuplicates, len(duplicates))
+	}
+
+	return nil
+}
+
+// tableColumnsAdd adds the columns, which the table does not have yet,
+// and sets them to their default in the existing rows
+func (store *store) tableColumnsAdd(tableName string, columns []sb.Column) error {
+	sqlStr, _, errSql := goqu.Dialect(store.dbDriverName).
+		From(tableName).
+		Where(goqu.L("1 = 0")).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	rows, err := store.db.Query(sqlStr)
+
+	if err != nil {
+		return err
+	}
+
+	existingColumnNames, err := rows.Columns()
+
+	if errClose := rows.Close(); err == nil {
+		err = errClose
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		if lo.Contains(existingColumnNames, column.Name) {
+			continue
+		}
+
+		sqlStr, err := sb.NewBuilder(sb.DatabaseDriverName(store.db)).TableColumnAdd(tableName, column)
+
+		if err != nil {
+			return err
+		}
+
+		if store.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		if _, err := store.db.Exec(sqlStr); err != nil {
+			return err
+		}
+
+		// the existing rows get the default, as the column is added
+		// as nullable, not all databases allowing NOT NULL without one
+		sqlStr, _, err = goqu.Dialect(store.dbDriverName).
+			Update(tableName).
+			Set(goqu.Record{column.Name: column.Default}).
+			Where(goqu.C(column.Name).IsNull()).
+			ToSQL()
+
+		if err != nil {
+			return err
+		}
+
+		if store.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		if _, err := store.db.Exec(sqlStr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (store *store) toQuerableContext(ctx context.Context) database.QueryableContext {
 	if database.IsQueryableContext(ctx) {
 		return ctx.(database.QueryableContext)
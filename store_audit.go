@@ -0,0 +1,297 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+	"github.com/gouniverse/utils"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// auditRedactedColumns are the columns, which values are not stored in the diffs
+var auditRedactedColumns = []string{COLUMN_PASSWORD}
+
+const auditRedactedValue = "[redacted]"
+
+// AuditCount returns the number of the audit events matching the query
+func (store *store) AuditCount(ctx context.Context, query AuditQueryInterface) (int64, error) {
+	q, err := store.auditSelectQuery(query)
+
+	if err != nil {
+		return -1, err
+	}
+
+	sqlStr, params, errSql := q.Prepared(true).
+		ClearOrder().
+		ClearLimit().
+		ClearOffset().
+		Select(goqu.COUNT(goqu.Star()).As("count")).
+		ToSQL()
+
+	if errSql != nil {
+		return -1, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	mapped, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return -1, err
+	}
+
+	if len(mapped) < 1 {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(mapped[0]["count"], 10, 64)
+}
+
+// AuditList returns the audit events matching the query,
+// the newest first unless the sort direction is set to ascending
+func (store *store) AuditList(ctx context.Context, query AuditQueryInterface) ([]AuditInterface, error) {
+	q, err := store.auditSelectQuery(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sqlStr, params, errSql := q.Prepared(true).ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := []AuditInterface{}
+
+	for _, modelMap := range modelMaps {
+		list = append(list, NewAuditFromExistingData(modelMap))
+	}
+
+	return list, nil
+}
+
+func (store *store) auditSelectQuery(query AuditQueryInterface) (*goqu.SelectDataset, error) {
+	if query == nil {
+		return nil, errors.New("audit query is nil")
+	}
+
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	q := goqu.Dialect(store.dbDriverName).From(store.auditTableName)
+
+	if query.HasActorID() {
+		q = q.Where(goqu.C(COLUMN_ACTOR_ID).Eq(query.ActorID()))
+	}
+
+	if query.HasAction() {
+		q = q.Where(goqu.C(COLUMN_ACTION).Eq(query.Action()))
+	}
+
+	if query.HasEntity() {
+		q = q.Where(goqu.C(COLUMN_ENTITY).Eq(query.Entity()))
+	}
+
+	if query.HasEntityID() {
+		q = q.Where(goqu.C(COLUMN_ENTITY_ID).Eq(query.EntityID()))
+	}
+
+	if query.HasCreatedAtGte() {
+		q = q.Where(goqu.C(COLUMN_CREATED_AT).Gte(query.CreatedAtGte()))
+	}
+
+	if query.HasCreatedAtLte() {
+		q = q.Where(goqu.C(COLUMN_CREATED_AT).Lte(query.CreatedAtLte()))
+	}
+
+	if query.HasLimit() {
+		q = q.Limit(cast.ToUint(query.Limit()))
+	}
+
+	if query.HasOffset() {
+		q = q.Offset(cast.ToUint(query.Offset()))
+	}
+
+	if strings.EqualFold(query.SortDirection(), sb.ASC) {
+		q = q.Order(goqu.C(COLUMN_CREATED_AT).Asc(), goqu.C(COLUMN_ID).Asc())
+	} else {
+		q = q.Order(goqu.C(COLUMN_CREATED_AT).Desc(), goqu.C(COLUMN_ID).Desc())
+	}
+
+	return q, nil
+}
+
+// auditCreate records an audit event. The diff and the metadata
+// are stored as JSON, and either can be nil.
+//
+// If the actor ID is empty, the actor from the context is used.
+// The metadata from the context is merged with the specified one.
+func (store *store) auditCreate(ctx context.Context, actorID, action, entity, entityID string, diff any, metadata map[string]string) error {
+	if actorID == "" {
+		actorID = ActorFromContext(ctx)
+	}
+
+	if contextMetadata := AuditMetadataFromContext(ctx); contextMetadata != nil {
+		metadata = lo.Assign(contextMetadata, metadata)
+	}
+
+	diffJson := ""
+
+	if diff != nil {
+		var err error
+		diffJson, err = utils.ToJSON(diff)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	metadataJson := ""
+
+	if metadata != nil {
+		var err error
+		metadataJson, err = utils.ToJSON(metadata)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.auditTableName).
+		Prepared(true).
+		Rows(map[string]string{
+			COLUMN_ID:         uid.HumanUid(),
+			COLUMN_ACTOR_ID:   actorID,
+			COLUMN_ACTION:     action,
+			COLUMN_ENTITY:     entity,
+			COLUMN_ENTITY_ID:  entityID,
+			COLUMN_DIFF:       diffJson,
+			COLUMN_METADATA:   metadataJson,
+			COLUMN_CREATED_AT: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		}).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// auditDiff returns the diff of the columns as column => {"before", "after"}.
+// The columns of after are compared, or if after is empty (i.e. on delete)
+// the columns of before. The unchanged columns and updated_at are skipped,
+// and the values of the sensitive columns are redacted.
+func auditDiff(before map[string]string, after map[string]string) map[string]map[string]string {
+	columns := lo.Keys(after)
+
+	if len(after) < 1 {
+		columns = lo.Keys(before)
+	}
+
+	diff := map[string]map[string]string{}
+
+	for _, column := range columns {
+		if column == COLUMN_UPDATED_AT {
+			continue
+		}
+
+		valueBefore := before[column]
+		valueAfter := after[column]
+
+		if len(after) > 0 && valueBefore == valueAfter {
+			continue
+		}
+
+		if lo.Contains(auditRedactedColumns, column) {
+			valueBefore = lo.Ternary(valueBefore == "", "", auditRedactedValue)
+			valueAfter = lo.Ternary(valueAfter == "", "", auditRedactedValue)
+		}
+
+		diff[column] = map[string]string{
+			"before": valueBefore,
+			"after":  valueAfter,
+		}
+	}
+
+	return diff
+}
+
+// auditRedactEntity redacts the values in the diffs of the audit events
+// of the entity, and clears their metadata (i.e. IP address, user agent).
+// The actions and the changed columns are kept.
+func (store *store) auditRedactEntity(ctx context.Context, entity, entityID string) (int64, error) {
+	audits, err := store.AuditList(ctx, NewAuditQuery().SetEntity(entity).SetEntityID(entityID))
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, audit := range audits {
+		diff := lo.MapValues(audit.DiffMap(), func(change map[string]string, _ string) map[string]string {
+			return lo.MapValues(change, func(value string, _ string) string {
+				return lo.Ternary(value == "", "", auditRedactedValue)
+			})
+		})
+
+		diffJson, err := utils.ToJSON(diff)
+
+		if err != nil {
+			return 0, err
+		}
+
+		sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+			Update(store.auditTableName).
+			Prepared(true).
+			Set(map[string]any{
+				COLUMN_DIFF:     diffJson,
+				COLUMN_METADATA: "",
+			}).
+			Where(goqu.C(COLUMN_ID).Eq(audit.ID())).
+			ToSQL()
+
+		if errSql != nil {
+			return 0, errSql
+		}
+
+		if store.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		if _, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(audits)), nil
+}
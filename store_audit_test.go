@@ -0,0 +1,175 @@
+package userstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gouniverse/base/database"
+)
+
+func TestStoreAuditUserMutations(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := WithActor(context.Background(), "ADMIN_ID")
+	ctx = WithAuditMetadata(ctx, map[string]string{"ip": "127.0.0.1"})
+
+	user := NewUser().
+		SetEmail("test@test.com").
+		SetFirstName("John").
+		SetStatus(USER_STATUS_ACTIVE)
+
+	if err := store.UserCreate(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	user.SetFirstName("Jane")
+
+	if err := user.SetPasswordAndHash("password"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserUpdate(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// no changes, besides updated_at, are not recorded
+	if err := store.UserRecordLoginSuccess(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSoftDelete(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserDelete(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	list, err := store.AuditList(context.Background(), NewAuditQuery().
+		SetEntity(AUDIT_ENTITY_USER).
+		SetEntityID(user.ID()).
+		SetSortDirection("asc"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	actions := []string{}
+
+	for _, audit := range list {
+		actions = append(actions, audit.Action())
+
+		if audit.ActorID() != "ADMIN_ID" {
+			t.Fatal("Actor MUST be ADMIN_ID, found:", audit.ActorID())
+		}
+
+		if audit.MetadataMap()["ip"] != "127.0.0.1" {
+			t.Fatal("Metadata ip MUST be 127.0.0.1, found:", audit.Metadata())
+		}
+	}
+
+	expected := []string{AUDIT_ACTION_CREATE, AUDIT_ACTION_UPDATE, AUDIT_ACTION_SOFT_DELETE, AUDIT_ACTION_DELETE}
+
+	if len(actions) != len(expected) {
+		t.Fatal("Audit actions MUST be", expected, "found:", actions)
+	}
+
+	for i := range expected {
+		if actions[i] != expected[i] {
+			t.Fatal("Audit actions MUST be", expected, "found:", actions)
+		}
+	}
+
+	diff := list[1].DiffMap()
+
+	if diff[COLUMN_FIRST_NAME]["before"] != "John" || diff[COLUMN_FIRST_NAME]["after"] != "Jane" {
+		t.Fatal("Diff of first name MUST be John => Jane, found:", list[1].Diff())
+	}
+
+	if diff[COLUMN_PASSWORD]["after"] != auditRedactedValue {
+		t.Fatal("Password MUST be redacted, found:", list[1].Diff())
+	}
+
+	if _, found := diff[COLUMN_EMAIL]; found {
+		t.Fatal("Unchanged email MUST NOT be in the diff, found:", list[1].Diff())
+	}
+
+	if list[3].DiffMap()[COLUMN_EMAIL]["before"] != "test@test.com" {
+		t.Fatal("Delete diff MUST contain the deleted data, found:", list[3].Diff())
+	}
+
+	count, err := store.AuditCount(context.Background(), NewAuditQuery().
+		SetEntityID(user.ID()).
+		SetAction(AUDIT_ACTION_UPDATE))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("Update audit events MUST be 1, found:", count)
+	}
+}
+
+func TestStoreAuditRolledBackWithTransaction(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	tx, err := store.DB().Begin()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the actor is added to a transaction context
+	txCtx := WithActor(database.Context(context.Background(), tx), "ADMIN_ID")
+
+	user := NewUser().SetEmail("test@test.com")
+
+	if err := store.UserCreate(txCtx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	count, err := store.AuditCount(txCtx, NewAuditQuery().SetEntityID(user.ID()).SetActorID("ADMIN_ID"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("Audit events MUST be 1 inside the transaction, found:", count)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	count, err = store.AuditCount(context.Background(), NewAuditQuery().SetEntityID(user.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 0 {
+		t.Fatal("Audit events MUST be rolled back, found:", count)
+	}
+}
@@ -1,10 +1,14 @@
 package userstore
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"net/http"
+	"time"
 
 	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
 )
 
 // NewStoreOptions define the options for creating a new block store
@@ -14,6 +18,115 @@ type NewStoreOptions struct {
 	DbDriverName       string
 	AutomigrateEnabled bool
 	DebugEnabled       bool
+
+	// The tables of the other entities are optional, each defaults to
+	// UserTableName with a suffix, i.e. "user_mfa" for MfaTableName
+	APIKeyTableName          string
+	AuditTableName           string
+	EmailHistoryTableName    string
+	IdentityTableName        string
+	MfaTableName             string
+	OutboxTableName          string
+	PasskeyTableName         string
+	RecoveryCodeTableName    string
+	RevisionTableName        string
+	SessionTableName         string
+	StatusHistoryTableName   string
+	TokenTableName           string
+	WebhookTableName         string
+	WebhookDeliveryTableName string
+
+	// EncryptionKey is used to encrypt sensitive data at rest,
+	// like the MFA and the webhook secrets. Required for using MFA
+	// and webhooks
+	EncryptionKey string
+
+	// LockoutPolicy configures locking users out after failed logins,
+	// any zero fields are replaced with the defaults
+	LockoutPolicy LockoutPolicy
+
+	// MfaIssuer is the issuer shown in the authenticator apps,
+	// defaults to "UserStore"
+	MfaIssuer string
+
+	// MfaDriftSteps is the number of 30 second steps a TOTP code
+	// is accepted before or after the current one, defaults to 1
+	MfaDriftSteps int
+
+	// EmailChangeTokenTTL is how long the confirmation token of
+	// an email change is valid, defaults to 24 hours
+	EmailChangeTokenTTL time.Duration
+
+	// IdentityEmailMatch is the rule for linking an external identity
+	// to an existing user with the same email in UserFindByIdentityOrCreate,
+	// one of the IDENTITY_EMAIL_MATCH_ constants, defaults to never
+	IdentityEmailMatch string
+
+	// PasswordResetMaxOutstanding is the number of unexpired password
+	// reset tokens a user can have at the same time, defaults to 3
+	PasswordResetMaxOutstanding int
+
+	// PhoneVerificationCodeTTL is how long the code sent for verifying
+	// the phone number is valid, defaults to 10 minutes
+	PhoneVerificationCodeTTL time.Duration
+
+	// SessionTTL is how long a session is valid, unless its expiry
+	// is set when created, defaults to 30 days
+	SessionTTL time.Duration
+
+	// RevisionRetention is the number of the latest revisions kept
+	// for each user, the older ones are deleted, defaults to 0 (keep all)
+	RevisionRetention int
+
+	// Statuses is the registry of the user statuses, only the statuses
+	// in it are valid, defaults to DefaultStatuses()
+	Statuses []UserStatus
+
+	// Roles is the registry of the user roles, only the roles
+	// in it are valid, defaults to DefaultRoles()
+	Roles []UserRole
+
+	// StatusTransitions are the allowed changes of the user status,
+	// between the statuses in the registry, defaults to DefaultStatusTransitions()
+	StatusTransitions StatusTransitions
+
+	// SmsSender delivers the phone verification codes.
+	// Required for using phone verification
+	SmsSender SmsSenderInterface
+
+	// PasswordValidator is an optional password policy, checked
+	// when the password is set through the store (i.e. on reset)
+	PasswordValidator func(password string) error
+
+	// ConsentsProvider returns the consents of the user, kept by the
+	// application, which are exported as "consents" in the Extra of
+	// UserExportPersonalData, optional
+	ConsentsProvider func(ctx context.Context, userID string) (any, error)
+
+	// WebhookBackoff is the delay before the first retry of a failed
+	// webhook delivery, doubled on each next retry (up to 24 hours),
+	// defaults to 1 minute
+	WebhookBackoff time.Duration
+
+	// WebhookHTTPClient sends the webhook requests,
+	// defaults to a client with a 10 seconds timeout
+	WebhookHTTPClient *http.Client
+
+	// WebhookMaxAttempts is the number of attempts to deliver a webhook,
+	// after which the delivery is dead (until redelivered), defaults to 8
+	WebhookMaxAttempts int
+
+	// The user lifecycle hooks are optional. They run inside the transaction
+	// of the operation (the caller's one, if in the context), and receive
+	// the transaction context, so the store can be used from them.
+	// An error from a "before" hook aborts the operation, an error from
+	// an "after" hook rolls it back. Soft deletes run the update hooks
+	BeforeUserCreate func(ctx context.Context, user UserInterface) error
+	AfterUserCreate  func(ctx context.Context, user UserInterface) error
+	BeforeUserUpdate func(ctx context.Context, user UserInterface, dataChanged map[string]string) error
+	AfterUserUpdate  func(ctx context.Context, user UserInterface, dataChanged map[string]string) error
+	BeforeUserDelete func(ctx context.Context, user UserInterface) error
+	AfterUserDelete  func(ctx context.Context, user UserInterface) error
 }
 
 // NewStore creates a new block store
@@ -30,12 +143,188 @@ func NewStore(opts NewStoreOptions) (StoreInterface, error) {
 		opts.DbDriverName = sb.DatabaseDriverName(opts.DB)
 	}
 
+	if opts.APIKeyTableName == "" {
+		opts.APIKeyTableName = opts.UserTableName + "_api_key"
+	}
+
+	if opts.AuditTableName == "" {
+		opts.AuditTableName = opts.UserTableName + "_audit"
+	}
+
+	if opts.EmailHistoryTableName == "" {
+		opts.EmailHistoryTableName = opts.UserTableName + "_email_history"
+	}
+
+	if opts.IdentityTableName == "" {
+		opts.IdentityTableName = opts.UserTableName + "_identity"
+	}
+
+	if opts.MfaTableName == "" {
+		opts.MfaTableName = opts.UserTableName + "_mfa"
+	}
+
+	if opts.OutboxTableName == "" {
+		opts.OutboxTableName = opts.UserTableName + "_outbox"
+	}
+
+	if opts.PasskeyTableName == "" {
+		opts.PasskeyTableName = opts.UserTableName + "_passkey"
+	}
+
+	if opts.RecoveryCodeTableName == "" {
+		opts.RecoveryCodeTableName = opts.UserTableName + "_recovery_code"
+	}
+
+	if opts.RevisionTableName == "" {
+		opts.RevisionTableName = opts.UserTableName + "_revision"
+	}
+
+	if opts.SessionTableName == "" {
+		opts.SessionTableName = opts.UserTableName + "_session"
+	}
+
+	if opts.StatusHistoryTableName == "" {
+		opts.StatusHistoryTableName = opts.UserTableName + "_status_history"
+	}
+
+	if opts.TokenTableName == "" {
+		opts.TokenTableName = opts.UserTableName + "_token"
+	}
+
+	if opts.WebhookTableName == "" {
+		opts.WebhookTableName = opts.UserTableName + "_webhook"
+	}
+
+	if opts.WebhookDeliveryTableName == "" {
+		opts.WebhookDeliveryTableName = opts.UserTableName + "_webhook_delivery"
+	}
+
+	if opts.MfaIssuer == "" {
+		opts.MfaIssuer = "UserStore"
+	}
+
+	if opts.MfaDriftSteps <= 0 {
+		opts.MfaDriftSteps = 1
+	}
+
+	if opts.EmailChangeTokenTTL <= 0 {
+		opts.EmailChangeTokenTTL = 24 * time.Hour
+	}
+
+	if opts.IdentityEmailMatch == "" {
+		opts.IdentityEmailMatch = IDENTITY_EMAIL_MATCH_NEVER
+	}
+
+	if !lo.Contains([]string{IDENTITY_EMAIL_MATCH_ALWAYS, IDENTITY_EMAIL_MATCH_NEVER, IDENTITY_EMAIL_MATCH_VERIFIED}, opts.IdentityEmailMatch) {
+		return nil, errors.New("user store: IdentityEmailMatch is not valid")
+	}
+
+	if opts.PhoneVerificationCodeTTL <= 0 {
+		opts.PhoneVerificationCodeTTL = 10 * time.Minute
+	}
+
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = 30 * 24 * time.Hour
+	}
+
+	if opts.RevisionRetention < 0 {
+		return nil, errors.New("user store: RevisionRetention cannot be negative")
+	}
+
+	if opts.PasswordResetMaxOutstanding <= 0 {
+		opts.PasswordResetMaxOutstanding = 3
+	}
+
+	if opts.Statuses == nil {
+		opts.Statuses = DefaultStatuses()
+	}
+
+	if opts.Roles == nil {
+		opts.Roles = DefaultRoles()
+	}
+
+	if opts.StatusTransitions == nil {
+		opts.StatusTransitions = DefaultStatusTransitions()
+	}
+
+	statusKeys := lo.Map(opts.Statuses, func(status UserStatus, _ int) string { return status.Key })
+	roleKeys := lo.Map(opts.Roles, func(role UserRole, _ int) string { return role.Key })
+
+	if len(statusKeys) < 1 || lo.Contains(statusKeys, "") || len(lo.Uniq(statusKeys)) != len(statusKeys) {
+		return nil, errors.New("user store: Statuses must have unique, not empty keys")
+	}
+
+	if len(roleKeys) < 1 || lo.Contains(roleKeys, "") || len(lo.Uniq(roleKeys)) != len(roleKeys) {
+		return nil, errors.New("user store: Roles must have unique, not empty keys")
+	}
+
+	if !lo.Every(statusKeys, opts.StatusTransitions.Statuses()) {
+		return nil, errors.New("user store: StatusTransitions has statuses, which are not in Statuses")
+	}
+
+	if opts.WebhookBackoff <= 0 {
+		opts.WebhookBackoff = time.Minute
+	}
+
+	if opts.WebhookHTTPClient == nil {
+		opts.WebhookHTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	if opts.WebhookMaxAttempts <= 0 {
+		opts.WebhookMaxAttempts = 8
+	}
+
 	store := &store{
-		userTableName:      opts.UserTableName,
-		automigrateEnabled: opts.AutomigrateEnabled,
-		db:                 opts.DB,
-		dbDriverName:       opts.DbDriverName,
-		debugEnabled:       opts.DebugEnabled,
+		userTableName:            opts.UserTableName,
+		apiKeyTableName:          opts.APIKeyTableName,
+		auditTableName:           opts.AuditTableName,
+		emailHistoryTableName:    opts.EmailHistoryTableName,
+		identityTableName:        opts.IdentityTableName,
+		mfaTableName:             opts.MfaTableName,
+		outboxTableName:          opts.OutboxTableName,
+		passkeyTableName:         opts.PasskeyTableName,
+		recoveryCodeTableName:    opts.RecoveryCodeTableName,
+		revisionTableName:        opts.RevisionTableName,
+		sessionTableName:         opts.SessionTableName,
+		statusHistoryTableName:   opts.StatusHistoryTableName,
+		tokenTableName:           opts.TokenTableName,
+		webhookDeliveryTableName: opts.WebhookDeliveryTableName,
+		webhookTableName:         opts.WebhookTableName,
+		automigrateEnabled:       opts.AutomigrateEnabled,
+		db:                       opts.DB,
+		dbDriverName:             opts.DbDriverName,
+		debugEnabled:             opts.DebugEnabled,
+		encryptionKey:            opts.EncryptionKey,
+		lockoutPolicy:            opts.LockoutPolicy.withDefaults(),
+		mfaIssuer:                opts.MfaIssuer,
+		mfaDriftSteps:            opts.MfaDriftSteps,
+
+		emailChangeTokenTTL: opts.EmailChangeTokenTTL,
+		identityEmailMatch:  opts.IdentityEmailMatch,
+
+		phoneVerificationCodeTTL: opts.PhoneVerificationCodeTTL,
+		smsSender:                opts.SmsSender,
+
+		passwordResetMaxOutstanding: opts.PasswordResetMaxOutstanding,
+		passwordValidator:           opts.PasswordValidator,
+		consentsProvider:            opts.ConsentsProvider,
+
+		sessionTTL:        opts.SessionTTL,
+		revisionRetention: opts.RevisionRetention,
+		statusTransitions: opts.StatusTransitions,
+		statuses:          opts.Statuses,
+		roles:             opts.Roles,
+
+		webhookBackoff:     opts.WebhookBackoff,
+		webhookHTTPClient:  opts.WebhookHTTPClient,
+		webhookMaxAttempts: opts.WebhookMaxAttempts,
+
+		beforeUserCreate: opts.BeforeUserCreate,
+		afterUserCreate:  opts.AfterUserCreate,
+		beforeUserUpdate: opts.BeforeUserUpdate,
+		afterUserUpdate:  opts.AfterUserUpdate,
+		beforeUserDelete: opts.BeforeUserDelete,
+		afterUserDelete:  opts.AfterUserDelete,
 	}
 
 	if store.automigrateEnabled {
@@ -0,0 +1,147 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/uid"
+	"github.com/gouniverse/utils"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// OutboxAck removes the delivered events from the outbox
+func (store *store) OutboxAck(ctx context.Context, ids []string) error {
+	if len(ids) < 1 {
+		return nil
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.outboxTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_ID).In(ids)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// OutboxFetch returns up to limit undelivered events, the oldest first.
+//
+// The events stay in the outbox until acknowledged with OutboxAck,
+// so an event is delivered at least once, and can be delivered again
+// if the worker fails before the acknowledgement
+func (store *store) OutboxFetch(ctx context.Context, limit int) ([]OutboxEventInterface, error) {
+	if limit < 1 {
+		return nil, errors.New("limit must be positive")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.outboxTableName).
+		Prepared(true).
+		Order(goqu.C(COLUMN_CREATED_AT).Asc(), goqu.C(COLUMN_ID).Asc()).
+		Limit(cast.ToUint(limit)).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := []OutboxEventInterface{}
+
+	for _, modelMap := range modelMaps {
+		list = append(list, NewOutboxEventFromExistingData(modelMap))
+	}
+
+	return list, nil
+}
+
+// outboxCreate adds an event to the outbox, the payload is stored as JSON,
+// and queues its webhook deliveries. Called inside the transaction of
+// the change, so the event is stored if and only if the change is
+func (store *store) outboxCreate(ctx context.Context, eventType, userID string, payload any) error {
+	payloadJson, err := utils.ToJSON(payload)
+
+	if err != nil {
+		return err
+	}
+
+	eventID := uid.HumanUid()
+	createdAt := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.outboxTableName).
+		Prepared(true).
+		Rows(map[string]string{
+			COLUMN_ID:         eventID,
+			COLUMN_TYPE:       eventType,
+			COLUMN_USER_ID:    userID,
+			COLUMN_PAYLOAD:    payloadJson,
+			COLUMN_CREATED_AT: createdAt,
+		}).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err = database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	// the webhook body is the event as delivered, with the payload as data
+	body, err := utils.ToJSON(map[string]any{
+		"id":         eventID,
+		"type":       eventType,
+		"user_id":    userID,
+		"created_at": createdAt,
+		"data":       payload,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return store.webhookDeliveriesCreate(ctx, eventID, eventType, userID, body)
+}
+
+// outboxRedact returns a copy of the user data
+// with the values of the sensitive columns redacted
+func outboxRedact(data map[string]string) map[string]string {
+	return lo.MapEntries(data, func(column string, value string) (string, string) {
+		if value != "" && lo.Contains(auditRedactedColumns, column) {
+			return column, auditRedactedValue
+		}
+
+		return column, value
+	})
+}
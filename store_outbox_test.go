@@ -0,0 +1,191 @@
+package userstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gouniverse/base/database"
+)
+
+// outboxPublisherMemory is an in-memory message bus for the tests
+type outboxPublisherMemory struct {
+	events []OutboxEventInterface
+}
+
+func (p *outboxPublisherMemory) Publish(event OutboxEventInterface) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+// outboxRelay delivers the events in batches, like a worker would
+func outboxRelay(ctx context.Context, store StoreInterface, publisher *outboxPublisherMemory, batchSize int) error {
+	for {
+		events, err := store.OutboxFetch(ctx, batchSize)
+
+		if err != nil {
+			return err
+		}
+
+		if len(events) < 1 {
+			return nil
+		}
+
+		ids := []string{}
+
+		for _, event := range events {
+			if err := publisher.Publish(event); err != nil {
+				return err
+			}
+
+			ids = append(ids, event.ID())
+		}
+
+		if err := store.OutboxAck(ctx, ids); err != nil {
+			return err
+		}
+	}
+}
+
+func TestStoreOutbox(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	user := NewUser().
+		SetEmail("test@test.com").
+		SetPassword("secret").
+		SetStatus(USER_STATUS_UNVERIFIED)
+
+	if err := store.UserCreate(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserUpdate(ctx, user.SetFirstName("John")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserUpdate(ctx, user.SetStatus(USER_STATUS_ACTIVE)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSoftDelete(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserDeleteByID(ctx, user.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	publisher := &outboxPublisherMemory{}
+
+	if err := outboxRelay(ctx, store, publisher, 2); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := []string{
+		OUTBOX_EVENT_USER_CREATED,
+		OUTBOX_EVENT_USER_UPDATED,
+		OUTBOX_EVENT_USER_UPDATED,
+		OUTBOX_EVENT_USER_STATUS_CHANGED,
+		OUTBOX_EVENT_USER_DELETED,
+		OUTBOX_EVENT_USER_DELETED,
+	}
+
+	if len(publisher.events) != len(expected) {
+		t.Fatal("Events MUST be", len(expected), "found:", len(publisher.events))
+	}
+
+	for i, event := range publisher.events {
+		if event.Type() != expected[i] {
+			t.Fatal("Event", i, "MUST be", expected[i], "found:", event.Type())
+		}
+
+		if event.UserID() != user.ID() {
+			t.Fatal("Event user ID MUST be", user.ID(), "found:", event.UserID())
+		}
+	}
+
+	created := publisher.events[0].PayloadMap()["user"].(map[string]any)
+
+	if created[COLUMN_EMAIL] != "test@test.com" {
+		t.Fatal("Created payload MUST contain the email, found:", created[COLUMN_EMAIL])
+	}
+
+	if created[COLUMN_PASSWORD] != auditRedactedValue {
+		t.Fatal("Password MUST be redacted, found:", created[COLUMN_PASSWORD])
+	}
+
+	changed := publisher.events[1].PayloadMap()["changed"].(map[string]any)
+
+	if len(changed) != 1 || changed[COLUMN_FIRST_NAME] != "John" {
+		t.Fatal("Updated payload MUST contain the changed fields, found:", changed)
+	}
+
+	statusChanged := publisher.events[3].PayloadMap()
+
+	if statusChanged["before"] != USER_STATUS_UNVERIFIED || statusChanged["after"] != USER_STATUS_ACTIVE {
+		t.Fatal("Status changed payload MUST contain the statuses, found:", statusChanged)
+	}
+
+	if publisher.events[4].PayloadMap()["soft_deleted"] != true {
+		t.Fatal("Soft delete MUST be marked, found:", publisher.events[4].PayloadMap())
+	}
+
+	events, err := store.OutboxFetch(ctx, 10)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(events) != 0 {
+		t.Fatal("Acknowledged events MUST be removed, found:", len(events))
+	}
+}
+
+func TestStoreOutboxRollback(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	tx, err := store.DB().Begin()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserCreate(database.Context(context.Background(), tx), NewUser()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	events, err := store.OutboxFetch(context.Background(), 10)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(events) != 0 {
+		t.Fatal("Events of a rolled back transaction MUST NOT be stored, found:", len(events))
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"os"
 	"testing"
 
+	"github.com/dromara/carbon/v2"
 	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
 	"github.com/gouniverse/utils"
 	_ "modernc.org/sqlite"
 )
@@ -42,6 +44,7 @@ func initStore(filepath string) (StoreInterface, error) {
 		DB:                 db,
 		UserTableName:      "user_table",
 		AutomigrateEnabled: true,
+		EncryptionKey:      "test_encryption_key",
 	})
 
 	if err != nil {
@@ -55,6 +58,33 @@ func initStore(filepath string) (StoreInterface, error) {
 	return store, nil
 }
 
+// userTableCreateAsReleased creates the user table, as created by
+// the first release, without the columns added later
+func userTableCreateAsReleased(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE user_table (
+		id TEXT(40) PRIMARY KEY NOT NULL,
+		status TEXT(40) NOT NULL,
+		first_name TEXT(50) NOT NULL,
+		middle_names TEXT(50) NOT NULL,
+		last_name TEXT(50) NOT NULL,
+		business_name TEXT(100) NOT NULL,
+		phone TEXT(20) NOT NULL,
+		email TEXT(100) NOT NULL,
+		password TEXT(255) NOT NULL,
+		role TEXT(40) NOT NULL,
+		country TEXT(2) NOT NULL,
+		timezone TEXT(40) NOT NULL,
+		profile_image_url TEXT(255) NOT NULL,
+		metas TEXT NOT NULL,
+		memo TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		soft_deleted_at DATETIME NOT NULL
+	)`)
+
+	return err
+}
+
 func TestStoreWithTx(t *testing.T) {
 	store, err := initStore("test_store_with_tx.db")
 
@@ -144,3 +174,175 @@ func TestStoreWithTx(t *testing.T) {
 		t.Fatal("User MUST be John 2, as transaction committed")
 	}
 }
+
+func TestStoreAutoMigrateColumnsAdd(t *testing.T) {
+	db, err := initDB("test_store_automigrate_columns_add.db")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = userTableCreateAsReleased(db)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = db.Exec(`INSERT INTO user_table VALUES (
+		'USER01', 'active', 'John', '', 'Doe', '', '', 'test@test.com', '', '', '', '', '', '{}', '',
+		'2020-01-01 00:00:00', '2020-01-01 00:00:00', '9999-12-31 23:59:59'
+	)`)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		EncryptionKey:      "test_encryption_key",
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// running it again must not try to add the columns twice
+	err = store.AutoMigrate()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	rows, err := db.Query("SELECT * FROM user_table WHERE 1 = 0")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	columnNames, err := rows.Columns()
+	rows.Close()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expectedColumnNames := []string{
+		COLUMN_FAILED_LOGIN_COUNT,
+		COLUMN_FAILED_LOGIN_FIRST_AT,
+		COLUMN_LOCKOUT_COUNT,
+		COLUMN_LOCKED_UNTIL,
+		COLUMN_VERIFIED_AT,
+		COLUMN_PHONE_VERIFIED_AT,
+		COLUMN_SUSPENDED_UNTIL,
+		COLUMN_SUSPENDED_BY,
+		COLUMN_SUSPENSION_REASON,
+	}
+
+	for _, expectedColumnName := range expectedColumnNames {
+		found := false
+
+		for _, columnName := range columnNames {
+			if columnName == expectedColumnName {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			t.Fatal("Column must be added:", expectedColumnName)
+		}
+	}
+
+	user, err := store.UserFindByID(context.Background(), "USER01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if user == nil {
+		t.Fatal("User MUST NOT be nil")
+	}
+
+	if user.FailedLoginCount() != 0 {
+		t.Fatal("FailedLoginCount MUST be 0, found:", user.FailedLoginCount())
+	}
+
+	if user.LockedUntilCarbon().ToDateTimeString(carbon.UTC) != sb.NULL_DATETIME {
+		t.Fatal("LockedUntil MUST be NULL_DATETIME, found:", user.LockedUntil())
+	}
+
+	if user.VerifiedAtCarbon().ToDateTimeString(carbon.UTC) != sb.NULL_DATETIME {
+		t.Fatal("VerifiedAt MUST be NULL_DATETIME, found:", user.VerifiedAt())
+	}
+
+	if user.PhoneVerifiedAtCarbon().ToDateTimeString(carbon.UTC) != sb.NULL_DATETIME {
+		t.Fatal("PhoneVerifiedAt MUST be NULL_DATETIME, found:", user.PhoneVerifiedAt())
+	}
+
+	if user.IsSuspended() || user.SuspensionReason() != "" {
+		t.Fatal("User MUST NOT be suspended, found:", user.SuspendedUntil(), user.SuspensionReason())
+	}
+
+	if err := store.UserCreate(context.Background(), NewUser().SetEmail("new@test.com")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestStoreAutoMigrateEmailDuplicates(t *testing.T) {
+	db, err := initDB("test_store_automigrate_email_duplicates.db")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	err = userTableCreateAsReleased(db)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the same email, differing in case and whitespace only
+	for id, email := range map[string]string{"USER01": "test@test.com", "USER02": " Test@Test.com"} {
+		_, err = db.Exec(`INSERT INTO user_table VALUES (
+			'` + id + `', 'active', '', '', '', '', '', '` + email + `', '', '', '', '', '', '{}', '',
+			'2020-01-01 00:00:00', '2020-01-01 00:00:00', '9999-12-31 23:59:59'
+		)`)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	options := NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		EncryptionKey:      "test_encryption_key",
+	}
+
+	_, err = NewStore(options)
+
+	if !errors.Is(err, ErrEmailDuplicates) {
+		t.Fatal("Error MUST be ErrEmailDuplicates, found:", err)
+	}
+
+	_, err = db.Exec(`UPDATE user_table SET soft_deleted_at = '2020-01-02 00:00:00' WHERE id = 'USER02'`)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = NewStore(options)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
@@ -63,30 +63,70 @@ func (store *store) UserCreate(ctx context.Context, user UserInterface) error {
 		return errors.New("user is nil")
 	}
 
-	user.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
-	user.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	if store.db == nil {
+		return errors.New("userstore: database is nil")
+	}
 
-	data := user.Data()
+	err := store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		if store.beforeUserCreate != nil {
+			if err := store.beforeUserCreate(txCtx, user); err != nil {
+				return err
+			}
+		}
 
-	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
-		Insert(store.userTableName).
-		Prepared(true).
-		Rows(data).
-		ToSQL()
+		if _, found := store.statusFind(user.Status()); !found {
+			return ErrStatusInvalid
+		}
 
-	if errSql != nil {
-		return errSql
-	}
+		if _, found := store.roleFind(user.Role()); !found {
+			return ErrRoleInvalid
+		}
 
-	if store.debugEnabled {
-		log.Println(sqlStr)
-	}
+		userEmailTrim(user)
 
-	if store.db == nil {
-		return errors.New("userstore: database is nil")
-	}
+		user.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+		user.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+		data := user.Data()
+
+		sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+			Insert(store.userTableName).
+			Prepared(true).
+			Rows(data).
+			ToSQL()
+
+		if errSql != nil {
+			return errSql
+		}
 
-	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+		if store.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		if _, err := database.Execute(txCtx, sqlStr, params...); err != nil {
+			return err
+		}
+
+		if err := store.revisionCreate(txCtx, user.ID(), nil, data); err != nil {
+			return err
+		}
+
+		if err := store.auditCreate(txCtx, "", AUDIT_ACTION_CREATE, AUDIT_ENTITY_USER, user.ID(), auditDiff(nil, data), nil); err != nil {
+			return err
+		}
+
+		if err := store.outboxCreate(txCtx, OUTBOX_EVENT_USER_CREATED, user.ID(), map[string]any{
+			"user": outboxRedact(data),
+		}); err != nil {
+			return err
+		}
+
+		if store.afterUserCreate != nil {
+			return store.afterUserCreate(txCtx, user)
+		}
+
+		return nil
+	})
 
 	if err != nil {
 		return err
@@ -124,9 +164,49 @@ func (store *store) UserDeleteByID(ctx context.Context, id string) error {
 		log.Println(sqlStr)
 	}
 
-	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+	return store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		before, err := store.userDataByID(txCtx, id)
+
+		if err != nil {
+			return err
+		}
+
+		if before == nil {
+			return nil // nothing to delete
+		}
+
+		if store.beforeUserDelete != nil {
+			if err := store.beforeUserDelete(txCtx, NewUserFromExistingData(before)); err != nil {
+				return err
+			}
+		}
+
+		if _, err := database.Execute(txCtx, sqlStr, params...); err != nil {
+			return err
+		}
 
-	return err
+		// the sessions are kept for the audit, but can no longer be used
+		if err := store.sessionsRevokeByUserID(txCtx, id, ""); err != nil {
+			return err
+		}
+
+		if err := store.auditCreate(txCtx, "", AUDIT_ACTION_DELETE, AUDIT_ENTITY_USER, id, auditDiff(before, nil), nil); err != nil {
+			return err
+		}
+
+		if err := store.outboxCreate(txCtx, OUTBOX_EVENT_USER_DELETED, id, map[string]any{
+			"user":         outboxRedact(before),
+			"soft_deleted": false,
+		}); err != nil {
+			return err
+		}
+
+		if store.afterUserDelete != nil {
+			return store.afterUserDelete(txCtx, NewUserFromExistingData(before))
+		}
+
+		return nil
+	})
 }
 
 func (store *store) UserFindByEmail(ctx context.Context, email string) (user UserInterface, err error) {
@@ -149,7 +229,10 @@ func (store *store) UserFindByEmail(ctx context.Context, email string) (user Use
 	return nil, nil
 }
 
-// UserFindByEmailOrCreate - finds by email or creates a user (with active status)
+// UserFindByEmailOrCreate - finds by email or creates a user (with the specified status)
+//
+// Safe for concurrent calls with the same email, the unique email index
+// rejects all but the first insert, the others return the user created by it
 func (store *store) UserFindByEmailOrCreate(ctx context.Context, email, createStatus string) (UserInterface, error) {
 	existingUser, errUser := store.UserFindByEmail(ctx, email)
 
@@ -167,11 +250,18 @@ func (store *store) UserFindByEmailOrCreate(ctx context.Context, email, createSt
 
 	errCreate := store.UserCreate(ctx, newUser)
 
-	if errCreate != nil {
-		return nil, errCreate
+	if errCreate == nil {
+		return newUser, nil
+	}
+
+	// the user may have been created concurrently
+	existingUser, errUser = store.UserFindByEmail(ctx, email)
+
+	if errUser == nil && existingUser != nil {
+		return existingUser, nil
 	}
 
-	return newUser, nil
+	return nil, errCreate
 }
 
 func (store *store) UserFindByID(ctx context.Context, id string) (user UserInterface, err error) {
@@ -241,6 +331,68 @@ func (store *store) UserList(ctx context.Context, query UserQueryInterface) ([]U
 	return list, nil
 }
 
+// UserRestore restores the soft deleted user. Restoring a user,
+// which is not soft deleted, does nothing.
+//
+// Returns ErrEmailTaken if the email of the user was taken by another
+// user since the user was soft deleted.
+func (store *store) UserRestore(ctx context.Context, user UserInterface) error {
+	if user == nil {
+		return errors.New("at user restore > user is nil")
+	}
+
+	return store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		current, err := store.userDataByID(txCtx, user.ID())
+
+		if err != nil {
+			return err
+		}
+
+		if current == nil {
+			return ErrUserNotFound
+		}
+
+		if !NewUserFromExistingData(current).IsSoftDeleted() {
+			return nil
+		}
+
+		if user.Email() != "" {
+			existing, err := store.UserFindByEmail(txCtx, user.Email())
+
+			if err != nil {
+				return err
+			}
+
+			if existing != nil && existing.ID() != user.ID() {
+				return ErrEmailTaken
+			}
+		}
+
+		user.SetSoftDeletedAt(sb.MAX_DATETIME)
+
+		return store.UserUpdate(txCtx, user)
+	})
+}
+
+// UserRestoreByID restores the soft deleted user with the specified ID
+func (store *store) UserRestoreByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("user id is empty")
+	}
+
+	data, err := store.userDataByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		return ErrUserNotFound
+	}
+
+	return store.UserRestore(ctx, NewUserFromExistingData(data))
+}
+
 func (store *store) UserSoftDelete(ctx context.Context, user UserInterface) error {
 	if user == nil {
 		return errors.New("at user soft delete > user is nil")
@@ -261,45 +413,213 @@ func (store *store) UserSoftDeleteByID(ctx context.Context, id string) error {
 	return store.UserSoftDelete(ctx, user)
 }
 
+// UserUpdate stores the changes of the user. A status change is checked
+// against the status transitions, and recorded in the status history.
+// A changed email is no longer verified, unless verified_at is set by
+// the same change.
+//
+// Returns ErrStatusTransitionNotAllowed if the transition is not allowed.
 func (store *store) UserUpdate(ctx context.Context, user UserInterface) error {
 	if user == nil {
 		return errors.New("at user update > user is nil")
 	}
 
+	if store.db == nil {
+		return errors.New("userstore: database is nil")
+	}
+
 	user.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
 
-	dataChanged := user.DataChanged()
+	userEmailTrim(user)
+
+	err := store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		if store.beforeUserUpdate != nil {
+			if err := store.beforeUserUpdate(txCtx, user, user.DataChanged()); err != nil {
+				return err
+			}
+		}
+
+		dataChanged := user.DataChanged()
+
+		delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+		if len(dataChanged) < 1 {
+			return nil
+		}
+
+		if status, ok := dataChanged[COLUMN_STATUS]; ok {
+			if _, found := store.statusFind(status); !found {
+				return ErrStatusInvalid
+			}
+		}
+
+		if role, ok := dataChanged[COLUMN_ROLE]; ok {
+			if _, found := store.roleFind(role); !found {
+				return ErrRoleInvalid
+			}
+		}
+
+		before, err := store.userDataByID(txCtx, user.ID())
+
+		if err != nil {
+			return err
+		}
+
+		// a new email is not verified, unless the change verifies it too
+		newEmail, emailChanged := dataChanged[COLUMN_EMAIL]
+		_, verifiedAtChanged := dataChanged[COLUMN_VERIFIED_AT]
+
+		if emailChanged && !verifiedAtChanged && before != nil && before[COLUMN_EMAIL] != newEmail {
+			user.SetVerifiedAt(sb.NULL_DATETIME)
+			dataChanged[COLUMN_VERIFIED_AT] = sb.NULL_DATETIME
+		}
+
+		sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+			Update(store.userTableName).
+			Prepared(true).
+			Set(dataChanged).
+			Where(goqu.C(COLUMN_ID).Eq(user.ID())).
+			ToSQL()
+
+		if errSql != nil {
+			return errSql
+		}
+
+		if store.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		// the status changes only as the transitions allow,
+		// and every change is recorded in the status history
+		newStatus, statusChanged := dataChanged[COLUMN_STATUS]
+		statusChanged = statusChanged && before != nil && before[COLUMN_STATUS] != newStatus
+
+		if statusChanged && !store.statusTransitions.IsAllowed(before[COLUMN_STATUS], newStatus) {
+			return ErrStatusTransitionNotAllowed
+		}
+
+		if _, err := database.Execute(txCtx, sqlStr, params...); err != nil {
+			return err
+		}
+
+		if statusChanged {
+			if err := store.statusHistoryCreate(txCtx, user.ID(), before[COLUMN_STATUS], newStatus); err != nil {
+				return err
+			}
+		}
+
+		// the sessions are revoked, when the password changes or the user is soft deleted
+		_, passwordChanged := dataChanged[COLUMN_PASSWORD]
+		_, softDeletedAtChanged := dataChanged[COLUMN_SOFT_DELETED_AT]
+		softDeleted := softDeletedAtChanged && user.SoftDeletedAtCarbon().Compare("<=", carbon.Now(carbon.UTC))
+		restored := softDeletedAtChanged && !softDeleted && before != nil && NewUserFromExistingData(before).IsSoftDeleted()
+
+		if passwordChanged || softDeleted {
+			if err := store.sessionsRevokeByUserID(txCtx, user.ID(), ""); err != nil {
+				return err
+			}
+		}
+
+		diff := auditDiff(before, dataChanged)
+
+		if len(diff) > 0 {
+			if err := store.revisionCreate(txCtx, user.ID(), before, dataChanged); err != nil {
+				return err
+			}
+
+			action := lo.If(softDeleted, AUDIT_ACTION_SOFT_DELETE).
+				ElseIf(restored, AUDIT_ACTION_RESTORE).
+				Else(AUDIT_ACTION_UPDATE)
+
+			if err := store.auditCreate(txCtx, "", action, AUDIT_ENTITY_USER, user.ID(), diff, nil); err != nil {
+				return err
+			}
+
+			if err := store.outboxCreateUserUpdated(txCtx, user.ID(), diff, softDeleted); err != nil {
+				return err
+			}
+		}
 
-	delete(dataChanged, COLUMN_ID) // ID is not updateable
+		if store.afterUserUpdate != nil {
+			return store.afterUserUpdate(txCtx, user, dataChanged)
+		}
 
-	if len(dataChanged) < 1 {
 		return nil
+	})
+
+	if err != nil {
+		return err
 	}
 
-	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
-		Update(store.userTableName).
-		Prepared(true).
-		Set(dataChanged).
-		Where(goqu.C(COLUMN_ID).Eq(user.ID())).
-		ToSQL()
+	user.MarkAsNotDirty()
 
-	if errSql != nil {
-		return errSql
+	return nil
+}
+
+// outboxCreateUserUpdated adds the events of a user update to the outbox:
+// user.deleted when soft deleted, otherwise user.updated with the changed
+// columns, and user.status_changed too when the status changed
+func (store *store) outboxCreateUserUpdated(ctx context.Context, userID string, diff map[string]map[string]string, softDeleted bool) error {
+	changed := lo.MapValues(diff, func(values map[string]string, _ string) string {
+		return values["after"]
+	})
+
+	if softDeleted {
+		return store.outboxCreate(ctx, OUTBOX_EVENT_USER_DELETED, userID, map[string]any{
+			"changed":      changed,
+			"soft_deleted": true,
+		})
 	}
 
-	if store.debugEnabled {
-		log.Println(sqlStr)
+	if err := store.outboxCreate(ctx, OUTBOX_EVENT_USER_UPDATED, userID, map[string]any{
+		"changed": changed,
+	}); err != nil {
+		return err
 	}
 
-	if store.db == nil {
-		return errors.New("userstore: database is nil")
+	if status, ok := diff[COLUMN_STATUS]; ok {
+		return store.outboxCreate(ctx, OUTBOX_EVENT_USER_STATUS_CHANGED, userID, map[string]any{
+			"before": status["before"],
+			"after":  status["after"],
+		})
 	}
 
-	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+	return nil
+}
 
-	user.MarkAsNotDirty()
+// userEmailTrim trims the email of the user. Its case is kept, as the email
+// may be tokenized, and the tokens are case sensitive, the emails are
+// compared lowercased instead.
+func userEmailTrim(user UserInterface) {
+	if email := strings.TrimSpace(user.Email()); email != user.Email() {
+		user.SetEmail(email)
+	}
+}
+
+// userDataByID returns the stored data of the user, including
+// the soft deleted ones, or nil if not found
+func (store *store) userDataByID(ctx context.Context, id string) (map[string]string, error) {
+	list, err := store.UserList(ctx, NewUserQuery().
+		SetID(id).
+		SetSoftDeletedIncluded(true).
+		SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) < 1 {
+		return nil, nil
+	}
 
-	return err
+	// SQLite returns the datetimes with the timezone appended
+	data := map[string]string{}
+
+	for column, value := range list[0].Data() {
+		data[column] = strings.TrimSuffix(value, " +0000 UTC")
+	}
+
+	return data, nil
 }
 
 func (store *store) userSelectQuery(options UserQueryInterface) (selectDataset *goqu.SelectDataset, columns []any, err error) {
@@ -330,7 +650,7 @@ func (store *store) userSelectQuery(options UserQueryInterface) (selectDataset *
 	}
 
 	if options.HasEmail() {
-		q = q.Where(goqu.C(COLUMN_EMAIL).Eq(options.Email()))
+		q = q.Where(goqu.Func("LOWER", goqu.Func("TRIM", goqu.C(COLUMN_EMAIL))).Eq(emailNormalize(options.Email())))
 	}
 
 	if options.HasMetaLike() {
@@ -348,6 +668,14 @@ func (store *store) userSelectQuery(options UserQueryInterface) (selectDataset *
 		q = q.Where(goqu.C(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()))
 	}
 
+	if options.HasSoftDeletedAtGte() {
+		q = q.Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gte(options.SoftDeletedAtGte()))
+	}
+
+	if options.HasSoftDeletedAtLte() {
+		q = q.Where(goqu.C(COLUMN_SOFT_DELETED_AT).Lte(options.SoftDeletedAtLte()))
+	}
+
 	if !options.IsCountOnly() {
 		if options.HasLimit() {
 			q = q.Limit(cast.ToUint(options.Limit()))
@@ -373,6 +701,13 @@ func (store *store) userSelectQuery(options UserQueryInterface) (selectDataset *
 		columns = append(columns, column)
 	}
 
+	if options.OnlySoftDeleted() {
+		onlySoftDeleted := goqu.C(COLUMN_SOFT_DELETED_AT).
+			Lte(carbon.Now(carbon.UTC).ToDateTimeString())
+
+		return q.Where(onlySoftDeleted), columns, nil
+	}
+
 	if options.SoftDeletedIncluded() {
 		return q, columns, nil // soft deleted users requested specifically
 	}
@@ -0,0 +1,250 @@
+package userstore
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/utils"
+)
+
+// The presented API keys have the format "usk_<prefix>_<secret>".
+// The prefix is stored as it is, and identifies the key. Only the
+// hash of the secret is stored.
+
+const apiKeyMarker = "usk_"
+const apiKeyPrefixAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+const apiKeyPrefixLength = 12
+const apiKeySecretLength = 32
+
+// UserAPIKeyCreate creates the API key, which is expected to have
+// the user ID, the name, the scopes and optionally the expiry set.
+//
+// Returns the key to be presented by the client. It is shown to the
+// user once, as only the hash of its secret part is stored.
+func (store *store) UserAPIKeyCreate(ctx context.Context, apiKey APIKeyInterface) (string, error) {
+	if apiKey == nil {
+		return "", errors.New("api key is nil")
+	}
+
+	if apiKey.UserID() == "" {
+		return "", errors.New("api key user id is empty")
+	}
+
+	prefix, err := randomFromAlphabet(apiKeyPrefixLength, apiKeyPrefixAlphabet)
+
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := randomFromAlphabet(apiKeySecretLength, tokenAlphabet)
+
+	if err != nil {
+		return "", err
+	}
+
+	apiKey.SetKeyPrefix(prefix)
+	apiKey.SetKeyHash(utils.StrToSHA256Hash(secret))
+	apiKey.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	apiKey.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.apiKeyTableName).
+		Prepared(true).
+		Rows(apiKey.Data()).
+		ToSQL()
+
+	if errSql != nil {
+		return "", errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err = database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return "", err
+	}
+
+	apiKey.MarkAsNotDirty()
+
+	return apiKeyMarker + prefix + "_" + secret, nil
+}
+
+// UserAPIKeyList returns the API keys of the user, which are not revoked,
+// including the expired ones, the most recently created first
+func (store *store) UserAPIKeyList(ctx context.Context, userID string) ([]APIKeyInterface, error) {
+	if userID == "" {
+		return nil, errors.New("user id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.apiKeyTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))).
+		Order(goqu.C(COLUMN_CREATED_AT).Desc()).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := []APIKeyInterface{}
+
+	for _, modelMap := range modelMaps {
+		list = append(list, NewAPIKeyFromExistingData(modelMap))
+	}
+
+	return list, nil
+}
+
+// UserAPIKeyRevoke revokes the API key with the specified ID
+func (store *store) UserAPIKeyRevoke(ctx context.Context, apiKeyID string) error {
+	if apiKeyID == "" {
+		return errors.New("api key id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.apiKeyTableName).
+		Prepared(true).
+		Set(map[string]any{
+			COLUMN_SOFT_DELETED_AT: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+			COLUMN_UPDATED_AT:      carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		}).
+		Where(goqu.C(COLUMN_ID).Eq(apiKeyID)).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// UserAPIKeyVerify verifies the API key presented by a client, and
+// records its use. Returns the user the key belongs to, and the key
+// (i.e. to check its scopes).
+//
+// Returns ErrAPIKeyInvalid if the key is not known, expired, revoked,
+// or the user is not found or cannot login (i.e. locked or suspended).
+func (store *store) UserAPIKeyVerify(ctx context.Context, presented string) (UserInterface, APIKeyInterface, error) {
+	prefix, secret, found := strings.Cut(strings.TrimPrefix(presented, apiKeyMarker), "_")
+
+	if !strings.HasPrefix(presented, apiKeyMarker) || !found || prefix == "" || secret == "" {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+
+	apiKey, err := store.apiKeyFindByPrefix(ctx, prefix)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if apiKey == nil {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+
+	secretHash := utils.StrToSHA256Hash(secret)
+
+	if subtle.ConstantTimeCompare([]byte(secretHash), []byte(apiKey.KeyHash())) != 1 {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+
+	if apiKey.IsRevoked() || apiKey.IsExpired() {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+
+	user, err := store.UserFindByID(ctx, apiKey.UserID())
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the key gives no more access than the password of the user
+	if user == nil || !store.UserCanLogin(user) {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+
+	apiKey.SetLastUsedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.apiKeyTableName).
+		Prepared(true).
+		Set(map[string]any{
+			COLUMN_LAST_USED_AT: apiKey.LastUsedAt(),
+		}).
+		Where(goqu.C(COLUMN_ID).Eq(apiKey.ID())).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err = database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	apiKey.MarkAsNotDirty()
+
+	return user, apiKey, nil
+}
+
+func (store *store) apiKeyFindByPrefix(ctx context.Context, prefix string) (APIKeyInterface, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.apiKeyTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_KEY_PREFIX).Eq(prefix)).
+		Limit(1).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(modelMaps) < 1 {
+		return nil, nil
+	}
+
+	return NewAPIKeyFromExistingData(modelMaps[0]), nil
+}
@@ -0,0 +1,225 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func TestStoreUserAPIKeyCreateAndVerify(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetStatus(USER_STATUS_ACTIVE)
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	apiKey := NewAPIKey().
+		SetUserID(user.ID()).
+		SetName("CI").
+		SetScopes([]string{"read", " write", "read"})
+
+	presented, err := store.UserAPIKeyCreate(context.Background(), apiKey)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !strings.HasPrefix(presented, "usk_"+apiKey.KeyPrefix()+"_") {
+		t.Fatal("Key MUST start with the prefix, found:", presented)
+	}
+
+	if strings.Contains(apiKey.KeyHash(), strings.Split(presented, "_")[2]) {
+		t.Fatal("Secret MUST NOT be stored in plain text")
+	}
+
+	userFound, apiKeyFound, err := store.UserAPIKeyVerify(context.Background(), presented)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.ID() != user.ID() {
+		t.Fatal("User IDs do not match")
+	}
+
+	if apiKeyFound.ID() != apiKey.ID() {
+		t.Fatal("API key IDs do not match")
+	}
+
+	if !apiKeyFound.HasScope("write") || len(apiKeyFound.Scopes()) != 2 {
+		t.Fatal("Scopes MUST be read and write, found:", apiKeyFound.Scopes())
+	}
+
+	if apiKeyFound.LastUsedAtCarbon().Year() < 2000 {
+		t.Fatal("Last used at MUST be set, found:", apiKeyFound.LastUsedAt())
+	}
+
+	for _, wrong := range []string{"", "usk_", "usk_abc", presented + "x", strings.Replace(presented, "usk_", "xyz_", 1)} {
+		_, _, err = store.UserAPIKeyVerify(context.Background(), wrong)
+
+		if !errors.Is(err, ErrAPIKeyInvalid) {
+			t.Fatal("Error MUST be ErrAPIKeyInvalid for", wrong, "found:", err)
+		}
+	}
+}
+
+func TestStoreUserAPIKeyRevokeAndExpire(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetStatus(USER_STATUS_ACTIVE)
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	apiKeyRevoked := NewAPIKey().SetUserID(user.ID()).SetName("Revoked")
+
+	presentedRevoked, err := store.UserAPIKeyCreate(context.Background(), apiKeyRevoked)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	apiKeyExpiring := NewAPIKey().
+		SetUserID(user.ID()).
+		SetName("Expiring").
+		SetExpiresAt(carbon.Now(carbon.UTC).AddHour().ToDateTimeString(carbon.UTC))
+
+	presentedExpiring, err := store.UserAPIKeyCreate(context.Background(), apiKeyExpiring)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	list, err := store.UserAPIKeyList(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 2 {
+		t.Fatal("API keys MUST be 2, found:", len(list))
+	}
+
+	err = store.UserAPIKeyRevoke(context.Background(), apiKeyRevoked.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, _, err = store.UserAPIKeyVerify(context.Background(), presentedRevoked)
+
+	if !errors.Is(err, ErrAPIKeyInvalid) {
+		t.Fatal("Revoked key MUST NOT be accepted, found:", err)
+	}
+
+	list, err = store.UserAPIKeyList(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 1 {
+		t.Fatal("API keys MUST be 1, found:", len(list))
+	}
+
+	_, _, err = store.UserAPIKeyVerify(context.Background(), presentedExpiring)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	carbon.SetTestNow(carbon.Now(carbon.UTC).AddHours(2))
+	defer carbon.ClearTestNow()
+
+	_, _, err = store.UserAPIKeyVerify(context.Background(), presentedExpiring)
+
+	if !errors.Is(err, ErrAPIKeyInvalid) {
+		t.Fatal("Expired key MUST NOT be accepted, found:", err)
+	}
+}
+
+func TestStoreUserAPIKeyVerifySuspendedUser(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetStatus(USER_STATUS_ACTIVE)
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	presented, err := store.UserAPIKeyCreate(context.Background(), NewAPIKey().
+		SetUserID(user.ID()).
+		SetName("CI"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	until := carbon.Now(carbon.UTC).AddDays(1).ToDateTimeString(carbon.UTC)
+
+	err = store.UserSuspend(context.Background(), user, until, "Abuse")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, _, err = store.UserAPIKeyVerify(context.Background(), presented)
+
+	if !errors.Is(err, ErrAPIKeyInvalid) {
+		t.Fatal("Error MUST be ErrAPIKeyInvalid for a suspended user, found:", err)
+	}
+
+	err = store.UserSuspensionLift(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, _, err = store.UserAPIKeyVerify(context.Background(), presented)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
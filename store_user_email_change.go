@@ -0,0 +1,200 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/uid"
+	"github.com/samber/lo"
+)
+
+// UserEmailChangeRequest starts changing the email address of the user.
+//
+// The new address is kept pending with a one-time token, which is
+// returned to be sent to the new address. The email of the user is
+// changed only after confirming with UserEmailChangeConfirm. A new
+// request replaces any pending one.
+func (store *store) UserEmailChangeRequest(ctx context.Context, userID string, newEmail string) (string, error) {
+	if userID == "" {
+		return "", errors.New("user id is empty")
+	}
+
+	newEmail = strings.TrimSpace(newEmail)
+
+	if newEmail == "" {
+		return "", errors.New("new email is empty")
+	}
+
+	user, err := store.UserFindByID(ctx, userID)
+
+	if err != nil {
+		return "", err
+	}
+
+	if user == nil {
+		return "", ErrUserNotFound
+	}
+
+	if user.Email() == newEmail {
+		return "", errors.New("new email is the same as the current one")
+	}
+
+	existing, err := store.UserFindByEmail(ctx, newEmail)
+
+	if err != nil {
+		return "", err
+	}
+
+	// the user may change the case of its own email
+	if existing != nil && existing.ID() != userID {
+		return "", ErrEmailTaken
+	}
+
+	var token string
+
+	err = store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		if err := store.tokenDeleteByUserID(txCtx, userID, TOKEN_TYPE_EMAIL_CHANGE); err != nil {
+			return err
+		}
+
+		var err error
+		token, err = store.tokenCreate(txCtx, userID, TOKEN_TYPE_EMAIL_CHANGE, newEmail, store.emailChangeTokenTTL)
+
+		return err
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// UserEmailChangeConfirm changes the email address of the user to the
+// pending one, and invalidates the token. The previous address is kept
+// in the email history.
+//
+// Returns ErrEmailTaken if the address was taken by another user since
+// the request, and ErrTokenInvalid if the token is not found or expired.
+func (store *store) UserEmailChangeConfirm(ctx context.Context, token string) (UserInterface, error) {
+	var user UserInterface
+
+	err := store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		userID, newEmail, err := store.tokenConsume(txCtx, TOKEN_TYPE_EMAIL_CHANGE, token)
+
+		if err != nil {
+			return err
+		}
+
+		existing, err := store.UserFindByEmail(txCtx, newEmail)
+
+		if err != nil {
+			return err
+		}
+
+		if existing != nil && existing.ID() != userID {
+			return ErrEmailTaken
+		}
+
+		user, err = store.UserFindByID(txCtx, userID)
+
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return ErrUserNotFound
+		}
+
+		oldEmail := user.Email()
+
+		if oldEmail != "" {
+			if err := store.emailHistoryCreate(txCtx, userID, oldEmail); err != nil {
+				return err
+			}
+		}
+
+		// the new address is verified by confirming the change
+		user.SetEmail(newEmail).
+			SetVerifiedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+		if err := store.UserUpdate(txCtx, user); err != nil {
+			return err
+		}
+
+		return store.auditCreate(txCtx, userID, AUDIT_ACTION_EMAIL_CHANGE, AUDIT_ENTITY_USER, userID, auditDiff(
+			map[string]string{COLUMN_EMAIL: oldEmail},
+			map[string]string{COLUMN_EMAIL: newEmail},
+		), nil)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// UserEmailHistory returns the previous email addresses of the user,
+// the most recently replaced first
+func (store *store) UserEmailHistory(ctx context.Context, userID string) ([]string, error) {
+	if userID == "" {
+		return nil, errors.New("user id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.emailHistoryTableName).
+		Prepared(true).
+		Select(COLUMN_EMAIL).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Order(goqu.C(COLUMN_CREATED_AT).Desc(), goqu.C(COLUMN_ID).Desc()).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(modelMaps, func(modelMap map[string]string, _ int) string {
+		return modelMap[COLUMN_EMAIL]
+	}), nil
+}
+
+func (store *store) emailHistoryCreate(ctx context.Context, userID string, email string) error {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.emailHistoryTableName).
+		Prepared(true).
+		Rows(map[string]string{
+			COLUMN_ID:         uid.HumanUid(),
+			COLUMN_USER_ID:    userID,
+			COLUMN_EMAIL:      email,
+			COLUMN_CREATED_AT: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		}).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
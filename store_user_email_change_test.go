@@ -0,0 +1,159 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStoreUserEmailChange(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("old@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	token, err := store.UserEmailChangeRequest(context.Background(), user.ID(), "new@test.com")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// not changed until confirmed
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.Email() != "old@test.com" {
+		t.Fatal("Email MUST be old@test.com, found:", userFound.Email())
+	}
+
+	_, err = store.UserEmailChangeConfirm(context.Background(), token)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err = store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.Email() != "new@test.com" {
+		t.Fatal("Email MUST be new@test.com, found:", userFound.Email())
+	}
+
+	history, err := store.UserEmailHistory(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(history) != 1 || history[0] != "old@test.com" {
+		t.Fatal("History MUST contain old@test.com, found:", history)
+	}
+
+	audits, err := store.AuditList(context.Background(), NewAuditQuery().
+		SetAction(AUDIT_ACTION_EMAIL_CHANGE).
+		SetEntityID(user.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(audits) != 1 {
+		t.Fatal("Email change audits MUST be 1, found:", len(audits))
+	}
+
+	diff := audits[0].DiffMap()
+
+	if diff[COLUMN_EMAIL]["before"] != "old@test.com" || diff[COLUMN_EMAIL]["after"] != "new@test.com" {
+		t.Fatal("Diff of email MUST be old@test.com => new@test.com, found:", audits[0].Diff())
+	}
+
+	_, err = store.UserEmailChangeConfirm(context.Background(), token)
+
+	if !errors.Is(err, ErrTokenInvalid) {
+		t.Fatal("Token MUST NOT be accepted twice, found:", err)
+	}
+}
+
+func TestStoreUserEmailChangeTaken(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("user@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = store.UserEmailChangeRequest(context.Background(), user.ID(), "user@test.com")
+
+	if err == nil {
+		t.Fatal("Error MUST be returned for the same email")
+	}
+
+	token, err := store.UserEmailChangeRequest(context.Background(), user.ID(), "new@test.com")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// taken by another user after the request
+	err = store.UserCreate(context.Background(), NewUser().SetEmail("new@test.com"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = store.UserEmailChangeRequest(context.Background(), user.ID(), "new@test.com")
+
+	if !errors.Is(err, ErrEmailTaken) {
+		t.Fatal("Error MUST be ErrEmailTaken on request, found:", err)
+	}
+
+	_, err = store.UserEmailChangeConfirm(context.Background(), token)
+
+	if !errors.Is(err, ErrEmailTaken) {
+		t.Fatal("Error MUST be ErrEmailTaken on confirm, found:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.Email() != "user@test.com" {
+		t.Fatal("Email MUST be user@test.com, found:", userFound.Email())
+	}
+}
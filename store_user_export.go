@@ -0,0 +1,293 @@
+package userstore
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+// PERSONAL_DATA_EXPORT_SCHEMA_VERSION is the version of the schema of
+// PersonalDataExport. It changes when the schema changes incompatibly.
+const PERSONAL_DATA_EXPORT_SCHEMA_VERSION = "1"
+
+// PERSONAL_DATA_EXPORT_FILE_NAME is the name of the JSON file in the ZIP
+const PERSONAL_DATA_EXPORT_FILE_NAME = "personal_data.json"
+
+// PersonalDataExport is the personal data of a user, as exported for
+// a subject access request. It is serialized to JSON with ToJSON, or
+// to a ZIP containing the JSON with ToZip.
+//
+// The datetimes are in UTC. The credentials (i.e. the password, the hashes
+// of the API keys and of the session tokens, the MFA secrets and the public
+// keys of the passkeys) and the session IDs are not exported. The values of the tokenized columns are exported as stored,
+// and are to be detokenized by the application before serializing
+// (i.e. the admin does it with its TokensRead function).
+//
+// The store does not keep consents. They are added to Extra as "consents"
+// by the ConsentsProvider of the store options, if set. The application
+// should add any other personal data it keeps to Extra.
+type PersonalDataExport struct {
+	// SchemaVersion is PERSONAL_DATA_EXPORT_SCHEMA_VERSION
+	SchemaVersion string `json:"schema_version"`
+
+	// ExportedAt is the datetime of the export
+	ExportedAt string `json:"exported_at"`
+
+	// User is the user row as column => value, without the password
+	// and the metas
+	User map[string]string `json:"user"`
+
+	// Metas are the metas of the user as name => value
+	Metas map[string]string `json:"metas"`
+
+	// Roles are the roles of the user, each with "key" and "label"
+	Roles []map[string]string `json:"roles"`
+
+	// Identities are the linked external identities, each with "id",
+	// "provider", "subject", "email", "created_at" and "updated_at"
+	Identities []map[string]string `json:"identities"`
+
+	// Sessions are all the sessions, including the ended ones, each
+	// with "ip_address", "user_agent", "device", "last_seen_at",
+	// "expires_at", "created_at", "updated_at" and "soft_deleted_at"
+	Sessions []map[string]string `json:"sessions"`
+
+	// APIKeys are all the API keys, including the revoked ones, each
+	// with "id", "name", "key_prefix", "scopes", "expires_at",
+	// "last_used_at", "created_at", "updated_at" and "soft_deleted_at"
+	APIKeys []map[string]string `json:"api_keys"`
+
+	// EmailHistory are the previous email addresses, each with "id",
+	// "email" and "created_at"
+	EmailHistory []map[string]string `json:"email_history"`
+
+	// Passkeys are all the passkeys, including the revoked ones, each
+	// with "id", "credential_id", "sign_count", "aaguid", "transports",
+	// "nickname", "last_used_at", "created_at", "updated_at" and
+	// "soft_deleted_at"
+	Passkeys []map[string]string `json:"passkeys"`
+
+	// MFA is the MFA enrollment, if any, with "id", "status",
+	// "last_used_step", "enabled_at", "created_at" and "updated_at"
+	MFA []map[string]string `json:"mfa"`
+
+	// StatusHistory are the status changes, the oldest first, each with
+	// "id", "from_status", "to_status", "reason", "actor_id" and "created_at"
+	StatusHistory []map[string]string `json:"status_history"`
+
+	// Revisions are the kept revisions of the user, the oldest first,
+	// each with "id", "revision", "snapshot" (the user row as JSON,
+	// without the password) and "created_at"
+	Revisions []map[string]string `json:"revisions"`
+
+	// AuditEvents are the audit events of the user, the oldest first,
+	// each with "id", "action", "actor_id", "diff" (column => {"before",
+	// "after"}), "metadata" (name => value) and "created_at"
+	AuditEvents []map[string]any `json:"audit_events"`
+
+	// Extra is the personal data added by the application (i.e. consents)
+	Extra map[string]any `json:"extra,omitempty"`
+}
+
+// ToJSON returns the export as indented JSON
+func (export *PersonalDataExport) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(export, "", "  ")
+}
+
+// ToZip returns a ZIP archive with the export as JSON,
+// in a file named PERSONAL_DATA_EXPORT_FILE_NAME
+func (export *PersonalDataExport) ToZip() ([]byte, error) {
+	exportJson, err := export.ToJSON()
+
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := bytes.Buffer{}
+	archive := zip.NewWriter(&buffer)
+
+	file, err := archive.Create(PERSONAL_DATA_EXPORT_FILE_NAME)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := file.Write(exportJson); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// UserExportPersonalData exports the personal data of the user,
+// including a soft deleted user, for a subject access request.
+// See PersonalDataExport for the schema.
+//
+// Returns ErrUserNotFound if the user does not exist.
+func (store *store) UserExportPersonalData(ctx context.Context, userID string) (*PersonalDataExport, error) {
+	if userID == "" {
+		return nil, errors.New("user id is empty")
+	}
+
+	data, err := store.userDataByID(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, ErrUserNotFound
+	}
+
+	metas, err := NewUserFromExistingData(data).Metas()
+
+	if err != nil {
+		return nil, err
+	}
+
+	role, found := store.roleFind(data[COLUMN_ROLE])
+
+	if !found {
+		role = UserRole{Key: data[COLUMN_ROLE], Label: data[COLUMN_ROLE]}
+	}
+
+	identities, err := store.exportRows(ctx, store.identityTableName, userID, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := store.exportRows(ctx, store.sessionTableName, userID, []string{COLUMN_ID, COLUMN_TOKEN_HASH})
+
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := store.exportRows(ctx, store.apiKeyTableName, userID, []string{COLUMN_KEY_HASH})
+
+	if err != nil {
+		return nil, err
+	}
+
+	emailHistory, err := store.exportRows(ctx, store.emailHistoryTableName, userID, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	passkeys, err := store.exportRows(ctx, store.passkeyTableName, userID, []string{COLUMN_PUBLIC_KEY})
+
+	if err != nil {
+		return nil, err
+	}
+
+	mfa, err := store.exportRows(ctx, store.mfaTableName, userID, []string{COLUMN_SECRET})
+
+	if err != nil {
+		return nil, err
+	}
+
+	statusHistory, err := store.exportRows(ctx, store.statusHistoryTableName, userID, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := store.exportRows(ctx, store.revisionTableName, userID, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	audits, err := store.AuditList(ctx, NewAuditQuery().
+		SetEntity(AUDIT_ENTITY_USER).
+		SetEntityID(userID).
+		SetSortDirection(sb.ASC))
+
+	if err != nil {
+		return nil, err
+	}
+
+	auditEvents := lo.Map(audits, func(audit AuditInterface, _ int) map[string]any {
+		return map[string]any{
+			COLUMN_ID:         audit.ID(),
+			COLUMN_ACTION:     audit.Action(),
+			COLUMN_ACTOR_ID:   audit.ActorID(),
+			COLUMN_DIFF:       audit.DiffMap(),
+			COLUMN_METADATA:   audit.MetadataMap(),
+			COLUMN_CREATED_AT: audit.CreatedAt(),
+		}
+	})
+
+	extra := map[string]any{}
+
+	if store.consentsProvider != nil {
+		consents, err := store.consentsProvider(ctx, userID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		extra["consents"] = consents
+	}
+
+	return &PersonalDataExport{
+		SchemaVersion: PERSONAL_DATA_EXPORT_SCHEMA_VERSION,
+		ExportedAt:    carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		User:          lo.OmitByKeys(data, []string{COLUMN_PASSWORD, COLUMN_METAS}),
+		Metas:         metas,
+		Roles:         []map[string]string{{"key": role.Key, "label": role.Label}},
+		Identities:    identities,
+		Sessions:      sessions,
+		APIKeys:       apiKeys,
+		EmailHistory:  emailHistory,
+		Passkeys:      passkeys,
+		MFA:           mfa,
+		StatusHistory: statusHistory,
+		Revisions:     revisions,
+		AuditEvents:   auditEvents,
+		Extra:         extra,
+	}, nil
+}
+
+// exportRows returns the rows of the table, which belong to the user,
+// the oldest first, without the user ID and the omitted columns
+func (store *store) exportRows(ctx context.Context, tableName string, userID string, omitColumns []string) ([]map[string]string, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(tableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Order(goqu.C(COLUMN_CREATED_AT).Asc(), goqu.C(COLUMN_ID).Asc()).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(modelMaps, func(modelMap map[string]string, _ int) map[string]string {
+		return lo.OmitByKeys(modelMap, append([]string{COLUMN_USER_ID}, omitColumns...))
+	}), nil
+}
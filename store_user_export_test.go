@@ -0,0 +1,342 @@
+package userstore
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStoreUserExportPersonalData(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().
+		SetEmail("test@test.com").
+		SetFirstName("John").
+		SetPassword("secret_password")
+
+	if err := user.SetMeta("newsletter", "yes"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserUpdate(context.Background(), user.SetLastName("Doe")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := store.UserSessionCreate(context.Background(), NewSession().SetUserID(user.ID()).SetIPAddress("127.0.0.1")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := store.UserAPIKeyCreate(context.Background(), NewAPIKey().SetUserID(user.ID()).SetName("CI")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	identity := NewIdentity().
+		SetUserID(user.ID()).
+		SetProvider("google").
+		SetSubject("google_subject")
+
+	if err := store.UserIdentityLink(context.Background(), identity); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	export, err := store.UserExportPersonalData(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if export.SchemaVersion != PERSONAL_DATA_EXPORT_SCHEMA_VERSION {
+		t.Fatal("Schema version MUST be", PERSONAL_DATA_EXPORT_SCHEMA_VERSION, "found:", export.SchemaVersion)
+	}
+
+	if export.User[COLUMN_EMAIL] != "test@test.com" {
+		t.Fatal("User email MUST be test@test.com, found:", export.User[COLUMN_EMAIL])
+	}
+
+	if _, found := export.User[COLUMN_PASSWORD]; found {
+		t.Fatal("User password MUST NOT be exported")
+	}
+
+	if export.Metas["newsletter"] != "yes" {
+		t.Fatal("Meta newsletter MUST be yes, found:", export.Metas["newsletter"])
+	}
+
+	if len(export.Roles) != 1 || export.Roles[0]["key"] != USER_ROLE_USER {
+		t.Fatal("Roles MUST be the user role, found:", export.Roles)
+	}
+
+	if len(export.Identities) != 1 || export.Identities[0][COLUMN_PROVIDER] != "google" {
+		t.Fatal("Identities MUST be the google identity, found:", export.Identities)
+	}
+
+	if len(export.Sessions) != 1 || export.Sessions[0][COLUMN_IP_ADDRESS] != "127.0.0.1" {
+		t.Fatal("Sessions MUST be the created session, found:", export.Sessions)
+	}
+
+	for _, column := range []string{COLUMN_ID, COLUMN_TOKEN_HASH} {
+		if _, found := export.Sessions[0][column]; found {
+			t.Fatal("Session column MUST NOT be exported:", column)
+		}
+	}
+
+	if len(export.APIKeys) != 1 || export.APIKeys[0][COLUMN_NAME] != "CI" {
+		t.Fatal("API keys MUST be the created key, found:", export.APIKeys)
+	}
+
+	if _, found := export.APIKeys[0][COLUMN_KEY_HASH]; found {
+		t.Fatal("API key hash MUST NOT be exported")
+	}
+
+	if len(export.AuditEvents) != 2 {
+		t.Fatal("Audit events MUST be 2, found:", len(export.AuditEvents))
+	}
+
+	if export.AuditEvents[0][COLUMN_ACTION] != AUDIT_ACTION_CREATE {
+		t.Fatal("First audit event MUST be create, found:", export.AuditEvents[0][COLUMN_ACTION])
+	}
+
+	export.Extra = map[string]any{
+		"consents": []string{"marketing"},
+	}
+
+	exportJson, err := export.ToJSON()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	decoded := map[string]any{}
+
+	if err := json.Unmarshal(exportJson, &decoded); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, key := range []string{"schema_version", "exported_at", "user", "metas", "roles", "identities", "sessions", "api_keys", "email_history", "passkeys", "mfa", "status_history", "revisions", "audit_events", "extra"} {
+		if _, found := decoded[key]; !found {
+			t.Fatal("JSON MUST contain", key)
+		}
+	}
+
+	exportZip, err := export.ToZip()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(exportZip), int64(len(exportZip)))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(archive.File) != 1 || archive.File[0].Name != PERSONAL_DATA_EXPORT_FILE_NAME {
+		t.Fatal("ZIP MUST contain", PERSONAL_DATA_EXPORT_FILE_NAME)
+	}
+
+	file, err := archive.File[0].Open()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer file.Close()
+
+	fileJson, err := io.ReadAll(file)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !bytes.Equal(fileJson, exportJson) {
+		t.Fatal("ZIP file MUST contain the JSON export")
+	}
+}
+
+func TestStoreUserExportPersonalDataNotFound(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	_, err = store.UserExportPersonalData(context.Background(), "not_existing")
+
+	if err != ErrUserNotFound {
+		t.Fatal("Error MUST be ErrUserNotFound, found:", err)
+	}
+}
+
+func TestStoreUserExportPersonalDataHistory(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	user := NewUser().
+		SetEmail("old@test.com").
+		SetPassword("secret_password")
+
+	if err := store.UserCreate(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	token, err := store.UserEmailChangeRequest(ctx, user.ID(), "new@test.com")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := store.UserEmailChangeConfirm(ctx, token); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	user, err = store.UserFindByID(ctx, user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserTransitionStatus(ctx, user, USER_STATUS_ACTIVE, "Email verified"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	passkey := NewPasskey().
+		SetUserID(user.ID()).
+		SetCredentialID("CREDENTIAL_ID").
+		SetPublicKey("PUBLIC_KEY").
+		SetNickname("Laptop")
+
+	if err := store.UserPasskeyRegister(ctx, passkey); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, _, err := store.UserMFAEnroll(ctx, user.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	export, err := store.UserExportPersonalData(ctx, user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(export.EmailHistory) != 1 || export.EmailHistory[0][COLUMN_EMAIL] != "old@test.com" {
+		t.Fatal("Email history MUST be old@test.com, found:", export.EmailHistory)
+	}
+
+	if len(export.Passkeys) != 1 || export.Passkeys[0][COLUMN_NICKNAME] != "Laptop" {
+		t.Fatal("Passkeys MUST be the registered passkey, found:", export.Passkeys)
+	}
+
+	if _, found := export.Passkeys[0][COLUMN_PUBLIC_KEY]; found {
+		t.Fatal("Passkey public key MUST NOT be exported")
+	}
+
+	if len(export.MFA) != 1 {
+		t.Fatal("MFA MUST be the enrollment, found:", export.MFA)
+	}
+
+	if _, found := export.MFA[0][COLUMN_SECRET]; found {
+		t.Fatal("MFA secret MUST NOT be exported")
+	}
+
+	if len(export.StatusHistory) != 1 || export.StatusHistory[0][COLUMN_TO_STATUS] != USER_STATUS_ACTIVE {
+		t.Fatal("Status history MUST be the transition to active, found:", export.StatusHistory)
+	}
+
+	if len(export.Revisions) < 1 {
+		t.Fatal("Revisions MUST be exported")
+	}
+
+	for _, revision := range export.Revisions {
+		if strings.Contains(revision[COLUMN_SNAPSHOT], "secret_password") || strings.Contains(revision[COLUMN_SNAPSHOT], `"`+COLUMN_PASSWORD+`"`) {
+			t.Fatal("Revision snapshot MUST NOT contain the password, found:", revision[COLUMN_SNAPSHOT])
+		}
+	}
+
+	if len(export.Extra) != 0 {
+		t.Fatal("Extra MUST be empty without a consents provider, found:", export.Extra)
+	}
+}
+
+func TestStoreUserExportPersonalDataConsents(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		ConsentsProvider: func(ctx context.Context, userID string) (any, error) {
+			return map[string]string{"marketing": "granted", "user_id": userID}, nil
+		},
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	user := NewUser().SetEmail("test@test.com")
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	export, err := store.UserExportPersonalData(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	consents, ok := export.Extra["consents"].(map[string]string)
+
+	if !ok {
+		t.Fatal("Extra MUST contain the consents, found:", export.Extra)
+	}
+
+	if consents["marketing"] != "granted" || consents["user_id"] != user.ID() {
+		t.Fatal("Consents MUST be the ones of the user, found:", consents)
+	}
+}
@@ -0,0 +1,344 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+)
+
+// UserFindByIdentityOrCreateOptions are the claims of the external identity,
+// as received from the identity provider
+type UserFindByIdentityOrCreateOptions struct {
+	// Provider is the name of the identity provider (required)
+	Provider string
+
+	// Subject is the stable ID of the user at the provider (required)
+	Subject string
+
+	// Email is the email reported by the provider (optional)
+	Email string
+
+	// EmailVerified is true, if the provider reports the email as verified
+	EmailVerified bool
+
+	// CreateStatus is the status of the user, if a new user is created
+	CreateStatus string
+}
+
+// UserFindByIdentity returns the user linked to the external identity,
+// or nil if the identity is not linked or the user no longer exists
+func (store *store) UserFindByIdentity(ctx context.Context, provider string, subject string) (UserInterface, error) {
+	if provider == "" {
+		return nil, errors.New("provider is empty")
+	}
+
+	if subject == "" {
+		return nil, errors.New("subject is empty")
+	}
+
+	identity, err := store.identityFind(ctx, provider, subject)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if identity == nil {
+		return nil, nil
+	}
+
+	return store.UserFindByID(ctx, identity.UserID())
+}
+
+// UserFindByIdentityOrCreate returns the user linked to the external identity.
+//
+// If the identity is not linked yet and a user with the same email exists,
+// the identity is linked to that user only if allowed by the IdentityEmailMatch
+// option of the store, otherwise ErrEmailTaken is returned:
+//   - IDENTITY_EMAIL_MATCH_NEVER - never links by email (default)
+//   - IDENTITY_EMAIL_MATCH_VERIFIED - links if both the provider and the user
+//     have the email verified
+//   - IDENTITY_EMAIL_MATCH_ALWAYS - links regardless of the verification
+//
+// If no user is found, a new user is created and the identity linked to it.
+func (store *store) UserFindByIdentityOrCreate(ctx context.Context, options UserFindByIdentityOrCreateOptions) (UserInterface, error) {
+	if options.Provider == "" {
+		return nil, errors.New("provider is empty")
+	}
+
+	if options.Subject == "" {
+		return nil, errors.New("subject is empty")
+	}
+
+	email := strings.TrimSpace(options.Email)
+
+	var found UserInterface
+
+	err := store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		existing, err := store.identityFind(txCtx, options.Provider, options.Subject)
+
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			found, err = store.UserFindByID(txCtx, existing.UserID())
+
+			if err != nil {
+				return err
+			}
+
+			if found == nil {
+				return ErrUserNotFound
+			}
+
+			return nil
+		}
+
+		identity := NewIdentity().
+			SetProvider(options.Provider).
+			SetSubject(options.Subject).
+			SetEmail(email)
+
+		if email != "" {
+			user, err := store.UserFindByEmail(txCtx, email)
+
+			if err != nil {
+				return err
+			}
+
+			if user != nil {
+				if !store.identityEmailMatchAllowed(user, options.EmailVerified) {
+					return ErrEmailTaken
+				}
+
+				identity.SetUserID(user.ID())
+				found = user
+
+				return store.identityInsert(txCtx, identity)
+			}
+		}
+
+		user := NewUser().
+			SetEmail(email).
+			SetStatus(options.CreateStatus)
+
+		if email != "" && options.EmailVerified {
+			user.SetVerifiedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+		}
+
+		if err := store.UserCreate(txCtx, user); err != nil {
+			return err
+		}
+
+		identity.SetUserID(user.ID())
+		found = user
+
+		return store.identityInsert(txCtx, identity)
+	})
+
+	if err == nil {
+		return found, nil
+	}
+
+	if errors.Is(err, ErrEmailTaken) || errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+
+	// the identity may have been linked concurrently,
+	// the unique index rejected the second insert
+	user, errFind := store.UserFindByIdentity(ctx, options.Provider, options.Subject)
+
+	if errFind == nil && user != nil {
+		return user, nil
+	}
+
+	return nil, err
+}
+
+// UserIdentityLink links the external identity to the user.
+//
+// Returns ErrIdentityAlreadyLinked, if the identity is already linked
+// to this or to another user.
+func (store *store) UserIdentityLink(ctx context.Context, identity IdentityInterface) error {
+	if identity == nil {
+		return errors.New("identity is nil")
+	}
+
+	if identity.UserID() == "" {
+		return errors.New("identity user id is empty")
+	}
+
+	if identity.Provider() == "" {
+		return errors.New("identity provider is empty")
+	}
+
+	if identity.Subject() == "" {
+		return errors.New("identity subject is empty")
+	}
+
+	return store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		existing, err := store.identityFind(txCtx, identity.Provider(), identity.Subject())
+
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			return ErrIdentityAlreadyLinked
+		}
+
+		return store.identityInsert(txCtx, identity)
+	})
+}
+
+// UserIdentityList returns the external identities linked to the user,
+// in the order they were linked
+func (store *store) UserIdentityList(ctx context.Context, userID string) ([]IdentityInterface, error) {
+	if userID == "" {
+		return nil, errors.New("user id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.identityTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Order(goqu.C(COLUMN_CREATED_AT).Asc()).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := []IdentityInterface{}
+
+	for _, modelMap := range modelMaps {
+		list = append(list, NewIdentityFromExistingData(modelMap))
+	}
+
+	return list, nil
+}
+
+// UserIdentityUnlink removes the link between the user and the external identity.
+// The identity can then be linked again, to the same or to another user.
+func (store *store) UserIdentityUnlink(ctx context.Context, userID string, provider string, subject string) error {
+	if userID == "" {
+		return errors.New("user id is empty")
+	}
+
+	if provider == "" {
+		return errors.New("provider is empty")
+	}
+
+	if subject == "" {
+		return errors.New("subject is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.identityTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Where(goqu.C(COLUMN_PROVIDER).Eq(provider)).
+		Where(goqu.C(COLUMN_SUBJECT).Eq(subject)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// identityEmailMatchAllowed returns true, if an identity with the same email
+// may be linked to the existing user, according to the IdentityEmailMatch option
+func (store *store) identityEmailMatchAllowed(user UserInterface, emailVerified bool) bool {
+	switch store.identityEmailMatch {
+	case IDENTITY_EMAIL_MATCH_ALWAYS:
+		return true
+	case IDENTITY_EMAIL_MATCH_VERIFIED:
+		userVerified := user.VerifiedAt() != "" && !strings.Contains(user.VerifiedAt(), sb.NULL_DATETIME)
+		return emailVerified && userVerified
+	default:
+		return false
+	}
+}
+
+func (store *store) identityFind(ctx context.Context, provider string, subject string) (IdentityInterface, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.identityTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_PROVIDER).Eq(provider)).
+		Where(goqu.C(COLUMN_SUBJECT).Eq(subject)).
+		Limit(1).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(modelMaps) < 1 {
+		return nil, nil
+	}
+
+	return NewIdentityFromExistingData(modelMaps[0]), nil
+}
+
+func (store *store) identityInsert(ctx context.Context, identity IdentityInterface) error {
+	identity.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	identity.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.identityTableName).
+		Prepared(true).
+		Rows(identity.Data()).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	identity.MarkAsNotDirty()
+
+	return nil
+}
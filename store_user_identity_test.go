@@ -0,0 +1,307 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func initStoreWithIdentityEmailMatch(identityEmailMatch string) (StoreInterface, error) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		IdentityEmailMatch: identityEmailMatch,
+	})
+}
+
+func TestStoreUserIdentityLink(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("test@test.com").SetStatus(USER_STATUS_ACTIVE)
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	identity := NewIdentity().
+		SetUserID(user.ID()).
+		SetProvider("google").
+		SetSubject("SUBJECT_1").
+		SetEmail("test@gmail.com")
+
+	if err := store.UserIdentityLink(context.Background(), identity); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err := store.UserFindByIdentity(context.Background(), "google", "SUBJECT_1")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound == nil {
+		t.Fatal("User MUST NOT be nil")
+	}
+
+	if userFound.ID() != user.ID() {
+		t.Fatal("IDs do not match")
+	}
+
+	// same subject at another provider is another identity
+	userFound, err = store.UserFindByIdentity(context.Background(), "github", "SUBJECT_1")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound != nil {
+		t.Fatal("User MUST be nil")
+	}
+
+	// linking the same identity again, even to another user, is rejected
+	duplicate := NewIdentity().
+		SetUserID("ANOTHER_USER_ID").
+		SetProvider("google").
+		SetSubject("SUBJECT_1")
+
+	err = store.UserIdentityLink(context.Background(), duplicate)
+
+	if !errors.Is(err, ErrIdentityAlreadyLinked) {
+		t.Fatal("Error MUST be ErrIdentityAlreadyLinked, found:", err)
+	}
+}
+
+func TestStoreUserIdentityListAndUnlink(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	carbon.SetTestNow(carbon.Now(carbon.UTC).SubHour())
+
+	err = store.UserIdentityLink(context.Background(), NewIdentity().
+		SetUserID("USER_ID").
+		SetProvider("google").
+		SetSubject("SUBJECT_1"))
+
+	carbon.ClearTestNow()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserIdentityLink(context.Background(), NewIdentity().
+		SetUserID("USER_ID").
+		SetProvider("github").
+		SetSubject("SUBJECT_2"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	list, err := store.UserIdentityList(context.Background(), "USER_ID")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 2 {
+		t.Fatal("Identities MUST be 2, found:", len(list))
+	}
+
+	if list[0].Provider() != "google" || list[1].Provider() != "github" {
+		t.Fatal("Identities MUST be in the order they were linked")
+	}
+
+	// another user cannot unlink the identity
+	err = store.UserIdentityUnlink(context.Background(), "ANOTHER_USER_ID", "google", "SUBJECT_1")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserIdentityUnlink(context.Background(), "USER_ID", "google", "SUBJECT_1")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	list, err = store.UserIdentityList(context.Background(), "USER_ID")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 1 {
+		t.Fatal("Identities MUST be 1, found:", len(list))
+	}
+
+	if list[0].Provider() != "github" {
+		t.Fatal("Identity MUST be github, found:", list[0].Provider())
+	}
+
+	// unlinked identity can be linked again
+	err = store.UserIdentityLink(context.Background(), NewIdentity().
+		SetUserID("ANOTHER_USER_ID").
+		SetProvider("google").
+		SetSubject("SUBJECT_1"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestStoreUserFindByIdentityOrCreate(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	options := UserFindByIdentityOrCreateOptions{
+		Provider:      "google",
+		Subject:       "SUBJECT_1",
+		Email:         "test@test.com",
+		EmailVerified: true,
+		CreateStatus:  USER_STATUS_ACTIVE,
+	}
+
+	user, err := store.UserFindByIdentityOrCreate(context.Background(), options)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if user == nil {
+		t.Fatal("User MUST NOT be nil")
+	}
+
+	if user.Email() != "test@test.com" {
+		t.Fatal("Email MUST be test@test.com, found:", user.Email())
+	}
+
+	if user.Status() != USER_STATUS_ACTIVE {
+		t.Fatal("Status MUST be active, found:", user.Status())
+	}
+
+	if user.VerifiedAtCarbon().Lt(carbon.Now(carbon.UTC).SubMinute()) {
+		t.Fatal("User MUST be verified, found:", user.VerifiedAt())
+	}
+
+	userAgain, err := store.UserFindByIdentityOrCreate(context.Background(), options)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userAgain.ID() != user.ID() {
+		t.Fatal("The same user MUST be returned")
+	}
+
+	count, err := store.UserCount(context.Background(), NewUserQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("Users MUST be 1, found:", count)
+	}
+}
+
+func TestStoreUserFindByIdentityOrCreateEmailMatch(t *testing.T) {
+	tests := []struct {
+		emailMatch         string
+		userVerified       bool
+		emailVerified      bool
+		expectLinkExisting bool
+	}{
+		{IDENTITY_EMAIL_MATCH_NEVER, true, true, false},
+		{IDENTITY_EMAIL_MATCH_VERIFIED, true, true, true},
+		{IDENTITY_EMAIL_MATCH_VERIFIED, false, true, false},
+		{IDENTITY_EMAIL_MATCH_VERIFIED, true, false, false},
+		{IDENTITY_EMAIL_MATCH_ALWAYS, false, false, true},
+	}
+
+	for _, test := range tests {
+		store, err := initStoreWithIdentityEmailMatch(test.emailMatch)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		existing := NewUser().SetEmail("test@test.com").SetStatus(USER_STATUS_ACTIVE)
+
+		if test.userVerified {
+			existing.SetVerifiedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+		}
+
+		if err := store.UserCreate(context.Background(), existing); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		user, err := store.UserFindByIdentityOrCreate(context.Background(), UserFindByIdentityOrCreateOptions{
+			Provider:      "google",
+			Subject:       "SUBJECT_1",
+			Email:         "test@test.com",
+			EmailVerified: test.emailVerified,
+			CreateStatus:  USER_STATUS_ACTIVE,
+		})
+
+		if test.expectLinkExisting {
+			if err != nil {
+				t.Fatal(test.emailMatch, "unexpected error:", err)
+			}
+
+			if user == nil || user.ID() != existing.ID() {
+				t.Fatal(test.emailMatch, "Identity MUST be linked to the existing user")
+			}
+		} else if !errors.Is(err, ErrEmailTaken) {
+			t.Fatal(test.emailMatch, "Error MUST be ErrEmailTaken, found:", err)
+		}
+
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStoreIdentityEmailMatchInvalid(t *testing.T) {
+	_, err := initStoreWithIdentityEmailMatch("sometimes")
+
+	if err == nil {
+		t.Fatal("Error MUST NOT be nil")
+	}
+}
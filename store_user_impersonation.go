@@ -0,0 +1,70 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+)
+
+// UserImpersonationStart checks that the impersonator may impersonate
+// the user and records the start of the impersonation in the audit log.
+// Switching the session to the user is up to the application.
+//
+// Only superusers and administrators may impersonate, and only superusers
+// may impersonate other superusers. Otherwise ErrImpersonationNotAllowed
+// is returned.
+func (store *store) UserImpersonationStart(ctx context.Context, impersonatorID string, userID string) error {
+	if impersonatorID == "" {
+		return errors.New("impersonator id is empty")
+	}
+
+	if userID == "" {
+		return errors.New("user id is empty")
+	}
+
+	if impersonatorID == userID {
+		return ErrImpersonationNotAllowed
+	}
+
+	impersonator, err := store.UserFindByID(ctx, impersonatorID)
+
+	if err != nil {
+		return err
+	}
+
+	if impersonator == nil || !impersonator.IsActive() {
+		return ErrImpersonationNotAllowed
+	}
+
+	if !impersonator.IsSuperuser() && !impersonator.IsAdministrator() {
+		return ErrImpersonationNotAllowed
+	}
+
+	user, err := store.UserFindByID(ctx, userID)
+
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if user.IsSuperuser() && !impersonator.IsSuperuser() {
+		return ErrImpersonationNotAllowed
+	}
+
+	return store.auditCreate(ctx, impersonatorID, AUDIT_ACTION_IMPERSONATION_START, AUDIT_ENTITY_USER, userID, nil, nil)
+}
+
+// UserImpersonationStop records the end of the impersonation in the audit log
+func (store *store) UserImpersonationStop(ctx context.Context, impersonatorID string, userID string) error {
+	if impersonatorID == "" {
+		return errors.New("impersonator id is empty")
+	}
+
+	if userID == "" {
+		return errors.New("user id is empty")
+	}
+
+	return store.auditCreate(ctx, impersonatorID, AUDIT_ACTION_IMPERSONATION_STOP, AUDIT_ENTITY_USER, userID, nil, nil)
+}
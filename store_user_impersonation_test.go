@@ -0,0 +1,105 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+func TestStoreUserImpersonation(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	superuser := NewUser().SetEmail("superuser@test.com").SetStatus(USER_STATUS_ACTIVE).SetRole(USER_ROLE_SUPERUSER)
+	administrator := NewUser().SetEmail("administrator@test.com").SetStatus(USER_STATUS_ACTIVE).SetRole(USER_ROLE_ADMINISTRATOR)
+	manager := NewUser().SetEmail("manager@test.com").SetStatus(USER_STATUS_ACTIVE).SetRole(USER_ROLE_MANAGER)
+	user := NewUser().SetEmail("user@test.com").SetStatus(USER_STATUS_ACTIVE).SetRole(USER_ROLE_USER)
+
+	for _, u := range []UserInterface{superuser, administrator, manager, user} {
+		if err := store.UserCreate(context.Background(), u); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	tests := []struct {
+		impersonator UserInterface
+		user         UserInterface
+		allowed      bool
+	}{
+		{superuser, user, true},
+		{superuser, administrator, true},
+		{administrator, user, true},
+		{administrator, manager, true},
+		{administrator, superuser, false},
+		{administrator, administrator, false},
+		{manager, user, false},
+		{user, manager, false},
+	}
+
+	for _, test := range tests {
+		err := store.UserImpersonationStart(context.Background(), test.impersonator.ID(), test.user.ID())
+
+		if test.allowed && err != nil {
+			t.Fatal(test.impersonator.Role(), "->", test.user.Role(), "unexpected error:", err)
+		}
+
+		if !test.allowed && !errors.Is(err, ErrImpersonationNotAllowed) {
+			t.Fatal(test.impersonator.Role(), "->", test.user.Role(), "Error MUST be ErrImpersonationNotAllowed, found:", err)
+		}
+	}
+
+	err = store.UserImpersonationStart(context.Background(), administrator.ID(), "NOT_FOUND")
+
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatal("Error MUST be ErrUserNotFound, found:", err)
+	}
+
+	if err := store.UserImpersonationStop(context.Background(), administrator.ID(), user.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	rows, err := store.DB().Query("SELECT actor_id, action FROM user_table_audit WHERE entity_id = ? AND action LIKE 'impersonation_%'", user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	actions := []string{}
+
+	for rows.Next() {
+		var actorID, action string
+
+		if err := rows.Scan(&actorID, &action); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if actorID != superuser.ID() && actorID != administrator.ID() {
+			t.Fatal("Actor MUST be the impersonator, found:", actorID)
+		}
+
+		actions = append(actions, action)
+	}
+
+	if len(actions) != 3 {
+		t.Fatal("Audit events MUST be 3, found:", len(actions))
+	}
+
+	if lo.Count(actions, AUDIT_ACTION_IMPERSONATION_STOP) != 1 {
+		t.Fatal("Audit events MUST include one impersonation_stop, found:", actions)
+	}
+}
@@ -0,0 +1,255 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+// LockoutPolicy defines when a user is locked out after failed logins.
+//
+// A user is locked when MaxFailures failed logins happen within Window.
+// The first lock lasts LockDuration, and every consecutive lock doubles
+// it (exponential backoff) up to MaxLockDuration. A successful login
+// resets the backoff.
+type LockoutPolicy struct {
+	MaxFailures     int
+	Window          time.Duration
+	LockDuration    time.Duration
+	MaxLockDuration time.Duration
+}
+
+const LOCKOUT_DEFAULT_MAX_FAILURES = 5
+const LOCKOUT_DEFAULT_WINDOW = 15 * time.Minute
+const LOCKOUT_DEFAULT_LOCK_DURATION = 15 * time.Minute
+const LOCKOUT_DEFAULT_MAX_LOCK_DURATION = 24 * time.Hour
+
+// withDefaults returns a copy of the policy with the zero fields
+// replaced by the defaults
+func (policy LockoutPolicy) withDefaults() LockoutPolicy {
+	if policy.MaxFailures <= 0 {
+		policy.MaxFailures = LOCKOUT_DEFAULT_MAX_FAILURES
+	}
+
+	if policy.Window <= 0 {
+		policy.Window = LOCKOUT_DEFAULT_WINDOW
+	}
+
+	if policy.LockDuration <= 0 {
+		policy.LockDuration = LOCKOUT_DEFAULT_LOCK_DURATION
+	}
+
+	if policy.MaxLockDuration <= 0 {
+		policy.MaxLockDuration = LOCKOUT_DEFAULT_MAX_LOCK_DURATION
+	}
+
+	if policy.MaxLockDuration < policy.LockDuration {
+		policy.MaxLockDuration = policy.LockDuration
+	}
+
+	return policy
+}
+
+// lockDuration returns how long the lock lasts, given the number of
+// locks that preceded it
+func (policy LockoutPolicy) lockDuration(previousLocks int) time.Duration {
+	duration := policy.LockDuration
+
+	for i := 0; i < previousLocks; i++ {
+		duration = duration * 2
+
+		if duration >= policy.MaxLockDuration {
+			return policy.MaxLockDuration
+		}
+	}
+
+	return duration
+}
+
+// UserRecordLoginFailure records a failed login attempt for the user.
+//
+// When the number of failures within the lockout window reaches the
+// threshold of the lockout policy, the user is locked. Attempts made
+// while the user is already locked do not extend the lock.
+//
+// The failure is counted with a single conditional UPDATE, so that
+// concurrent failed logins are not lost, and the user is refreshed
+// with the stored state afterwards.
+func (store *store) UserRecordLoginFailure(ctx context.Context, user UserInterface) error {
+	if user == nil {
+		return errors.New("at user record login failure > user is nil")
+	}
+
+	if user.IsLocked() {
+		return nil
+	}
+
+	now := carbon.Now(carbon.UTC)
+	nowString := now.ToDateTimeString(carbon.UTC)
+	windowStart := now.Copy().SubDuration(store.lockoutPolicy.Window.String()).ToDateTimeString(carbon.UTC)
+
+	// both cases depend on the first failure time only, as MySQL evaluates
+	// the assignments left to right, seeing the already assigned values
+	windowExpired := goqu.C(COLUMN_FAILED_LOGIN_FIRST_AT).Lt(windowStart)
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.userTableName).
+		Prepared(true).
+		Set(goqu.Record{
+			COLUMN_FAILED_LOGIN_COUNT: goqu.Case().
+				When(windowExpired, 1).
+				Else(goqu.L("? + 1", goqu.C(COLUMN_FAILED_LOGIN_COUNT))),
+			COLUMN_FAILED_LOGIN_FIRST_AT: goqu.Case().
+				When(windowExpired, nowString).
+				Else(goqu.C(COLUMN_FAILED_LOGIN_FIRST_AT)),
+			COLUMN_UPDATED_AT: nowString,
+		}).
+		Where(
+			goqu.C(COLUMN_ID).Eq(user.ID()),
+			goqu.C(COLUMN_LOCKED_UNTIL).Lte(nowString),
+		).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	return store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		if _, err := database.Execute(txCtx, sqlStr, params...); err != nil {
+			return err
+		}
+
+		data, err := store.userDataByID(txCtx, user.ID())
+
+		if err != nil {
+			return err
+		}
+
+		if data == nil {
+			return ErrUserNotFound
+		}
+
+		for column, value := range data {
+			user.Set(column, value)
+		}
+
+		user.MarkAsNotDirty()
+
+		if user.IsLocked() || user.FailedLoginCount() < store.lockoutPolicy.MaxFailures {
+			return nil
+		}
+
+		// the row stays locked by the update above until the transaction
+		// ends, so the concurrent failures can not lock the user twice
+		lockDuration := store.lockoutPolicy.lockDuration(user.LockoutCount())
+
+		user.SetLockedUntil(now.Copy().AddDuration(lockDuration.String()).ToDateTimeString(carbon.UTC))
+		user.SetLockoutCount(user.LockoutCount() + 1)
+		user.SetFailedLoginCount(0)
+		user.SetFailedLoginFirstAt(sb.NULL_DATETIME)
+
+		if err := store.userLoginStateWrite(txCtx, user, true); err != nil {
+			return err
+		}
+
+		user.MarkAsNotDirty()
+
+		return nil
+	})
+}
+
+// UserRecordLoginSuccess records a successful login for the user,
+// clearing the failed login count and the lockout backoff
+func (store *store) UserRecordLoginSuccess(ctx context.Context, user UserInterface) error {
+	if user == nil {
+		return errors.New("at user record login success > user is nil")
+	}
+
+	return store.userLoginStateReset(ctx, user, false)
+}
+
+// UserUnlock lifts the lock of a locked out user and clears
+// the failed login count and the lockout backoff
+func (store *store) UserUnlock(ctx context.Context, user UserInterface) error {
+	if user == nil {
+		return errors.New("at user unlock > user is nil")
+	}
+
+	return store.userLoginStateReset(ctx, user, true)
+}
+
+func (store *store) userLoginStateReset(ctx context.Context, user UserInterface, audited bool) error {
+	user.SetFailedLoginCount(0)
+	user.SetFailedLoginFirstAt(sb.NULL_DATETIME)
+	user.SetLockoutCount(0)
+	user.SetLockedUntil(sb.NULL_DATETIME)
+
+	return store.userLoginStateWrite(ctx, user, audited)
+}
+
+// userLoginStateWrite writes the failed login and lockout columns of the
+// user with a direct UPDATE, not with UserUpdate, so the logins create
+// no revisions, outbox events nor webhook deliveries. The locks and the
+// unlocks are audited.
+func (store *store) userLoginStateWrite(ctx context.Context, user UserInterface, audited bool) error {
+	columns := []string{
+		COLUMN_FAILED_LOGIN_COUNT,
+		COLUMN_FAILED_LOGIN_FIRST_AT,
+		COLUMN_LOCKOUT_COUNT,
+		COLUMN_LOCKED_UNTIL,
+	}
+
+	after := lo.PickByKeys(user.Data(), columns)
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.userTableName).
+		Prepared(true).
+		Set(lo.Assign(after, map[string]string{
+			COLUMN_UPDATED_AT: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		})).
+		Where(goqu.C(COLUMN_ID).Eq(user.ID())).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	return store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		before, err := store.userDataByID(txCtx, user.ID())
+
+		if err != nil {
+			return err
+		}
+
+		if before == nil {
+			return ErrUserNotFound
+		}
+
+		if _, err := database.Execute(txCtx, sqlStr, params...); err != nil {
+			return err
+		}
+
+		diff := auditDiff(before, after)
+
+		if !audited || len(diff) < 1 {
+			return nil
+		}
+
+		return store.auditCreate(txCtx, "", AUDIT_ACTION_UPDATE, AUDIT_ENTITY_USER, user.ID(), diff, nil)
+	})
+}
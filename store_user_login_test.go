@@ -0,0 +1,430 @@
+package userstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+func initStoreWithLockoutPolicy(policy LockoutPolicy) (StoreInterface, error) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		LockoutPolicy:      policy,
+	})
+}
+
+func TestStoreUserRecordLoginFailure(t *testing.T) {
+	store, err := initStoreWithLockoutPolicy(LockoutPolicy{
+		MaxFailures:     3,
+		Window:          10 * time.Minute,
+		LockDuration:    5 * time.Minute,
+		MaxLockDuration: 15 * time.Minute,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+		carbon.ClearTestNow()
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	now := carbon.Parse("2025-01-01 10:00:00", carbon.UTC)
+	carbon.SetTestNow(now)
+
+	for i := 0; i < 2; i++ {
+		if err := store.UserRecordLoginFailure(context.Background(), user); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if user.IsLocked() {
+		t.Fatal("User MUST NOT be locked before reaching the threshold")
+	}
+
+	if user.FailedLoginCount() != 2 {
+		t.Fatal("Failed login count MUST be 2, found:", user.FailedLoginCount())
+	}
+
+	if err := store.UserRecordLoginFailure(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !user.IsLocked() {
+		t.Fatal("User MUST be locked after reaching the threshold")
+	}
+
+	if user.LockedUntil() != "2025-01-01 10:05:00" {
+		t.Fatal("User MUST be locked for 5 minutes, found:", user.LockedUntil())
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound == nil {
+		t.Fatal("User MUST NOT be nil")
+	}
+
+	if !userFound.IsLocked() {
+		t.Fatal("User MUST be locked in the database")
+	}
+
+	// the second lock doubles the lock duration
+	carbon.SetTestNow(carbon.Parse("2025-01-01 10:06:00", carbon.UTC))
+
+	if user.IsLocked() {
+		t.Fatal("User MUST NOT be locked after the lock expired")
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := store.UserRecordLoginFailure(context.Background(), user); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if user.LockedUntil() != "2025-01-01 10:16:00" {
+		t.Fatal("User MUST be locked for 10 minutes, found:", user.LockedUntil())
+	}
+
+	// the third lock is capped at the max lock duration
+	carbon.SetTestNow(carbon.Parse("2025-01-01 10:20:00", carbon.UTC))
+
+	for i := 0; i < 3; i++ {
+		if err := store.UserRecordLoginFailure(context.Background(), user); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if user.LockedUntil() != "2025-01-01 10:35:00" {
+		t.Fatal("User MUST be locked for 15 minutes, found:", user.LockedUntil())
+	}
+}
+
+func TestStoreUserRecordLoginFailureOutsideWindow(t *testing.T) {
+	store, err := initStoreWithLockoutPolicy(LockoutPolicy{
+		MaxFailures: 3,
+		Window:      10 * time.Minute,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+		carbon.ClearTestNow()
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	carbon.SetTestNow(carbon.Parse("2025-01-01 10:00:00", carbon.UTC))
+
+	for i := 0; i < 2; i++ {
+		if err := store.UserRecordLoginFailure(context.Background(), user); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	carbon.SetTestNow(carbon.Parse("2025-01-01 10:11:00", carbon.UTC))
+
+	if err := store.UserRecordLoginFailure(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if user.IsLocked() {
+		t.Fatal("User MUST NOT be locked, as the earlier failures are outside the window")
+	}
+
+	if user.FailedLoginCount() != 1 {
+		t.Fatal("Failed login count MUST be 1, found:", user.FailedLoginCount())
+	}
+}
+
+func TestStoreUserRecordLoginFailureStaleUser(t *testing.T) {
+	store, err := initStoreWithLockoutPolicy(LockoutPolicy{
+		MaxFailures: 3,
+		Window:      10 * time.Minute,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// each of the concurrent logins has its own copy of the user
+	users := []UserInterface{}
+
+	for i := 0; i < 3; i++ {
+		userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		users = append(users, userFound)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := store.UserRecordLoginFailure(context.Background(), users[i]); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if users[1].FailedLoginCount() != 2 {
+		t.Fatal("FailedLoginCount MUST be 2, found:", users[1].FailedLoginCount())
+	}
+
+	if err := store.UserRecordLoginFailure(context.Background(), users[2]); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !userFound.IsLocked() {
+		t.Fatal("User MUST be locked after reaching the threshold")
+	}
+}
+
+func TestStoreUserRecordLoginSuccess(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := store.UserRecordLoginFailure(context.Background(), user); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	err = store.UserRecordLoginSuccess(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.FailedLoginCount() != 0 {
+		t.Fatal("Failed login count MUST be reset, found:", userFound.FailedLoginCount())
+	}
+
+	if !strings.Contains(userFound.FailedLoginFirstAt(), sb.NULL_DATETIME) {
+		t.Fatal("Failed login first at MUST be reset, found:", userFound.FailedLoginFirstAt())
+	}
+}
+
+func TestStoreUserUnlock(t *testing.T) {
+	store, err := initStoreWithLockoutPolicy(LockoutPolicy{
+		MaxFailures: 1,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserRecordLoginFailure(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !user.IsLocked() {
+		t.Fatal("User MUST be locked")
+	}
+
+	err = store.UserUnlock(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.IsLocked() {
+		t.Fatal("User MUST NOT be locked after unlock")
+	}
+
+	if userFound.LockoutCount() != 0 {
+		t.Fatal("Lockout count MUST be reset, found:", userFound.LockoutCount())
+	}
+}
+
+func TestStoreUserLoginStateNotPublished(t *testing.T) {
+	store, err := initStoreWithLockoutPolicy(LockoutPolicy{MaxFailures: 2})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	user := NewUser().SetEmail("test@test.com").SetStatus(USER_STATUS_ACTIVE)
+
+	if err := store.UserCreate(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	events, err := store.OutboxFetch(ctx, 10)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.OutboxAck(ctx, lo.Map(events, func(event OutboxEventInterface, _ int) string {
+		return event.ID()
+	})); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserRecordLoginFailure(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserRecordLoginSuccess(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := store.UserRecordLoginFailure(ctx, user); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if !user.IsLocked() {
+		t.Fatal("User MUST be locked")
+	}
+
+	events, err = store.OutboxFetch(ctx, 10)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(events) != 0 {
+		t.Fatal("Login state changes MUST NOT be published, found:", len(events))
+	}
+
+	revisions, err := store.UserRevisions(ctx, user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(revisions) != 1 {
+		t.Fatal("Login state changes MUST NOT create revisions, found:", len(revisions))
+	}
+
+	audits, err := store.AuditList(ctx, NewAuditQuery().
+		SetEntity(AUDIT_ENTITY_USER).
+		SetEntityID(user.ID()).
+		SetAction(AUDIT_ACTION_UPDATE))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// only the lock is audited
+	if len(audits) != 1 {
+		t.Fatal("Audits MUST be 1, found:", len(audits))
+	}
+
+	if _, found := audits[0].DiffMap()[COLUMN_LOCKED_UNTIL]; !found {
+		t.Fatal("Audit MUST record the lock, found:", audits[0].DiffMap())
+	}
+}
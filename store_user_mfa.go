@@ -0,0 +1,341 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/samber/lo"
+)
+
+// UserMFAEnroll starts the TOTP enrollment of the user.
+//
+// Returns the generated secret and the otpauth:// provisioning URI
+// to be shown to the user (usually as a QR code). The enrollment
+// stays pending until confirmed with UserMFAConfirm. Enrolling again
+// before confirming replaces the pending secret.
+func (store *store) UserMFAEnroll(ctx context.Context, userID string) (secret string, provisioningURI string, err error) {
+	if userID == "" {
+		return "", "", errors.New("user id is empty")
+	}
+
+	if store.encryptionKey == "" {
+		return "", "", errors.New("userstore: encryption key is required for MFA")
+	}
+
+	user, err := store.UserFindByID(ctx, userID)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if user == nil {
+		return "", "", ErrUserNotFound
+	}
+
+	existing, err := store.mfaFindByUserID(ctx, userID)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if existing != nil && existing.IsEnabled() {
+		return "", "", ErrMfaAlreadyEnabled
+	}
+
+	secret, err = totpSecretGenerate()
+
+	if err != nil {
+		return "", "", err
+	}
+
+	secretEncrypted, err := encryptString(secret, store.encryptionKey)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if existing != nil {
+		existing.SetSecret(secretEncrypted).
+			SetLastUsedStep(0)
+
+		err = store.mfaUpdate(ctx, existing)
+	} else {
+		err = store.mfaCreate(ctx, NewMfa().
+			SetUserID(userID).
+			SetSecret(secretEncrypted))
+	}
+
+	if err != nil {
+		return "", "", err
+	}
+
+	accountName := lo.Ternary(user.Email() != "", user.Email(), user.ID())
+
+	return secret, totpProvisioningURI(store.mfaIssuer, accountName, secret), nil
+}
+
+// UserMFAConfirm confirms the pending enrollment of the user with
+// a code from the authenticator app, and enables MFA
+func (store *store) UserMFAConfirm(ctx context.Context, userID string, code string) error {
+	if userID == "" {
+		return errors.New("user id is empty")
+	}
+
+	mfa, err := store.mfaFindByUserID(ctx, userID)
+
+	if err != nil {
+		return err
+	}
+
+	if mfa == nil {
+		return ErrMfaNotEnrolled
+	}
+
+	if mfa.IsEnabled() {
+		return ErrMfaAlreadyEnabled
+	}
+
+	step, matched, err := store.mfaCodeMatch(mfa, code)
+
+	if err != nil {
+		return err
+	}
+
+	if !matched {
+		return ErrMfaCodeInvalid
+	}
+
+	mfa.SetStatus(MFA_STATUS_ENABLED).
+		SetEnabledAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetLastUsedStep(step)
+
+	return store.mfaUpdate(ctx, mfa)
+}
+
+// UserMFADisable disables MFA for the user, and removes the secret
+// and the recovery codes
+func (store *store) UserMFADisable(ctx context.Context, userID string) error {
+	if userID == "" {
+		return errors.New("user id is empty")
+	}
+
+	return store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+			Delete(store.mfaTableName).
+			Prepared(true).
+			Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+			ToSQL()
+
+		if errSql != nil {
+			return errSql
+		}
+
+		if store.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		if _, err := database.Execute(txCtx, sqlStr, params...); err != nil {
+			return err
+		}
+
+		return store.recoveryCodesDeleteByUserID(txCtx, userID)
+	})
+}
+
+// UserMFAIsEnabled returns true if the user has confirmed MFA enrollment
+func (store *store) UserMFAIsEnabled(ctx context.Context, userID string) (bool, error) {
+	if userID == "" {
+		return false, errors.New("user id is empty")
+	}
+
+	mfa, err := store.mfaFindByUserID(ctx, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	return mfa != nil && mfa.IsEnabled(), nil
+}
+
+// UserMFAVerify verifies a code from the authenticator app of the user.
+//
+// A code is accepted within the configured drift window, and only once.
+// Codes for the same or an earlier step than the last accepted one are
+// rejected, which prevents replaying an intercepted code.
+func (store *store) UserMFAVerify(ctx context.Context, userID string, code string) (bool, error) {
+	if userID == "" {
+		return false, errors.New("user id is empty")
+	}
+
+	mfa, err := store.mfaFindByUserID(ctx, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if mfa == nil || !mfa.IsEnabled() {
+		return false, ErrMfaNotEnabled
+	}
+
+	step, matched, err := store.mfaCodeMatch(mfa, code)
+
+	if err != nil {
+		return false, err
+	}
+
+	if !matched || step <= mfa.LastUsedStep() {
+		return false, nil
+	}
+
+	// the step is only moved forward, so concurrent use
+	// of the same code can succeed only once
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.mfaTableName).
+		Prepared(true).
+		Set(map[string]any{
+			COLUMN_LAST_USED_STEP: step,
+			COLUMN_UPDATED_AT:     carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		}).
+		Where(goqu.C(COLUMN_ID).Eq(mfa.ID())).
+		Where(goqu.C(COLUMN_LAST_USED_STEP).Lt(step)).
+		ToSQL()
+
+	if errSql != nil {
+		return false, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (store *store) mfaCodeMatch(mfa MfaInterface, code string) (step int64, matched bool, err error) {
+	secret, err := decryptString(mfa.Secret(), store.encryptionKey)
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	return totpMatch(secret, code, carbon.Now(carbon.UTC).StdTime(), store.mfaDriftSteps)
+}
+
+func (store *store) mfaCreate(ctx context.Context, mfa MfaInterface) error {
+	if mfa == nil {
+		return errors.New("mfa is nil")
+	}
+
+	mfa.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	mfa.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.mfaTableName).
+		Prepared(true).
+		Rows(mfa.Data()).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	mfa.MarkAsNotDirty()
+
+	return nil
+}
+
+func (store *store) mfaFindByUserID(ctx context.Context, userID string) (MfaInterface, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.mfaTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Limit(1).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(modelMaps) < 1 {
+		return nil, nil
+	}
+
+	return NewMfaFromExistingData(modelMaps[0]), nil
+}
+
+func (store *store) mfaUpdate(ctx context.Context, mfa MfaInterface) error {
+	if mfa == nil {
+		return errors.New("mfa is nil")
+	}
+
+	mfa.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	dataChanged := mfa.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	if len(dataChanged) < 1 {
+		return nil
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.mfaTableName).
+		Prepared(true).
+		Set(dataChanged).
+		Where(goqu.C(COLUMN_ID).Eq(mfa.ID())).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	mfa.MarkAsNotDirty()
+
+	return nil
+}
@@ -0,0 +1,269 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func TestTotpCode(t *testing.T) {
+	// RFC 6238 test vector for SHA1, at 59 seconds (94287082 with 8 digits)
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	code, err := totpCode(secret, 59/totpPeriod)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if code != "287082" {
+		t.Fatal("Code MUST be 287082, found:", code)
+	}
+}
+
+func TestStoreUserMFAEnroll(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	secret, uri, err := store.UserMFAEnroll(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if secret == "" {
+		t.Fatal("Secret MUST NOT be empty")
+	}
+
+	if !strings.HasPrefix(uri, "otpauth://totp/UserStore:test@test.com?") {
+		t.Fatal("Provisioning URI is not valid:", uri)
+	}
+
+	if !strings.Contains(uri, "secret="+secret) {
+		t.Fatal("Provisioning URI MUST contain the secret:", uri)
+	}
+
+	var statusStored, secretStored string
+
+	err = store.DB().
+		QueryRow("SELECT status, secret FROM user_table_mfa WHERE user_id = ?", user.ID()).
+		Scan(&statusStored, &secretStored)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if statusStored != MFA_STATUS_PENDING {
+		t.Fatal("MFA MUST be pending, found:", statusStored)
+	}
+
+	if secretStored == secret || strings.Contains(secretStored, secret) {
+		t.Fatal("Secret MUST be encrypted at rest")
+	}
+
+	enabled, err := store.UserMFAIsEnabled(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if enabled {
+		t.Fatal("MFA MUST NOT be enabled before confirmation")
+	}
+
+	_, _, err = store.UserMFAEnroll(context.Background(), "not_existing")
+
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatal("Error MUST be ErrUserNotFound, found:", err)
+	}
+}
+
+func TestStoreUserMFAConfirmAndVerify(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+		carbon.ClearTestNow()
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	carbon.SetTestNow(carbon.Parse("2025-01-01 10:00:00", carbon.UTC))
+
+	secret, _, err := store.UserMFAEnroll(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = store.UserMFAVerify(context.Background(), user.ID(), "000000")
+
+	if !errors.Is(err, ErrMfaNotEnabled) {
+		t.Fatal("Error MUST be ErrMfaNotEnabled, found:", err)
+	}
+
+	step := totpStep(carbon.Now(carbon.UTC).StdTime())
+
+	wrongCode, _ := totpCode(secret, step+5)
+
+	err = store.UserMFAConfirm(context.Background(), user.ID(), wrongCode)
+
+	if !errors.Is(err, ErrMfaCodeInvalid) {
+		t.Fatal("Error MUST be ErrMfaCodeInvalid, found:", err)
+	}
+
+	code, _ := totpCode(secret, step)
+
+	err = store.UserMFAConfirm(context.Background(), user.ID(), code)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	enabled, err := store.UserMFAIsEnabled(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !enabled {
+		t.Fatal("MFA MUST be enabled after confirmation")
+	}
+
+	// the code used for confirmation cannot be replayed
+	valid, err := store.UserMFAVerify(context.Background(), user.ID(), code)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if valid {
+		t.Fatal("Code MUST NOT be accepted twice")
+	}
+
+	// the next code is accepted within the drift window
+	nextCode, _ := totpCode(secret, step+1)
+
+	valid, err = store.UserMFAVerify(context.Background(), user.ID(), nextCode)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !valid {
+		t.Fatal("Code within the drift window MUST be accepted")
+	}
+
+	valid, err = store.UserMFAVerify(context.Background(), user.ID(), nextCode)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if valid {
+		t.Fatal("Code MUST NOT be replayed")
+	}
+
+	// codes outside the drift window are rejected
+	farCode, _ := totpCode(secret, step+3)
+
+	valid, err = store.UserMFAVerify(context.Background(), user.ID(), farCode)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if valid {
+		t.Fatal("Code outside the drift window MUST NOT be accepted")
+	}
+}
+
+func TestStoreUserMFADisable(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	secret, _, err := store.UserMFAEnroll(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	code, _ := totpCode(secret, totpStep(carbon.Now(carbon.UTC).StdTime()))
+
+	err = store.UserMFAConfirm(context.Background(), user.ID(), code)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, _, err = store.UserMFAEnroll(context.Background(), user.ID())
+
+	if !errors.Is(err, ErrMfaAlreadyEnabled) {
+		t.Fatal("Error MUST be ErrMfaAlreadyEnabled, found:", err)
+	}
+
+	err = store.UserMFADisable(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	enabled, err := store.UserMFAIsEnabled(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if enabled {
+		t.Fatal("MFA MUST NOT be enabled after disabling")
+	}
+}
@@ -0,0 +1,262 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+)
+
+// UserPasskeyFindByCredentialID returns the passkey with the specified
+// credential ID, or nil if not found or revoked
+func (store *store) UserPasskeyFindByCredentialID(ctx context.Context, credentialID string) (PasskeyInterface, error) {
+	if credentialID == "" {
+		return nil, errors.New("credential id is empty")
+	}
+
+	passkey, err := store.passkeyFindByCredentialID(ctx, credentialID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if passkey == nil || passkey.IsRevoked() {
+		return nil, nil
+	}
+
+	return passkey, nil
+}
+
+// UserPasskeyList returns the passkeys of the user, which are not revoked,
+// in the order they were registered
+func (store *store) UserPasskeyList(ctx context.Context, userID string) ([]PasskeyInterface, error) {
+	if userID == "" {
+		return nil, errors.New("user id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.passkeyTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))).
+		Order(goqu.C(COLUMN_CREATED_AT).Asc()).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := []PasskeyInterface{}
+
+	for _, modelMap := range modelMaps {
+		list = append(list, NewPasskeyFromExistingData(modelMap))
+	}
+
+	return list, nil
+}
+
+// UserPasskeyRegister stores a new passkey credential of the user
+func (store *store) UserPasskeyRegister(ctx context.Context, passkey PasskeyInterface) error {
+	if passkey == nil {
+		return errors.New("passkey is nil")
+	}
+
+	if passkey.UserID() == "" {
+		return errors.New("passkey user id is empty")
+	}
+
+	if passkey.CredentialID() == "" {
+		return errors.New("passkey credential id is empty")
+	}
+
+	if passkey.PublicKey() == "" {
+		return errors.New("passkey public key is empty")
+	}
+
+	// revoked passkeys are kept, so they count too
+	existing, err := store.passkeyFindByCredentialID(ctx, passkey.CredentialID())
+
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		return ErrPasskeyAlreadyRegistered
+	}
+
+	passkey.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	passkey.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.passkeyTableName).
+		Prepared(true).
+		Rows(passkey.Data()).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err = database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	passkey.MarkAsNotDirty()
+
+	return nil
+}
+
+// UserPasskeyRevoke revokes the passkey with the specified ID,
+// so it can no longer be used for logging in
+func (store *store) UserPasskeyRevoke(ctx context.Context, passkeyID string) error {
+	if passkeyID == "" {
+		return errors.New("passkey id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.passkeyTableName).
+		Prepared(true).
+		Set(map[string]any{
+			COLUMN_SOFT_DELETED_AT: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+			COLUMN_UPDATED_AT:      carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		}).
+		Where(goqu.C(COLUMN_ID).Eq(passkeyID)).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// UserPasskeyUpdateSignCount stores the sign count reported by the
+// authenticator after a successful login, and the time of the use.
+//
+// The sign count must increase with every use. If it does not, the
+// authenticator may have been cloned, and ErrPasskeyCloneDetected is
+// returned without updating the passkey. Authenticators which do not
+// support counters always report 0, which is accepted.
+func (store *store) UserPasskeyUpdateSignCount(ctx context.Context, credentialID string, signCount int64) error {
+	if credentialID == "" {
+		return errors.New("credential id is empty")
+	}
+
+	if signCount < 0 {
+		return errors.New(ERROR_NEGATIVE_NUMBER)
+	}
+
+	// the condition is checked in the update itself, so concurrent
+	// logins with the same sign count can succeed only once
+	signCountValid := goqu.C(COLUMN_SIGN_COUNT).Lt(signCount)
+
+	if signCount == 0 {
+		signCountValid = goqu.C(COLUMN_SIGN_COUNT).Eq(0)
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.passkeyTableName).
+		Prepared(true).
+		Set(map[string]any{
+			COLUMN_SIGN_COUNT:   signCount,
+			COLUMN_LAST_USED_AT: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+			COLUMN_UPDATED_AT:   carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		}).
+		Where(goqu.C(COLUMN_CREDENTIAL_ID).Eq(credentialID)).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))).
+		Where(signCountValid).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected > 0 {
+		return nil
+	}
+
+	passkey, err := store.UserPasskeyFindByCredentialID(ctx, credentialID)
+
+	if err != nil {
+		return err
+	}
+
+	if passkey == nil {
+		return ErrPasskeyNotFound
+	}
+
+	return ErrPasskeyCloneDetected
+}
+
+// passkeyFindByCredentialID returns the passkey with the specified
+// credential ID, including the revoked ones
+func (store *store) passkeyFindByCredentialID(ctx context.Context, credentialID string) (PasskeyInterface, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.passkeyTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_CREDENTIAL_ID).Eq(credentialID)).
+		Limit(1).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(modelMaps) < 1 {
+		return nil, nil
+	}
+
+	return NewPasskeyFromExistingData(modelMaps[0]), nil
+}
@@ -0,0 +1,206 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStoreUserPasskeyRegister(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	passkey := NewPasskey().
+		SetUserID("USER_ID").
+		SetCredentialID("CREDENTIAL_ID").
+		SetPublicKey("PUBLIC_KEY").
+		SetAAGUID("adce0002-35bc-c60a-648b-0b25f1f05503").
+		SetTransports([]string{"internal", "hybrid"}).
+		SetNickname("Laptop")
+
+	err = store.UserPasskeyRegister(context.Background(), passkey)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	passkeyFound, err := store.UserPasskeyFindByCredentialID(context.Background(), "CREDENTIAL_ID")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if passkeyFound == nil {
+		t.Fatal("Passkey MUST NOT be nil")
+	}
+
+	if passkeyFound.ID() != passkey.ID() {
+		t.Fatal("IDs do not match")
+	}
+
+	if passkeyFound.PublicKey() != "PUBLIC_KEY" {
+		t.Fatal("Public key MUST be PUBLIC_KEY, found:", passkeyFound.PublicKey())
+	}
+
+	if len(passkeyFound.Transports()) != 2 || passkeyFound.Transports()[1] != "hybrid" {
+		t.Fatal("Transports MUST be internal and hybrid, found:", passkeyFound.Transports())
+	}
+
+	if passkeyFound.IsRevoked() {
+		t.Fatal("Passkey MUST NOT be revoked")
+	}
+
+	err = store.UserPasskeyRegister(context.Background(), NewPasskey().
+		SetUserID("USER_ID_2").
+		SetCredentialID("CREDENTIAL_ID").
+		SetPublicKey("PUBLIC_KEY"))
+
+	if !errors.Is(err, ErrPasskeyAlreadyRegistered) {
+		t.Fatal("Error MUST be ErrPasskeyAlreadyRegistered, found:", err)
+	}
+}
+
+func TestStoreUserPasskeyListAndRevoke(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for _, credentialID := range []string{"CREDENTIAL_ID_1", "CREDENTIAL_ID_2"} {
+		err = store.UserPasskeyRegister(context.Background(), NewPasskey().
+			SetUserID("USER_ID").
+			SetCredentialID(credentialID).
+			SetPublicKey("PUBLIC_KEY"))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	list, err := store.UserPasskeyList(context.Background(), "USER_ID")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 2 {
+		t.Fatal("Passkeys MUST be 2, found:", len(list))
+	}
+
+	err = store.UserPasskeyRevoke(context.Background(), list[0].ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	list, err = store.UserPasskeyList(context.Background(), "USER_ID")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 1 {
+		t.Fatal("Passkeys MUST be 1, found:", len(list))
+	}
+
+	passkeyFound, err := store.UserPasskeyFindByCredentialID(context.Background(), "CREDENTIAL_ID_1")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if passkeyFound != nil {
+		t.Fatal("Revoked passkey MUST be nil")
+	}
+}
+
+func TestStoreUserPasskeyUpdateSignCount(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	err = store.UserPasskeyRegister(context.Background(), NewPasskey().
+		SetUserID("USER_ID").
+		SetCredentialID("CREDENTIAL_ID").
+		SetPublicKey("PUBLIC_KEY").
+		SetSignCount(5))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserPasskeyUpdateSignCount(context.Background(), "CREDENTIAL_ID", 6)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	passkeyFound, err := store.UserPasskeyFindByCredentialID(context.Background(), "CREDENTIAL_ID")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if passkeyFound.SignCount() != 6 {
+		t.Fatal("Sign count MUST be 6, found:", passkeyFound.SignCount())
+	}
+
+	if passkeyFound.LastUsedAtCarbon().Year() < 2000 {
+		t.Fatal("Last used at MUST be set, found:", passkeyFound.LastUsedAt())
+	}
+
+	// the same or a lower count means a possibly cloned authenticator
+	for _, signCount := range []int64{6, 3, 0} {
+		err = store.UserPasskeyUpdateSignCount(context.Background(), "CREDENTIAL_ID", signCount)
+
+		if !errors.Is(err, ErrPasskeyCloneDetected) {
+			t.Fatal("Error MUST be ErrPasskeyCloneDetected for", signCount, "found:", err)
+		}
+	}
+
+	err = store.UserPasskeyUpdateSignCount(context.Background(), "CREDENTIAL_ID_UNKNOWN", 1)
+
+	if !errors.Is(err, ErrPasskeyNotFound) {
+		t.Fatal("Error MUST be ErrPasskeyNotFound, found:", err)
+	}
+
+	// authenticators without a counter always report 0
+	err = store.UserPasskeyRegister(context.Background(), NewPasskey().
+		SetUserID("USER_ID").
+		SetCredentialID("CREDENTIAL_ID_NO_COUNTER").
+		SetPublicKey("PUBLIC_KEY"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserPasskeyUpdateSignCount(context.Background(), "CREDENTIAL_ID_NO_COUNTER", 0)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
@@ -0,0 +1,102 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gouniverse/base/database"
+)
+
+// UserPasswordResetTokenCreate creates a one-time token for resetting
+// the password of the user, valid for the specified duration.
+//
+// The token is returned to be sent to the user (usually as a link),
+// only its hash is stored. Returns ErrPasswordResetLimitReached if
+// the user already has the maximum number of unexpired tokens.
+func (store *store) UserPasswordResetTokenCreate(ctx context.Context, userID string, ttl time.Duration) (string, error) {
+	if userID == "" {
+		return "", errors.New("user id is empty")
+	}
+
+	user, err := store.UserFindByID(ctx, userID)
+
+	if err != nil {
+		return "", err
+	}
+
+	if user == nil {
+		return "", ErrUserNotFound
+	}
+
+	outstanding, err := store.tokenCountByUserID(ctx, userID, TOKEN_TYPE_PASSWORD_RESET)
+
+	if err != nil {
+		return "", err
+	}
+
+	if outstanding >= int64(store.passwordResetMaxOutstanding) {
+		return "", ErrPasswordResetLimitReached
+	}
+
+	return store.tokenCreate(ctx, userID, TOKEN_TYPE_PASSWORD_RESET, "", ttl)
+}
+
+// UserPasswordReset sets the new password of the user the token was
+// created for, in a single transaction with invalidating the token,
+// and all the other password reset tokens of the user.
+//
+// The password is checked against the PasswordValidator, if set, and
+// stored hashed. Returns ErrTokenInvalid if the token is not found
+// or expired.
+func (store *store) UserPasswordReset(ctx context.Context, token string, newPassword string) (UserInterface, error) {
+	if newPassword == "" {
+		return nil, ErrPasswordEmpty
+	}
+
+	if store.passwordValidator != nil {
+		if err := store.passwordValidator(newPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	var user UserInterface
+
+	err := store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		userID, _, err := store.tokenConsume(txCtx, TOKEN_TYPE_PASSWORD_RESET, token)
+
+		if err != nil {
+			return err
+		}
+
+		user, err = store.UserFindByID(txCtx, userID)
+
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return ErrUserNotFound
+		}
+
+		if err := user.SetPasswordAndHash(newPassword); err != nil {
+			return err
+		}
+
+		if err := store.UserUpdate(txCtx, user); err != nil {
+			return err
+		}
+
+		if err := store.tokenDeleteByUserID(txCtx, userID, TOKEN_TYPE_PASSWORD_RESET); err != nil {
+			return err
+		}
+
+		return store.auditCreate(txCtx, userID, AUDIT_ACTION_PASSWORD_RESET, AUDIT_ENTITY_USER, userID, nil, nil)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
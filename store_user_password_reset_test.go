@@ -0,0 +1,160 @@
+package userstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStoreUserPasswordReset(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser()
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	token1, err := store.UserPasswordResetTokenCreate(context.Background(), user.ID(), time.Hour)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	token2, err := store.UserPasswordResetTokenCreate(context.Background(), user.ID(), time.Hour)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = store.UserPasswordReset(context.Background(), token1, "")
+
+	if !errors.Is(err, ErrPasswordEmpty) {
+		t.Fatal("Error MUST be ErrPasswordEmpty, found:", err)
+	}
+
+	userReset, err := store.UserPasswordReset(context.Background(), token1, "new_password")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userReset.ID() != user.ID() {
+		t.Fatal("IDs do not match")
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !userFound.PasswordCompare("new_password") {
+		t.Fatal("Password MUST be new_password")
+	}
+
+	// the other tokens are invalidated too
+	_, err = store.UserPasswordReset(context.Background(), token2, "another_password")
+
+	if !errors.Is(err, ErrTokenInvalid) {
+		t.Fatal("Error MUST be ErrTokenInvalid, found:", err)
+	}
+
+	var action string
+
+	err = store.DB().
+		QueryRow("SELECT action FROM user_table_audit WHERE entity_id = ? AND action = ?", user.ID(), AUDIT_ACTION_PASSWORD_RESET).
+		Scan(&action)
+
+	if err == sql.ErrNoRows {
+		t.Fatal("Audit event MUST be recorded")
+	}
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if action != AUDIT_ACTION_PASSWORD_RESET {
+		t.Fatal("Audit action MUST be password_reset, found:", action)
+	}
+}
+
+func TestStoreUserPasswordResetTokenCreateLimit(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                          db,
+		UserTableName:               "user_table",
+		AutomigrateEnabled:          true,
+		PasswordResetMaxOutstanding: 2,
+		PasswordValidator: func(password string) error {
+			if len(password) < 8 {
+				return errors.New("password is too short")
+			}
+
+			return nil
+		},
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser()
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err = store.UserPasswordResetTokenCreate(context.Background(), user.ID(), time.Hour)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	_, err = store.UserPasswordResetTokenCreate(context.Background(), user.ID(), time.Hour)
+
+	if !errors.Is(err, ErrPasswordResetLimitReached) {
+		t.Fatal("Error MUST be ErrPasswordResetLimitReached, found:", err)
+	}
+
+	token, err := store.UserPasswordResetTokenCreate(context.Background(), "", time.Hour)
+
+	if err == nil || token != "" {
+		t.Fatal("Error MUST be returned for empty user id")
+	}
+
+	_, err = store.UserPasswordReset(context.Background(), "any_token", "short")
+
+	if err == nil || err.Error() != "password is too short" {
+		t.Fatal("Error MUST be returned by the password validator, found:", err)
+	}
+}
@@ -0,0 +1,155 @@
+package userstore
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/utils"
+)
+
+const phoneVerificationCodeLength = 6
+const phoneVerificationMaxAttempts = 5
+
+// UserPhoneVerificationStart sends a verification code by SMS to
+// the phone number of the user, replacing any code sent before.
+//
+// The phone number is normalized to the E.164 format, using the
+// country of the user as the default region.
+func (store *store) UserPhoneVerificationStart(ctx context.Context, userID string) error {
+	if userID == "" {
+		return errors.New("user id is empty")
+	}
+
+	if store.smsSender == nil {
+		return errors.New("userstore: sms sender is required for phone verification")
+	}
+
+	user, err := store.UserFindByID(ctx, userID)
+
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	phone, err := PhoneNormalize(user.Phone(), user.Country())
+
+	if err != nil {
+		return err
+	}
+
+	code, err := randomFromAlphabet(phoneVerificationCodeLength, "0123456789")
+
+	if err != nil {
+		return err
+	}
+
+	err = store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		if err := store.tokenDeleteByUserID(txCtx, userID, TOKEN_TYPE_PHONE_VERIFICATION); err != nil {
+			return err
+		}
+
+		// the phone number is kept, so the code is valid only for it
+		return store.tokenInsert(txCtx, userID, TOKEN_TYPE_PHONE_VERIFICATION, phoneVerificationCodeHash(userID, code), phone, store.phoneVerificationCodeTTL)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return store.smsSender.Send(ctx, phone, "Your verification code is "+code)
+}
+
+// UserPhoneVerify verifies the code sent by UserPhoneVerificationStart,
+// and marks the phone number of the user as verified.
+//
+// Returns false if the code does not match. After too many attempts
+// the code is invalidated, and ErrPhoneVerificationAttemptsExceeded
+// is returned. Returns ErrTokenInvalid if there is no unexpired code,
+// or the phone number was changed since it was sent.
+func (store *store) UserPhoneVerify(ctx context.Context, userID string, code string) (bool, error) {
+	if userID == "" {
+		return false, errors.New("user id is empty")
+	}
+
+	token, err := store.tokenFindByUserID(ctx, userID, TOKEN_TYPE_PHONE_VERIFICATION)
+
+	if err != nil {
+		return false, err
+	}
+
+	if token == nil {
+		return false, ErrTokenInvalid
+	}
+
+	allowed, err := store.tokenAttemptRecord(ctx, token[COLUMN_ID], phoneVerificationMaxAttempts)
+
+	if err != nil {
+		return false, err
+	}
+
+	if !allowed {
+		if _, err := store.tokenDeleteByID(ctx, token[COLUMN_ID]); err != nil {
+			return false, err
+		}
+
+		return false, ErrPhoneVerificationAttemptsExceeded
+	}
+
+	codeHash := phoneVerificationCodeHash(userID, code)
+
+	if subtle.ConstantTimeCompare([]byte(codeHash), []byte(token[COLUMN_TOKEN_HASH])) != 1 {
+		return false, nil
+	}
+
+	err = store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		affected, err := store.tokenDeleteByID(txCtx, token[COLUMN_ID])
+
+		if err != nil {
+			return err
+		}
+
+		// deleted by a concurrent request, which used it first
+		if affected < 1 {
+			return ErrTokenInvalid
+		}
+
+		user, err := store.UserFindByID(txCtx, userID)
+
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return ErrUserNotFound
+		}
+
+		phone, err := PhoneNormalize(user.Phone(), user.Country())
+
+		if err != nil || phone != token[COLUMN_PAYLOAD] {
+			return ErrTokenInvalid
+		}
+
+		user.SetPhone(phone).
+			SetPhoneVerifiedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+		return store.UserUpdate(txCtx, user)
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// phoneVerificationCodeHash hashes the code together with the user ID,
+// as the short codes of different users may be the same
+func phoneVerificationCodeHash(userID string, code string) string {
+	return utils.StrToSHA256Hash(userID + ":" + code)
+}
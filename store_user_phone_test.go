@@ -0,0 +1,223 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gouniverse/sb"
+)
+
+func TestPhoneNormalize(t *testing.T) {
+	cases := []struct {
+		phone         string
+		defaultRegion string
+		expected      string
+		err           error
+	}{
+		{"+44 7911 123456", "", "+447911123456", nil},
+		{"0044 7911 123456", "", "+447911123456", nil},
+		{"07911 123456", "GB", "+447911123456", nil},
+		{"07911 123456", "gb", "+447911123456", nil},
+		{"(415) 555-2671", "US", "+14155552671", nil},
+		{"1-415-555-2671", "US", "+14155552671", nil},
+		{"06 12345678", "IT", "+390612345678", nil},
+		{"0888 123 456", "BG", "+359888123456", nil},
+		{"07911 123456", "", "", ErrPhoneRegionUnknown},
+		{"07911 123456", "XX", "", ErrPhoneRegionUnknown},
+		{"+44 7911 12345a", "", "", ErrPhoneInvalid},
+		{"+44 79", "", "", ErrPhoneInvalid},
+		{"+44 7911 1234 5678 9012", "", "", ErrPhoneInvalid},
+		{"", "GB", "", ErrPhoneInvalid},
+	}
+
+	for _, c := range cases {
+		normalized, err := PhoneNormalize(c.phone, c.defaultRegion)
+
+		if !errors.Is(err, c.err) {
+			t.Fatal("Error MUST be", c.err, "for", c.phone, "found:", err)
+		}
+
+		if normalized != c.expected {
+			t.Fatal("Phone MUST be", c.expected, "for", c.phone, "found:", normalized)
+		}
+	}
+}
+
+func TestUserSetPhoneAndNormalize(t *testing.T) {
+	user := NewUser().
+		SetCountry("GB").
+		SetPhoneVerifiedAt("2024-01-01 00:00:00")
+
+	err := user.SetPhoneAndNormalize("07911 123456")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if user.Phone() != "+447911123456" {
+		t.Fatal("Phone MUST be +447911123456, found:", user.Phone())
+	}
+
+	if user.PhoneVerifiedAt() != sb.NULL_DATETIME {
+		t.Fatal("Phone verification MUST be reset, found:", user.PhoneVerifiedAt())
+	}
+
+	err = user.SetPhoneAndNormalize("not a phone")
+
+	if !errors.Is(err, ErrPhoneInvalid) {
+		t.Fatal("Error MUST be ErrPhoneInvalid, found:", err)
+	}
+}
+
+func initStoreWithSmsSender(smsSender SmsSenderInterface) (StoreInterface, error) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		SmsSender:          smsSender,
+	})
+}
+
+func TestStoreUserPhoneVerify(t *testing.T) {
+	smsSender := NewSmsSenderMemory()
+
+	store, err := initStoreWithSmsSender(smsSender)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().
+		SetCountry("GB").
+		SetPhone("07911 123456")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserPhoneVerificationStart(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	messages := smsSender.Messages("+447911123456")
+
+	if len(messages) != 1 {
+		t.Fatal("Messages MUST be 1, found:", len(messages))
+	}
+
+	code := strings.TrimPrefix(messages[0], "Your verification code is ")
+
+	if len(code) != 6 {
+		t.Fatal("Code MUST be 6 digits, found:", code)
+	}
+
+	verified, err := store.UserPhoneVerify(context.Background(), user.ID(), "wrong")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if verified {
+		t.Fatal("Wrong code MUST NOT be accepted")
+	}
+
+	verified, err = store.UserPhoneVerify(context.Background(), user.ID(), code)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !verified {
+		t.Fatal("Code MUST be accepted")
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.Phone() != "+447911123456" {
+		t.Fatal("Phone MUST be normalized, found:", userFound.Phone())
+	}
+
+	if userFound.PhoneVerifiedAtCarbon().Year() < 2000 {
+		t.Fatal("Phone verified at MUST be set, found:", userFound.PhoneVerifiedAt())
+	}
+
+	_, err = store.UserPhoneVerify(context.Background(), user.ID(), code)
+
+	if !errors.Is(err, ErrTokenInvalid) {
+		t.Fatal("Code MUST NOT be accepted twice, found:", err)
+	}
+}
+
+func TestStoreUserPhoneVerifyAttemptsExceeded(t *testing.T) {
+	smsSender := NewSmsSenderMemory()
+
+	store, err := initStoreWithSmsSender(smsSender)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetPhone("+447911123456")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserPhoneVerificationStart(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	code := strings.TrimPrefix(smsSender.Messages("+447911123456")[0], "Your verification code is ")
+
+	for i := 0; i < phoneVerificationMaxAttempts; i++ {
+		_, err = store.UserPhoneVerify(context.Background(), user.ID(), "wrong")
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	_, err = store.UserPhoneVerify(context.Background(), user.ID(), code)
+
+	if !errors.Is(err, ErrPhoneVerificationAttemptsExceeded) {
+		t.Fatal("Error MUST be ErrPhoneVerificationAttemptsExceeded, found:", err)
+	}
+
+	_, err = store.UserPhoneVerify(context.Background(), user.ID(), code)
+
+	if !errors.Is(err, ErrTokenInvalid) {
+		t.Fatal("Code MUST be invalidated, found:", err)
+	}
+}
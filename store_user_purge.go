@@ -0,0 +1,299 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/spf13/cast"
+)
+
+// PurgeReport reports the outcome of purging the soft deleted users
+type PurgeReport struct {
+	// UserIDs are the IDs of the purged users
+	UserIDs []string
+
+	// Counts are the numbers of the deleted (or for the audit table
+	// the redacted) rows, by table name
+	Counts map[string]int64
+}
+
+// UserPurgeSoftDeleted permanently deletes the users, which were soft
+// deleted more than olderThan ago, in batches of batchSize.
+//
+// Together with each user, its API keys, email history, identities, MFA,
+// outbox events, passkeys, recovery codes, revisions, sessions, status
+// history, tokens and webhook deliveries are deleted, and its audit events
+// are redacted. The metas are stored with the user. Each user is purged in
+// its own transaction, so if the purge is interrupted it can be run again
+// to continue.
+//
+// The outbox events and the webhook deliveries carry the data of the user,
+// so the undelivered ones are deleted too. A user.purged event is queued
+// instead, for the consumers to delete their copies.
+func (store *store) UserPurgeSoftDeleted(ctx context.Context, olderThan time.Duration, batchSize int) (PurgeReport, error) {
+	report := PurgeReport{
+		UserIDs: []string{},
+		Counts:  map[string]int64{},
+	}
+
+	if olderThan < 0 {
+		return report, errors.New("at user purge soft deleted > older than cannot be negative")
+	}
+
+	if batchSize < 1 {
+		return report, errors.New("at user purge soft deleted > batch size must be positive")
+	}
+
+	for {
+		userIDs, err := store.purgeCandidates(ctx, olderThan, batchSize)
+
+		if err != nil {
+			return report, err
+		}
+
+		if len(userIDs) < 1 {
+			return report, nil
+		}
+
+		for _, userID := range userIDs {
+			err := store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+				return store.userPurge(txCtx, userID, report.Counts)
+			})
+
+			if err != nil {
+				return report, err
+			}
+
+			report.UserIDs = append(report.UserIDs, userID)
+		}
+	}
+}
+
+// UserPurgeSoftDeletedDryRun returns the report of what
+// UserPurgeSoftDeleted would purge, without deleting anything
+func (store *store) UserPurgeSoftDeletedDryRun(ctx context.Context, olderThan time.Duration) (PurgeReport, error) {
+	report := PurgeReport{
+		UserIDs: []string{},
+		Counts:  map[string]int64{},
+	}
+
+	if olderThan < 0 {
+		return report, errors.New("at user purge soft deleted dry run > older than cannot be negative")
+	}
+
+	userIDs, err := store.purgeCandidates(ctx, olderThan, 0)
+
+	if err != nil {
+		return report, err
+	}
+
+	if len(userIDs) < 1 {
+		return report, nil
+	}
+
+	report.UserIDs = userIDs
+
+	for _, tableName := range store.purgeRelatedTableNames() {
+		count, err := store.purgeCount(ctx, tableName, goqu.C(COLUMN_USER_ID).In(userIDs))
+
+		if err != nil {
+			return report, err
+		}
+
+		report.Counts[tableName] = count
+	}
+
+	redacted, err := store.purgeCount(ctx, store.auditTableName,
+		goqu.C(COLUMN_ENTITY).Eq(AUDIT_ENTITY_USER),
+		goqu.C(COLUMN_ENTITY_ID).In(userIDs))
+
+	if err != nil {
+		return report, err
+	}
+
+	report.Counts[store.auditTableName] = redacted
+	report.Counts[store.userTableName] = int64(len(userIDs))
+
+	return report, nil
+}
+
+// purgeCandidates returns the IDs of the users soft deleted more than
+// olderThan ago, the longest deleted first. A limit of 0 means no limit.
+func (store *store) purgeCandidates(ctx context.Context, olderThan time.Duration, limit int) ([]string, error) {
+	cutoff := carbon.Now(carbon.UTC).SubDuration(olderThan.String()).ToDateTimeString(carbon.UTC)
+
+	q := goqu.Dialect(store.dbDriverName).
+		From(store.userTableName).
+		Prepared(true).
+		Select(COLUMN_ID).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Lt(cutoff)).
+		Order(goqu.C(COLUMN_SOFT_DELETED_AT).Asc(), goqu.C(COLUMN_ID).Asc())
+
+	if limit > 0 {
+		q = q.Limit(uint(limit))
+	}
+
+	sqlStr, params, errSql := q.ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := []string{}
+
+	for _, modelMap := range modelMaps {
+		userIDs = append(userIDs, modelMap[COLUMN_ID])
+	}
+
+	return userIDs, nil
+}
+
+// userPurge permanently deletes the user with its related data,
+// and adds the numbers of the affected rows to counts
+func (store *store) userPurge(ctx context.Context, userID string, counts map[string]int64) error {
+	before, err := store.userDataByID(ctx, userID)
+
+	if err != nil {
+		return err
+	}
+
+	if before == nil {
+		return nil // already purged
+	}
+
+	if store.beforeUserDelete != nil {
+		if err := store.beforeUserDelete(ctx, NewUserFromExistingData(before)); err != nil {
+			return err
+		}
+	}
+
+	for _, tableName := range store.purgeRelatedTableNames() {
+		deleted, err := store.purgeRows(ctx, tableName, COLUMN_USER_ID, userID)
+
+		if err != nil {
+			return err
+		}
+
+		counts[tableName] += deleted
+	}
+
+	redacted, err := store.auditRedactEntity(ctx, AUDIT_ENTITY_USER, userID)
+
+	if err != nil {
+		return err
+	}
+
+	counts[store.auditTableName] += redacted
+
+	deleted, err := store.purgeRows(ctx, store.userTableName, COLUMN_ID, userID)
+
+	if err != nil {
+		return err
+	}
+
+	counts[store.userTableName] += deleted
+
+	if err := store.auditCreate(ctx, "", AUDIT_ACTION_PURGE, AUDIT_ENTITY_USER, userID, nil, nil); err != nil {
+		return err
+	}
+
+	if err := store.outboxCreate(ctx, OUTBOX_EVENT_USER_PURGED, userID, map[string]any{}); err != nil {
+		return err
+	}
+
+	if store.afterUserDelete != nil {
+		return store.afterUserDelete(ctx, NewUserFromExistingData(before))
+	}
+
+	return nil
+}
+
+// purgeCount returns the number of the rows of the table matching the conditions
+func (store *store) purgeCount(ctx context.Context, tableName string, conditions ...goqu.Expression) (int64, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(tableName).
+		Prepared(true).
+		Select(goqu.COUNT(goqu.Star()).As("count")).
+		Where(conditions...).
+		ToSQL()
+
+	if errSql != nil {
+		return 0, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	mapped, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(mapped) < 1 {
+		return 0, nil
+	}
+
+	return cast.ToInt64(mapped[0]["count"]), nil
+}
+
+// purgeRelatedTableNames returns the names of the tables, which rows
+// are deleted together with the user, by the user ID column
+func (store *store) purgeRelatedTableNames() []string {
+	return []string{
+		store.apiKeyTableName,
+		store.emailHistoryTableName,
+		store.identityTableName,
+		store.mfaTableName,
+		store.outboxTableName,
+		store.passkeyTableName,
+		store.recoveryCodeTableName,
+		store.revisionTableName,
+		store.sessionTableName,
+		store.statusHistoryTableName,
+		store.tokenTableName,
+		store.webhookDeliveryTableName,
+	}
+}
+
+// purgeRows deletes the rows of the table, where the column has the value,
+// and returns the number of the deleted rows
+func (store *store) purgeRows(ctx context.Context, tableName string, column string, value string) (int64, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(tableName).
+		Prepared(true).
+		Where(goqu.C(column).Eq(value)).
+		ToSQL()
+
+	if errSql != nil {
+		return 0, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
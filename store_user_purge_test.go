@@ -0,0 +1,275 @@
+package userstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/sb"
+)
+
+func TestStoreUserPurgeSoftDeleted(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+		carbon.ClearTestNow()
+	}()
+
+	ctx := WithAuditMetadata(context.Background(), map[string]string{
+		"ip_address": "127.0.0.1",
+	})
+
+	carbon.SetTestNow(carbon.Parse("2025-01-01 10:00:00", carbon.UTC))
+
+	// the deliveries of the events carry the data of the users
+	if _, err := store.WebhookCreate(ctx, NewWebhook().
+		SetURL("http://127.0.0.1:1").
+		SetEventTypes([]string{OUTBOX_EVENT_USER_CREATED})); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userOld := NewUser().SetEmail("old@test.com").SetFirstName("Old")
+	userRecent := NewUser().SetEmail("recent@test.com")
+	userActive := NewUser().SetEmail("active@test.com")
+
+	for _, user := range []UserInterface{userOld, userRecent, userActive} {
+		if err := store.UserCreate(ctx, user); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if _, err := store.UserSessionCreate(ctx, NewSession().SetUserID(userOld.ID())); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the session outlives the default TTL, which the test travels past
+	if _, err := store.UserSessionCreate(ctx, NewSession().SetUserID(userActive.ID()).SetExpiresAt(sb.MAX_DATETIME)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserUpdate(ctx, userOld.SetFirstName("Older")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSoftDelete(ctx, userOld); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	carbon.SetTestNow(carbon.Parse("2025-02-15 10:00:00", carbon.UTC))
+
+	if err := store.UserSoftDelete(ctx, userRecent); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	carbon.SetTestNow(carbon.Parse("2025-03-01 10:00:00", carbon.UTC))
+
+	retention := 30 * 24 * time.Hour
+
+	rowCount := func(tableName string, userID string) int64 {
+		var count int64
+
+		err := store.DB().
+			QueryRow("SELECT COUNT(*) FROM "+tableName+" WHERE user_id = ?", userID).
+			Scan(&count)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		return count
+	}
+
+	outboxCount := rowCount("user_table_outbox", userOld.ID())
+
+	if outboxCount < 1 {
+		t.Fatal("Outbox events MUST be queued for the old user")
+	}
+
+	if rowCount("user_table_webhook_delivery", userOld.ID()) != 1 {
+		t.Fatal("Webhook deliveries MUST be 1 for the old user")
+	}
+
+	dryRun, err := store.UserPurgeSoftDeletedDryRun(context.Background(), retention)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(dryRun.UserIDs) != 1 || dryRun.UserIDs[0] != userOld.ID() {
+		t.Fatal("Dry run MUST return the old user only, found:", dryRun.UserIDs)
+	}
+
+	if dryRun.Counts["user_table"] != 1 || dryRun.Counts["user_table_session"] != 1 || dryRun.Counts["user_table_audit"] != 3 {
+		t.Fatal("Dry run MUST count the rows to purge, found:", dryRun.Counts)
+	}
+
+	if dryRun.Counts["user_table_outbox"] != outboxCount || dryRun.Counts["user_table_webhook_delivery"] != 1 {
+		t.Fatal("Dry run MUST count the outbox events and the webhook deliveries, found:", dryRun.Counts)
+	}
+
+	count, err := store.UserCount(context.Background(), NewUserQuery().SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 3 {
+		t.Fatal("Dry run MUST NOT delete users, found:", count)
+	}
+
+	report, err := store.UserPurgeSoftDeleted(context.Background(), retention, 1)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(report.UserIDs) != 1 || report.UserIDs[0] != userOld.ID() {
+		t.Fatal("Purged MUST be the old user only, found:", report.UserIDs)
+	}
+
+	if report.Counts["user_table"] != 1 {
+		t.Fatal("Purged users MUST be 1, found:", report.Counts["user_table"])
+	}
+
+	if report.Counts["user_table_session"] != 1 {
+		t.Fatal("Purged sessions MUST be 1, found:", report.Counts["user_table_session"])
+	}
+
+	if report.Counts["user_table_audit"] != 3 {
+		t.Fatal("Redacted audits MUST be 3, found:", report.Counts["user_table_audit"])
+	}
+
+	if report.Counts["user_table_outbox"] != outboxCount || report.Counts["user_table_webhook_delivery"] != 1 {
+		t.Fatal("Outbox events and webhook deliveries MUST be purged, found:", report.Counts)
+	}
+
+	// only the user.purged event is left
+	if rowCount("user_table_outbox", userOld.ID()) != 1 {
+		t.Fatal("Outbox events MUST be 1 after the purge, found:", rowCount("user_table_outbox", userOld.ID()))
+	}
+
+	if rowCount("user_table_webhook_delivery", userOld.ID()) != 0 {
+		t.Fatal("Webhook deliveries MUST be deleted, found:", rowCount("user_table_webhook_delivery", userOld.ID()))
+	}
+
+	if rowCount("user_table_webhook_delivery", userActive.ID()) != 1 {
+		t.Fatal("Webhook deliveries of the other users MUST be kept")
+	}
+
+	count, err = store.UserCount(context.Background(), NewUserQuery().SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("Users MUST be 2 after the purge, found:", count)
+	}
+
+	sessions, err := store.UserSessionList(context.Background(), userActive.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(sessions) != 1 {
+		t.Fatal("Sessions of the other users MUST be kept, found:", len(sessions))
+	}
+
+	audits, err := store.AuditList(context.Background(), NewAuditQuery().
+		SetEntity(AUDIT_ENTITY_USER).
+		SetEntityID(userOld.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(audits) != 4 {
+		t.Fatal("Audits MUST be 4, found:", len(audits))
+	}
+
+	if audits[0].Action() != AUDIT_ACTION_PURGE {
+		t.Fatal("Newest audit MUST be purge, found:", audits[0].Action())
+	}
+
+	for _, audit := range audits[1:] {
+		if audit.Metadata() != "" {
+			t.Fatal("Audit metadata MUST be cleared, found:", audit.Metadata())
+		}
+
+		for column, change := range audit.DiffMap() {
+			for _, value := range change {
+				if value != "" && value != auditRedactedValue {
+					t.Fatal("Audit diff MUST be redacted, found:", column, value)
+				}
+			}
+		}
+	}
+
+	report, err = store.UserPurgeSoftDeleted(context.Background(), retention, 1)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(report.UserIDs) != 0 {
+		t.Fatal("Second purge MUST purge nothing, found:", report.UserIDs)
+	}
+}
+
+func TestStoreUserPurgeSoftDeletedBatches(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+		carbon.ClearTestNow()
+	}()
+
+	for i := 0; i < 5; i++ {
+		user := NewUser()
+
+		if err := store.UserCreate(context.Background(), user); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if err := store.UserSoftDelete(context.Background(), user); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	_, err = store.UserPurgeSoftDeleted(context.Background(), 0, 0)
+
+	if err == nil {
+		t.Fatal("Error MUST be returned for a batch size of 0")
+	}
+
+	carbon.SetTestNow(carbon.Now(carbon.UTC).AddMinute())
+
+	report, err := store.UserPurgeSoftDeleted(context.Background(), 0, 2)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(report.UserIDs) != 5 {
+		t.Fatal("Purged MUST be 5, found:", len(report.UserIDs))
+	}
+
+	if report.Counts["user_table"] != 5 {
+		t.Fatal("Purged users MUST be 5, found:", report.Counts["user_table"])
+	}
+}
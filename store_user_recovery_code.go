@@ -0,0 +1,193 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/uid"
+	"github.com/gouniverse/utils"
+)
+
+// recoveryCodeAlphabet omits the characters that are easily confused (0/o, 1/l/i)
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+const recoveryCodeLength = 10
+
+// UserRecoveryCodesGenerate generates new single use recovery codes for
+// the user, invalidating all the previous ones.
+//
+// The codes are returned to be shown to the user once,
+// only their hashes are stored.
+func (store *store) UserRecoveryCodesGenerate(ctx context.Context, userID string, count int) ([]string, error) {
+	if userID == "" {
+		return nil, errors.New("user id is empty")
+	}
+
+	if count < 1 {
+		return nil, errors.New("recovery code count must be greater than 0")
+	}
+
+	codes := make([]string, 0, count)
+
+	for len(codes) < count {
+		code, err := randomFromAlphabet(recoveryCodeLength, recoveryCodeAlphabet)
+
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+	}
+
+	err := store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		if err := store.recoveryCodesDeleteByUserID(txCtx, userID); err != nil {
+			return err
+		}
+
+		rows := []any{}
+
+		for _, code := range codes {
+			rows = append(rows, map[string]string{
+				COLUMN_ID:         uid.HumanUid(),
+				COLUMN_USER_ID:    userID,
+				COLUMN_CODE_HASH:  recoveryCodeHash(code),
+				COLUMN_CREATED_AT: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+			})
+		}
+
+		sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+			Insert(store.recoveryCodeTableName).
+			Prepared(true).
+			Rows(rows...).
+			ToSQL()
+
+		if errSql != nil {
+			return errSql
+		}
+
+		if store.debugEnabled {
+			log.Println(sqlStr)
+		}
+
+		_, err := database.Execute(txCtx, sqlStr, params...)
+
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// UserRecoveryCodeConsume verifies the recovery code of the user and
+// invalidates it in the same statement, so each code is accepted once.
+//
+// Returns false, if the code is not valid or was already used.
+func (store *store) UserRecoveryCodeConsume(ctx context.Context, userID string, code string) (bool, error) {
+	if userID == "" {
+		return false, errors.New("user id is empty")
+	}
+
+	if strings.TrimSpace(code) == "" {
+		return false, nil
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.recoveryCodeTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Where(goqu.C(COLUMN_CODE_HASH).Eq(recoveryCodeHash(code))).
+		ToSQL()
+
+	if errSql != nil {
+		return false, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// UserRecoveryCodesRemaining returns the number of unused recovery codes of the user
+func (store *store) UserRecoveryCodesRemaining(ctx context.Context, userID string) (int64, error) {
+	if userID == "" {
+		return -1, errors.New("user id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.recoveryCodeTableName).
+		Prepared(true).
+		Select(goqu.COUNT(goqu.Star()).As("count")).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		ToSQL()
+
+	if errSql != nil {
+		return -1, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	mapped, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return -1, err
+	}
+
+	if len(mapped) < 1 {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(mapped[0]["count"], 10, 64)
+}
+
+func (store *store) recoveryCodesDeleteByUserID(ctx context.Context, userID string) error {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.recoveryCodeTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// recoveryCodeHash hashes the code, ignoring the case and the separators
+func recoveryCodeHash(code string) string {
+	normalized := strings.ToLower(code)
+	normalized = strings.ReplaceAll(normalized, "-", "")
+	normalized = strings.ReplaceAll(normalized, " ", "")
+
+	return utils.StrToSHA256Hash(normalized)
+}
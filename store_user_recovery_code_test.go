@@ -0,0 +1,172 @@
+package userstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStoreUserRecoveryCodesGenerate(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser()
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	codes, err := store.UserRecoveryCodesGenerate(context.Background(), user.ID(), 10)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(codes) != 10 {
+		t.Fatal("Codes MUST be 10, found:", len(codes))
+	}
+
+	if len(codes[0]) != 11 || codes[0][5] != '-' {
+		t.Fatal("Code MUST be formatted as xxxxx-xxxxx, found:", codes[0])
+	}
+
+	var codeHashStored string
+
+	err = store.DB().
+		QueryRow("SELECT code_hash FROM user_table_recovery_code WHERE user_id = ? LIMIT 1", user.ID()).
+		Scan(&codeHashStored)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, code := range codes {
+		if codeHashStored == code {
+			t.Fatal("Codes MUST NOT be stored in plain text")
+		}
+	}
+
+	// generating again invalidates the previous codes
+	_, err = store.UserRecoveryCodesGenerate(context.Background(), user.ID(), 5)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	remaining, err := store.UserRecoveryCodesRemaining(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if remaining != 5 {
+		t.Fatal("Remaining codes MUST be 5, found:", remaining)
+	}
+
+	consumed, err := store.UserRecoveryCodeConsume(context.Background(), user.ID(), codes[0])
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if consumed {
+		t.Fatal("Previous code MUST NOT be accepted after regenerating")
+	}
+}
+
+func TestStoreUserRecoveryCodeConsume(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser()
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	codes, err := store.UserRecoveryCodesGenerate(context.Background(), user.ID(), 3)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	consumed, err := store.UserRecoveryCodeConsume(context.Background(), user.ID(), "wrong-code")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if consumed {
+		t.Fatal("Wrong code MUST NOT be accepted")
+	}
+
+	// case and separators are ignored
+	consumed, err = store.UserRecoveryCodeConsume(context.Background(), user.ID(), strings.ToUpper(strings.ReplaceAll(codes[0], "-", " ")))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !consumed {
+		t.Fatal("Code MUST be accepted")
+	}
+
+	consumed, err = store.UserRecoveryCodeConsume(context.Background(), user.ID(), codes[0])
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if consumed {
+		t.Fatal("Code MUST NOT be accepted twice")
+	}
+
+	remaining, err := store.UserRecoveryCodesRemaining(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if remaining != 2 {
+		t.Fatal("Remaining codes MUST be 2, found:", remaining)
+	}
+
+	// disabling MFA removes the recovery codes
+	err = store.UserMFADisable(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	remaining, err = store.UserRecoveryCodesRemaining(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if remaining != 0 {
+		t.Fatal("Remaining codes MUST be 0, found:", remaining)
+	}
+}
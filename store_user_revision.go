@@ -0,0 +1,299 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/uid"
+	"github.com/gouniverse/utils"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// revisionExcludedColumns are the columns, which are not kept in the
+// revisions, so restoring a revision cannot bring back an old password,
+// nor undo a lockout, a suspension, a verification or a status change
+var revisionExcludedColumns = []string{
+	COLUMN_PASSWORD,
+	COLUMN_STATUS,
+	COLUMN_FAILED_LOGIN_COUNT,
+	COLUMN_FAILED_LOGIN_FIRST_AT,
+	COLUMN_LOCKOUT_COUNT,
+	COLUMN_LOCKED_UNTIL,
+	COLUMN_SUSPENDED_UNTIL,
+	COLUMN_SUSPENDED_BY,
+	COLUMN_SUSPENSION_REASON,
+	COLUMN_VERIFIED_AT,
+	COLUMN_PHONE_VERIFIED_AT,
+	COLUMN_SOFT_DELETED_AT,
+}
+
+// UserAtRevision returns the user as it was at the specified revision,
+// or nil if the revision does not exist (or was removed by the retention)
+func (store *store) UserAtRevision(ctx context.Context, userID string, revision int) (UserInterface, error) {
+	if userID == "" {
+		return nil, errors.New("user id is empty")
+	}
+
+	found, err := store.revisionFind(ctx, userID, revision)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if found == nil {
+		return nil, nil
+	}
+
+	// the revisions stored before the exclusion may have the columns
+	return NewUserFromExistingData(lo.OmitByKeys(found.SnapshotMap(), revisionExcludedColumns)), nil
+}
+
+// UserRestoreRevision restores the user to the state at the specified
+// revision. The restore is an update itself, so it is recorded in the audit
+// log and creates a new revision, and can be undone too.
+//
+// Returns ErrRevisionNotFound, if the revision does not exist.
+func (store *store) UserRestoreRevision(ctx context.Context, userID string, revision int) (UserInterface, error) {
+	if userID == "" {
+		return nil, errors.New("user id is empty")
+	}
+
+	var restored UserInterface
+
+	err := store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		found, err := store.revisionFind(txCtx, userID, revision)
+
+		if err != nil {
+			return err
+		}
+
+		if found == nil {
+			return ErrRevisionNotFound
+		}
+
+		current, err := store.userDataByID(txCtx, userID)
+
+		if err != nil {
+			return err
+		}
+
+		if current == nil {
+			return ErrUserNotFound
+		}
+
+		userRestored := NewUserFromExistingData(current).(*user)
+
+		for column, value := range lo.OmitByKeys(found.SnapshotMap(), revisionExcludedColumns) {
+			if column == COLUMN_ID || current[column] == value {
+				continue
+			}
+
+			userRestored.Set(column, value)
+		}
+
+		restored = userRestored
+
+		return store.UserUpdate(txCtx, userRestored)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// UserRevisions returns the revisions of the user, the newest first
+func (store *store) UserRevisions(ctx context.Context, userID string) ([]RevisionInterface, error) {
+	if userID == "" {
+		return nil, errors.New("user id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.revisionTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Order(goqu.C(COLUMN_REVISION).Desc()).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := []RevisionInterface{}
+
+	for _, modelMap := range modelMaps {
+		list = append(list, NewRevisionFromExistingData(modelMap))
+	}
+
+	return list, nil
+}
+
+// revisionCreate stores the snapshot of the user after the change, the
+// before data merged with the changed data. If the user has no revisions yet
+// (i.e. created before the revisions were introduced), the before data is
+// stored first, so the first change can be undone too. The changes of the
+// excluded columns only do not create a revision.
+func (store *store) revisionCreate(ctx context.Context, userID string, before map[string]string, changed map[string]string) error {
+	changed = lo.OmitByKeys(changed, revisionExcludedColumns)
+
+	// the updated at column changes with every update
+	if len(lo.OmitByKeys(changed, []string{COLUMN_UPDATED_AT})) < 1 {
+		return nil
+	}
+
+	if before != nil {
+		before = lo.OmitByKeys(before, revisionExcludedColumns)
+	}
+
+	latest, err := store.revisionLatest(ctx, userID)
+
+	if err != nil {
+		return err
+	}
+
+	if latest == 0 && before != nil {
+		latest++
+
+		if err := store.revisionInsert(ctx, userID, latest, before); err != nil {
+			return err
+		}
+	}
+
+	latest++
+
+	if err := store.revisionInsert(ctx, userID, latest, lo.Assign(before, changed)); err != nil {
+		return err
+	}
+
+	if store.revisionRetention < 1 || latest <= store.revisionRetention {
+		return nil
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.revisionTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Where(goqu.C(COLUMN_REVISION).Lte(latest - store.revisionRetention)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err = database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+func (store *store) revisionFind(ctx context.Context, userID string, revision int) (RevisionInterface, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.revisionTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Where(goqu.C(COLUMN_REVISION).Eq(revision)).
+		Limit(1).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(modelMaps) < 1 {
+		return nil, nil
+	}
+
+	return NewRevisionFromExistingData(modelMaps[0]), nil
+}
+
+func (store *store) revisionInsert(ctx context.Context, userID string, revision int, snapshot map[string]string) error {
+	snapshotJson, err := utils.ToJSON(snapshot)
+
+	if err != nil {
+		return err
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.revisionTableName).
+		Prepared(true).
+		Rows(map[string]any{
+			COLUMN_ID:         uid.HumanUid(),
+			COLUMN_USER_ID:    userID,
+			COLUMN_REVISION:   revision,
+			COLUMN_SNAPSHOT:   snapshotJson,
+			COLUMN_CREATED_AT: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		}).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err = database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// revisionLatest returns the number of the latest revision of the user,
+// or 0 if the user has no revisions
+func (store *store) revisionLatest(ctx context.Context, userID string) (int, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.revisionTableName).
+		Prepared(true).
+		Select(goqu.MAX(COLUMN_REVISION).As("latest")).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		ToSQL()
+
+	if errSql != nil {
+		return 0, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	mapped, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(mapped) < 1 {
+		return 0, nil
+	}
+
+	return cast.ToInt(mapped[0]["latest"]), nil
+}
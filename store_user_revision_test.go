@@ -0,0 +1,298 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gouniverse/sb"
+)
+
+func TestStoreUserRevisions(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetFirstName("John").SetLastName("Doe")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	user.SetFirstName("Jane")
+
+	err = store.UserUpdate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	revisions, err := store.UserRevisions(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(revisions) != 2 {
+		t.Fatal("Revisions MUST be 2, found:", len(revisions))
+	}
+
+	if revisions[0].Revision() != 2 {
+		t.Fatal("Newest revision MUST be first, found:", revisions[0].Revision())
+	}
+
+	if revisions[0].SnapshotMap()[COLUMN_FIRST_NAME] != "Jane" {
+		t.Fatal("Snapshot MUST contain the new first name, found:", revisions[0].SnapshotMap()[COLUMN_FIRST_NAME])
+	}
+
+	if revisions[0].SnapshotMap()[COLUMN_LAST_NAME] != "Doe" {
+		t.Fatal("Snapshot MUST be full, found:", revisions[0].SnapshotMap())
+	}
+
+	userAt, err := store.UserAtRevision(context.Background(), user.ID(), 1)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userAt == nil {
+		t.Fatal("User at revision 1 MUST NOT be nil")
+	}
+
+	if userAt.FirstName() != "John" {
+		t.Fatal("First name at revision 1 MUST be John, found:", userAt.FirstName())
+	}
+
+	userAt, err = store.UserAtRevision(context.Background(), user.ID(), 5)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userAt != nil {
+		t.Fatal("User at missing revision MUST be nil")
+	}
+}
+
+func TestStoreUserRestoreRevision(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().
+		SetFirstName("John").
+		SetEmail("john@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// bad edit
+	user.SetFirstName("").SetEmail("wrong@test.com")
+
+	err = store.UserUpdate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	restored, err := store.UserRestoreRevision(context.Background(), user.ID(), 1)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if restored.FirstName() != "John" {
+		t.Fatal("First name MUST be restored, found:", restored.FirstName())
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.FirstName() != "John" || userFound.Email() != "john@test.com" {
+		t.Fatal("User MUST be restored, found:", userFound.FirstName(), userFound.Email())
+	}
+
+	revisions, err := store.UserRevisions(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(revisions) != 3 {
+		t.Fatal("Restore MUST create a new revision, found:", len(revisions))
+	}
+
+	_, err = store.UserRestoreRevision(context.Background(), user.ID(), 10)
+
+	if !errors.Is(err, ErrRevisionNotFound) {
+		t.Fatal("Error MUST be ErrRevisionNotFound, found:", err)
+	}
+}
+
+func TestStoreUserRestoreRevisionSecurityColumnsKept(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetFirstName("John").SetStatus(USER_STATUS_ACTIVE)
+
+	if err := user.SetPasswordAndHash("old_password"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := user.SetPasswordAndHash("new_password"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	user.SetFirstName("Jane")
+
+	if err := store.UserUpdate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSuspend(context.Background(), user, sb.MAX_DATETIME, "Fraud"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	revisions, err := store.UserRevisions(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the suspension alone does not create a revision
+	if len(revisions) != 2 {
+		t.Fatal("Revisions MUST be 2, found:", len(revisions))
+	}
+
+	for _, revision := range revisions {
+		if _, found := revision.SnapshotMap()[COLUMN_PASSWORD]; found {
+			t.Fatal("Snapshot MUST NOT contain the password, found:", revision.SnapshotMap())
+		}
+	}
+
+	restored, err := store.UserRestoreRevision(context.Background(), user.ID(), 1)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if restored.FirstName() != "John" {
+		t.Fatal("First name MUST be restored to John, found:", restored.FirstName())
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !userFound.PasswordCompare("new_password") {
+		t.Fatal("Password MUST NOT be restored")
+	}
+
+	if !userFound.IsSuspended() {
+		t.Fatal("Suspension MUST NOT be lifted by the restore")
+	}
+
+	if userFound.SuspensionReason() != "Fraud" {
+		t.Fatal("Suspension reason MUST be kept, found:", userFound.SuspensionReason())
+	}
+}
+
+func TestStoreUserRevisionRetention(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		RevisionRetention:  2,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetFirstName("0")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, firstName := range []string{"1", "2", "3"} {
+		user.SetFirstName(firstName)
+
+		if err := store.UserUpdate(context.Background(), user); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	revisions, err := store.UserRevisions(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(revisions) != 2 {
+		t.Fatal("Revisions MUST be 2, found:", len(revisions))
+	}
+
+	if revisions[0].Revision() != 4 || revisions[1].Revision() != 3 {
+		t.Fatal("The newest revisions MUST be kept, found:", revisions[0].Revision(), revisions[1].Revision())
+	}
+
+	_, err = store.UserRestoreRevision(context.Background(), user.ID(), 1)
+
+	if !errors.Is(err, ErrRevisionNotFound) {
+		t.Fatal("Pruned revision MUST NOT be found, found:", err)
+	}
+}
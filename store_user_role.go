@@ -0,0 +1,37 @@
+package userstore
+
+import (
+	"slices"
+
+	"github.com/samber/lo"
+)
+
+// UserRole describes a role in the registry of the user roles
+type UserRole struct {
+	// Key is the value stored in the role column
+	Key string
+
+	// Label is the name of the role shown to the humans
+	Label string
+}
+
+// DefaultRoles returns the registry of the USER_ROLE_ constants
+func DefaultRoles() []UserRole {
+	return []UserRole{
+		{Key: USER_ROLE_SUPERUSER, Label: "Superuser"},
+		{Key: USER_ROLE_ADMINISTRATOR, Label: "Administrator"},
+		{Key: USER_ROLE_MANAGER, Label: "Manager"},
+		{Key: USER_ROLE_USER, Label: "User"},
+	}
+}
+
+// Roles returns the registry of the user roles, in the configured order
+func (store *store) Roles() []UserRole {
+	return slices.Clone(store.roles)
+}
+
+func (store *store) roleFind(key string) (UserRole, bool) {
+	return lo.Find(store.roles, func(role UserRole) bool {
+		return role.Key == key
+	})
+}
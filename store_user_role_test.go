@@ -0,0 +1,63 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStoreRoles(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		Roles: append(DefaultRoles(), UserRole{
+			Key:   "support",
+			Label: "Support Agent",
+		}),
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if len(store.Roles()) != 5 || store.Roles()[4].Key != "support" {
+		t.Fatal("Roles MUST be the registry, found:", store.Roles())
+	}
+
+	ctx := context.Background()
+
+	err = store.UserCreate(ctx, NewUser().SetRole("suport"))
+
+	if !errors.Is(err, ErrRoleInvalid) {
+		t.Fatal("Error MUST be ErrRoleInvalid, found:", err)
+	}
+
+	user := NewUser().SetRole("support")
+
+	if err := store.UserCreate(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserUpdate(ctx, user.SetRole("admin"))
+
+	if !errors.Is(err, ErrRoleInvalid) {
+		t.Fatal("Error MUST be ErrRoleInvalid, found:", err)
+	}
+
+	if err := store.UserUpdate(ctx, user.SetRole(USER_ROLE_MANAGER)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
@@ -0,0 +1,278 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/utils"
+)
+
+// sessionTokenLength is the length of the session tokens, from the
+// 62 chars of the token alphabet, i.e. about 190 bits
+const sessionTokenLength = 32
+
+// UserSessionCreate stores a new login session of the user. The session
+// expires after the session TTL of the store, unless its expiry is set.
+//
+// Returns the token to be kept by the client (i.e. in a cookie), the
+// session is found by it with UserSessionFindByToken. Only the hash
+// of the token is stored.
+func (store *store) UserSessionCreate(ctx context.Context, session SessionInterface) (string, error) {
+	if session == nil {
+		return "", errors.New("session is nil")
+	}
+
+	if session.UserID() == "" {
+		return "", errors.New("session user id is empty")
+	}
+
+	token, err := randomFromAlphabet(sessionTokenLength, tokenAlphabet)
+
+	if err != nil {
+		return "", err
+	}
+
+	if session.ExpiresAt() == "" {
+		session.SetExpiresAt(carbon.Now(carbon.UTC).AddDuration(store.sessionTTL.String()).ToDateTimeString(carbon.UTC))
+	}
+
+	session.SetTokenHash(utils.StrToSHA256Hash(token))
+	session.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	session.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	session.SetLastSeenAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.sessionTableName).
+		Prepared(true).
+		Rows(session.Data()).
+		ToSQL()
+
+	if errSql != nil {
+		return "", errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err = database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return "", err
+	}
+
+	session.MarkAsNotDirty()
+
+	return token, nil
+}
+
+// UserSessionFindByID returns the session with the specified ID,
+// or nil if not found, expired or revoked
+func (store *store) UserSessionFindByID(ctx context.Context, sessionID string) (SessionInterface, error) {
+	if sessionID == "" {
+		return nil, errors.New("session id is empty")
+	}
+
+	return store.sessionFind(ctx, goqu.C(COLUMN_ID).Eq(sessionID))
+}
+
+// UserSessionFindByToken returns the session with the specified token,
+// as returned by UserSessionCreate, or nil if not found, expired or revoked
+func (store *store) UserSessionFindByToken(ctx context.Context, token string) (SessionInterface, error) {
+	if token == "" {
+		return nil, errors.New("session token is empty")
+	}
+
+	return store.sessionFind(ctx, goqu.C(COLUMN_TOKEN_HASH).Eq(utils.StrToSHA256Hash(token)))
+}
+
+// UserSessionList returns the active sessions of the user,
+// the most recently seen first
+func (store *store) UserSessionList(ctx context.Context, userID string) ([]SessionInterface, error) {
+	if userID == "" {
+		return nil, errors.New("user id is empty")
+	}
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.sessionTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Where(goqu.C(COLUMN_EXPIRES_AT).Gt(now)).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(now)).
+		Order(goqu.C(COLUMN_LAST_SEEN_AT).Desc()).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := []SessionInterface{}
+
+	for _, modelMap := range modelMaps {
+		list = append(list, NewSessionFromExistingData(modelMap))
+	}
+
+	return list, nil
+}
+
+// UserSessionRevoke revokes the session, it cannot be used anymore
+func (store *store) UserSessionRevoke(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return errors.New("session id is empty")
+	}
+
+	return store.sessionsRevoke(ctx, goqu.C(COLUMN_ID).Eq(sessionID))
+}
+
+// UserSessionRevokeAllExcept revokes all the sessions of the user, except
+// the specified one (i.e. "log out everywhere else"). If exceptSessionID
+// is empty, all the sessions are revoked.
+func (store *store) UserSessionRevokeAllExcept(ctx context.Context, userID string, exceptSessionID string) error {
+	if userID == "" {
+		return errors.New("user id is empty")
+	}
+
+	return store.sessionsRevokeByUserID(ctx, userID, exceptSessionID)
+}
+
+// UserSessionTouch marks the session as seen now.
+//
+// Returns ErrSessionNotFound, if the session does not exist,
+// is expired or revoked.
+func (store *store) UserSessionTouch(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return errors.New("session id is empty")
+	}
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.sessionTableName).
+		Prepared(true).
+		Set(map[string]any{
+			COLUMN_LAST_SEEN_AT: now,
+			COLUMN_UPDATED_AT:   now,
+		}).
+		Where(goqu.C(COLUMN_ID).Eq(sessionID)).
+		Where(goqu.C(COLUMN_EXPIRES_AT).Gt(now)).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(now)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected < 1 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// sessionFind returns the first session matching the condition,
+// or nil if not found, expired or revoked
+func (store *store) sessionFind(ctx context.Context, condition goqu.Expression) (SessionInterface, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.sessionTableName).
+		Prepared(true).
+		Where(condition).
+		Limit(1).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(modelMaps) < 1 {
+		return nil, nil
+	}
+
+	session := NewSessionFromExistingData(modelMaps[0])
+
+	if !session.IsActive() {
+		return nil, nil
+	}
+
+	return session, nil
+}
+
+// sessionsRevokeByUserID revokes the active sessions of the user,
+// except the specified one, if not empty
+func (store *store) sessionsRevokeByUserID(ctx context.Context, userID string, exceptSessionID string) error {
+	conditions := []goqu.Expression{goqu.C(COLUMN_USER_ID).Eq(userID)}
+
+	if exceptSessionID != "" {
+		conditions = append(conditions, goqu.C(COLUMN_ID).Neq(exceptSessionID))
+	}
+
+	return store.sessionsRevoke(ctx, conditions...)
+}
+
+func (store *store) sessionsRevoke(ctx context.Context, conditions ...goqu.Expression) error {
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.sessionTableName).
+		Prepared(true).
+		Set(map[string]any{
+			COLUMN_SOFT_DELETED_AT: now,
+			COLUMN_UPDATED_AT:      now,
+		}).
+		Where(conditions...).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(now)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
@@ -0,0 +1,344 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func TestStoreUserSessionCreate(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	session := NewSession().
+		SetUserID("USER_ID").
+		SetIPAddress("127.0.0.1").
+		SetUserAgent("Mozilla/5.0").
+		SetDevice("Firefox on Linux").
+		SetExpiresAt(carbon.Now(carbon.UTC).AddDay().ToDateTimeString(carbon.UTC))
+
+	token, err := store.UserSessionCreate(context.Background(), session)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(token) != sessionTokenLength {
+		t.Fatal("Token length MUST be", sessionTokenLength, "found:", len(token))
+	}
+
+	if session.TokenHash() == token {
+		t.Fatal("Token MUST NOT be stored as it is")
+	}
+
+	sessionFound, err := store.UserSessionFindByID(context.Background(), session.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if sessionFound == nil {
+		t.Fatal("Session MUST NOT be nil")
+	}
+
+	if sessionFound.UserID() != "USER_ID" {
+		t.Fatal("User ID MUST be USER_ID, found:", sessionFound.UserID())
+	}
+
+	if sessionFound.IPAddress() != "127.0.0.1" {
+		t.Fatal("IP address MUST be 127.0.0.1, found:", sessionFound.IPAddress())
+	}
+
+	if sessionFound.Device() != "Firefox on Linux" {
+		t.Fatal("Device MUST be Firefox on Linux, found:", sessionFound.Device())
+	}
+
+	if !sessionFound.IsActive() {
+		t.Fatal("Session MUST be active")
+	}
+}
+
+func TestStoreUserSessionFindByToken(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	session := NewSession().SetUserID("USER_ID")
+
+	token, err := store.UserSessionCreate(context.Background(), session)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the session expires after the default session TTL of 30 days
+	if session.ExpiresAtCarbon().DiffInDays(carbon.Now(carbon.UTC)) != -30 {
+		t.Fatal("Session MUST expire in 30 days, found:", session.ExpiresAt())
+	}
+
+	sessionFound, err := store.UserSessionFindByToken(context.Background(), token)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if sessionFound == nil {
+		t.Fatal("Session MUST NOT be nil")
+	}
+
+	if sessionFound.ID() != session.ID() {
+		t.Fatal("Session ID MUST be", session.ID(), "found:", sessionFound.ID())
+	}
+
+	// the session ID is not a token
+	sessionFound, err = store.UserSessionFindByToken(context.Background(), session.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if sessionFound != nil {
+		t.Fatal("Session MUST be nil, when found by its ID")
+	}
+
+	if err := store.UserSessionRevoke(context.Background(), session.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	sessionFound, err = store.UserSessionFindByToken(context.Background(), token)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if sessionFound != nil {
+		t.Fatal("Revoked session MUST be nil")
+	}
+}
+
+func TestStoreUserSessionTouch(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	carbon.SetTestNow(carbon.Now(carbon.UTC).SubHour())
+
+	session := NewSession().
+		SetUserID("USER_ID").
+		SetExpiresAt(carbon.Now(carbon.UTC).AddMinutes(90).ToDateTimeString(carbon.UTC))
+
+	_, err = store.UserSessionCreate(context.Background(), session)
+
+	carbon.ClearTestNow()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSessionTouch(context.Background(), session.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	sessionFound, err := store.UserSessionFindByID(context.Background(), session.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if sessionFound.LastSeenAtCarbon().Lt(carbon.Now(carbon.UTC).SubMinute()) {
+		t.Fatal("Last seen MUST be updated, found:", sessionFound.LastSeenAt())
+	}
+
+	// expired sessions cannot be touched
+	carbon.SetTestNow(carbon.Now(carbon.UTC).AddHour())
+	defer carbon.ClearTestNow()
+
+	err = store.UserSessionTouch(context.Background(), session.ID())
+
+	if !errors.Is(err, ErrSessionNotFound) {
+		t.Fatal("Error MUST be ErrSessionNotFound, found:", err)
+	}
+
+	sessionFound, err = store.UserSessionFindByID(context.Background(), session.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if sessionFound != nil {
+		t.Fatal("Expired session MUST be nil")
+	}
+}
+
+func TestStoreUserSessionRevoke(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	sessions := []SessionInterface{}
+
+	for i := 0; i < 3; i++ {
+		session := NewSession().SetUserID("USER_ID")
+
+		if _, err := store.UserSessionCreate(context.Background(), session); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if err := store.UserSessionRevoke(context.Background(), sessions[0].ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserSessionTouch(context.Background(), sessions[0].ID())
+
+	if !errors.Is(err, ErrSessionNotFound) {
+		t.Fatal("Error MUST be ErrSessionNotFound, found:", err)
+	}
+
+	list, err := store.UserSessionList(context.Background(), "USER_ID")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 2 {
+		t.Fatal("Sessions MUST be 2, found:", len(list))
+	}
+
+	// log out everywhere else
+	if err := store.UserSessionRevokeAllExcept(context.Background(), "USER_ID", sessions[2].ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	list, err = store.UserSessionList(context.Background(), "USER_ID")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 1 {
+		t.Fatal("Sessions MUST be 1, found:", len(list))
+	}
+
+	if list[0].ID() != sessions[2].ID() {
+		t.Fatal("The current session MUST be kept")
+	}
+}
+
+func TestStoreUserSessionRevokedOnPasswordChangeAndSoftDelete(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("test@test.com").SetStatus(USER_STATUS_ACTIVE)
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	sessionCount := func() int {
+		list, err := store.UserSessionList(context.Background(), user.ID())
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		return len(list)
+	}
+
+	if _, err := store.UserSessionCreate(context.Background(), NewSession().SetUserID(user.ID())); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// other changes keep the sessions
+	user.SetFirstName("John")
+
+	if err := store.UserUpdate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if sessionCount() != 1 {
+		t.Fatal("Sessions MUST be 1, found:", sessionCount())
+	}
+
+	if err := user.SetPasswordAndHash("new_password"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserUpdate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if sessionCount() != 0 {
+		t.Fatal("Sessions MUST be revoked on password change, found:", sessionCount())
+	}
+
+	if _, err := store.UserSessionCreate(context.Background(), NewSession().SetUserID(user.ID())); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSoftDelete(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if sessionCount() != 0 {
+		t.Fatal("Sessions MUST be revoked on soft delete, found:", sessionCount())
+	}
+
+	if _, err := store.UserSessionCreate(context.Background(), NewSession().SetUserID(user.ID())); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserDeleteByID(context.Background(), user.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if sessionCount() != 0 {
+		t.Fatal("Sessions MUST be revoked on delete, found:", sessionCount())
+	}
+}
@@ -0,0 +1,213 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/uid"
+	"github.com/samber/lo"
+)
+
+// UserStatus describes a status in the registry of the user statuses
+type UserStatus struct {
+	// Key is the value stored in the status column
+	Key string
+
+	// Label is the name of the status shown to the humans
+	Label string
+
+	// CanLogin is whether the users with the status can log in
+	CanLogin bool
+}
+
+// DefaultStatuses returns the registry of the USER_STATUS_ constants,
+// only the active users can log in
+func DefaultStatuses() []UserStatus {
+	return []UserStatus{
+		{Key: USER_STATUS_ACTIVE, Label: "Active", CanLogin: true},
+		{Key: USER_STATUS_UNVERIFIED, Label: "Unverified"},
+		{Key: USER_STATUS_INACTIVE, Label: "Inactive"},
+		{Key: USER_STATUS_DELETED, Label: "Deleted"},
+	}
+}
+
+// StatusTransitions defines the allowed changes of the user status,
+// as status => the statuses it can change to. The STATUS_TRANSITION_ANY
+// key defines the statuses any status can change to.
+//
+// The statuses in the transitions must be in the registry of the statuses.
+type StatusTransitions map[string][]string
+
+// STATUS_TRANSITION_ANY is the key of the transitions allowed from any status
+const STATUS_TRANSITION_ANY = "*"
+
+// DefaultStatusTransitions returns the default transitions: an unverified
+// user can be activated or deactivated, an active one deactivated and
+// an inactive one reactivated. Any user can be deleted, and a deleted
+// user cannot change its status.
+func DefaultStatusTransitions() StatusTransitions {
+	return StatusTransitions{
+		USER_STATUS_UNVERIFIED: {USER_STATUS_ACTIVE, USER_STATUS_INACTIVE},
+		USER_STATUS_ACTIVE:     {USER_STATUS_INACTIVE},
+		USER_STATUS_INACTIVE:   {USER_STATUS_ACTIVE},
+		STATUS_TRANSITION_ANY:  {USER_STATUS_DELETED},
+	}
+}
+
+// IsAllowed returns whether the status can change from one to the other
+func (transitions StatusTransitions) IsAllowed(from string, to string) bool {
+	if !transitions.IsValid(from) || !transitions.IsValid(to) {
+		return false
+	}
+
+	return lo.Contains(transitions[from], to) ||
+		lo.Contains(transitions[STATUS_TRANSITION_ANY], to)
+}
+
+// IsValid returns whether the status is one of the statuses in the transitions
+func (transitions StatusTransitions) IsValid(status string) bool {
+	return status != "" && status != STATUS_TRANSITION_ANY && lo.Contains(transitions.Statuses(), status)
+}
+
+// Statuses returns the statuses in the transitions, sorted
+func (transitions StatusTransitions) Statuses() []string {
+	statuses := []string{}
+
+	for from, tos := range transitions {
+		if from != STATUS_TRANSITION_ANY {
+			statuses = append(statuses, from)
+		}
+
+		statuses = append(statuses, tos...)
+	}
+
+	statuses = lo.Uniq(statuses)
+	slices.Sort(statuses)
+
+	return statuses
+}
+
+// Statuses returns the registry of the user statuses, in the configured order
+func (store *store) Statuses() []UserStatus {
+	return slices.Clone(store.statuses)
+}
+
+// UserCanLogin returns whether the user can log in, i.e. the status
+// of the user allows it, and the user is neither locked, suspended
+// nor soft deleted
+func (store *store) UserCanLogin(user UserInterface) bool {
+	if user == nil || user.IsLocked() || user.IsSuspended() || user.IsSoftDeleted() {
+		return false
+	}
+
+	status, found := store.statusFind(user.Status())
+
+	return found && status.CanLogin
+}
+
+// UserStatusHistory returns the status transitions of the user, the newest first
+func (store *store) UserStatusHistory(ctx context.Context, userID string) ([]StatusHistoryInterface, error) {
+	if userID == "" {
+		return nil, errors.New("user id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.statusHistoryTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Order(goqu.C(COLUMN_CREATED_AT).Desc(), goqu.C(COLUMN_ID).Desc()).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := []StatusHistoryInterface{}
+
+	for _, modelMap := range modelMaps {
+		list = append(list, NewStatusHistoryFromExistingData(modelMap))
+	}
+
+	return list, nil
+}
+
+// UserTransitionStatus changes the status of the user, and records the
+// transition in the status history with the reason and the actor from
+// the context. The user is updated, together with any other changes.
+//
+// The transition is checked against the stored status of the user.
+// Returns ErrStatusInvalid for an unknown status, and
+// ErrStatusTransitionNotAllowed if the transition is not allowed.
+// Changing to the current status records no transition.
+func (store *store) UserTransitionStatus(ctx context.Context, user UserInterface, newStatus string, reason string) error {
+	if user == nil {
+		return errors.New("at user transition status > user is nil")
+	}
+
+	if _, found := store.statusFind(newStatus); !found {
+		return ErrStatusInvalid
+	}
+
+	oldStatus := user.Status()
+
+	user.SetStatus(newStatus)
+
+	err := store.UserUpdate(withStatusReason(ctx, reason), user)
+
+	if err != nil {
+		user.SetStatus(oldStatus)
+	}
+
+	return err
+}
+
+// statusHistoryCreate records the transition of the status of the user,
+// with the reason and the actor from the context
+func (store *store) statusHistoryCreate(ctx context.Context, userID string, fromStatus string, toStatus string) error {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.statusHistoryTableName).
+		Prepared(true).
+		Rows(map[string]string{
+			COLUMN_ID:          uid.HumanUid(),
+			COLUMN_USER_ID:     userID,
+			COLUMN_FROM_STATUS: fromStatus,
+			COLUMN_TO_STATUS:   toStatus,
+			COLUMN_REASON:      statusReasonFromContext(ctx),
+			COLUMN_ACTOR_ID:    ActorFromContext(ctx),
+			COLUMN_CREATED_AT:  carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		}).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+func (store *store) statusFind(key string) (UserStatus, bool) {
+	return lo.Find(store.statuses, func(status UserStatus) bool {
+		return status.Key == key
+	})
+}
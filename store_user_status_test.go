@@ -0,0 +1,374 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStatusTransitions(t *testing.T) {
+	transitions := DefaultStatusTransitions()
+
+	cases := []struct {
+		from    string
+		to      string
+		allowed bool
+	}{
+		{USER_STATUS_UNVERIFIED, USER_STATUS_ACTIVE, true},
+		{USER_STATUS_ACTIVE, USER_STATUS_INACTIVE, true},
+		{USER_STATUS_INACTIVE, USER_STATUS_ACTIVE, true},
+		{USER_STATUS_ACTIVE, USER_STATUS_DELETED, true},
+		{USER_STATUS_UNVERIFIED, USER_STATUS_DELETED, true},
+		{USER_STATUS_ACTIVE, USER_STATUS_UNVERIFIED, false},
+		{USER_STATUS_DELETED, USER_STATUS_ACTIVE, false},
+		{USER_STATUS_ACTIVE, "actve", false},
+		{"", USER_STATUS_ACTIVE, false},
+		{STATUS_TRANSITION_ANY, USER_STATUS_DELETED, false},
+	}
+
+	for _, c := range cases {
+		if transitions.IsAllowed(c.from, c.to) != c.allowed {
+			t.Fatal("Transition from", c.from, "to", c.to, "MUST be allowed:", c.allowed)
+		}
+	}
+
+	statuses := strings.Join(transitions.Statuses(), ",")
+
+	if statuses != "active,deleted,inactive,unverified" {
+		t.Fatal("Statuses MUST be active,deleted,inactive,unverified, found:", statuses)
+	}
+}
+
+func TestStoreUserTransitionStatus(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser()
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := WithActor(context.Background(), "admin_id")
+
+	err = store.UserTransitionStatus(ctx, user, USER_STATUS_ACTIVE, "Email verified")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserTransitionStatus(ctx, user, USER_STATUS_DELETED, "Requested by the user")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserTransitionStatus(ctx, user, USER_STATUS_ACTIVE, "")
+
+	if !errors.Is(err, ErrStatusTransitionNotAllowed) {
+		t.Fatal("Error MUST be ErrStatusTransitionNotAllowed, found:", err)
+	}
+
+	err = store.UserTransitionStatus(ctx, user, "actve", "")
+
+	if !errors.Is(err, ErrStatusInvalid) {
+		t.Fatal("Error MUST be ErrStatusInvalid, found:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.Status() != USER_STATUS_DELETED {
+		t.Fatal("Status MUST be deleted, found:", userFound.Status())
+	}
+
+	history, err := store.UserStatusHistory(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(history) != 2 {
+		t.Fatal("History MUST be 2, found:", len(history))
+	}
+
+	// both are likely created in the same second, so the order is not checked
+	found, ok := lookupStatusHistory(history, USER_STATUS_ACTIVE, USER_STATUS_DELETED)
+
+	if !ok {
+		t.Fatal("History MUST contain active to deleted")
+	}
+
+	if found.Reason() != "Requested by the user" {
+		t.Fatal("Reason MUST be stored, found:", found.Reason())
+	}
+
+	if found.ActorID() != "admin_id" {
+		t.Fatal("Actor MUST be admin_id, found:", found.ActorID())
+	}
+
+	if _, ok := lookupStatusHistory(history, USER_STATUS_UNVERIFIED, USER_STATUS_ACTIVE); !ok {
+		t.Fatal("History MUST contain unverified to active")
+	}
+}
+
+func TestStoreUserUpdateStatusTransition(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetStatus(USER_STATUS_ACTIVE)
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := WithActor(context.Background(), "admin_id")
+
+	err = store.UserUpdate(ctx, user.SetStatus(USER_STATUS_UNVERIFIED))
+
+	if !errors.Is(err, ErrStatusTransitionNotAllowed) {
+		t.Fatal("Error MUST be ErrStatusTransitionNotAllowed, found:", err)
+	}
+
+	if err := store.UserUpdate(ctx, user.SetStatus(USER_STATUS_INACTIVE)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// other changes do not record a transition
+	if err := store.UserUpdate(ctx, user.SetFirstName("John")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	history, err := store.UserStatusHistory(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(history) != 1 {
+		t.Fatal("History MUST be 1, found:", len(history))
+	}
+
+	if history[0].FromStatus() != USER_STATUS_ACTIVE || history[0].ToStatus() != USER_STATUS_INACTIVE {
+		t.Fatal("History MUST contain active to inactive, found:", history[0].Data())
+	}
+
+	if history[0].ActorID() != "admin_id" {
+		t.Fatal("Actor MUST be admin_id, found:", history[0].ActorID())
+	}
+}
+
+func TestStoreUserCreateStatusInvalid(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	err = store.UserCreate(context.Background(), NewUser().SetStatus("actve"))
+
+	if !errors.Is(err, ErrStatusInvalid) {
+		t.Fatal("Error MUST be ErrStatusInvalid, found:", err)
+	}
+}
+
+func TestStoreUserTransitionStatusCustom(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		Statuses: append(DefaultStatuses(), UserStatus{
+			Key:   "pending_approval",
+			Label: "Pending Approval",
+		}),
+		StatusTransitions: StatusTransitions{
+			USER_STATUS_UNVERIFIED: {"pending_approval"},
+			"pending_approval":     {USER_STATUS_ACTIVE},
+		},
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser()
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserTransitionStatus(context.Background(), user, USER_STATUS_ACTIVE, "")
+
+	if !errors.Is(err, ErrStatusTransitionNotAllowed) {
+		t.Fatal("Error MUST be ErrStatusTransitionNotAllowed, found:", err)
+	}
+
+	if err := store.UserTransitionStatus(context.Background(), user, "pending_approval", ""); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserTransitionStatus(context.Background(), user, USER_STATUS_ACTIVE, ""); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func lookupStatusHistory(history []StatusHistoryInterface, from string, to string) (StatusHistoryInterface, bool) {
+	for _, entry := range history {
+		if entry.FromStatus() == from && entry.ToStatus() == to {
+			return entry, true
+		}
+	}
+
+	return nil, false
+}
+
+func TestStoreStatuses(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		Statuses: append(DefaultStatuses(),
+			UserStatus{Key: "suspended", Label: "Suspended"},
+			UserStatus{Key: "trial", Label: "Trial", CanLogin: true},
+		),
+		StatusTransitions: StatusTransitions{
+			"trial":     {"suspended", USER_STATUS_ACTIVE},
+			"suspended": {USER_STATUS_ACTIVE},
+		},
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if len(store.Statuses()) != 6 || store.Statuses()[5].Label != "Trial" {
+		t.Fatal("Statuses MUST be the registry, found:", store.Statuses())
+	}
+
+	ctx := context.Background()
+
+	trial := NewUser().SetStatus("trial")
+
+	if err := store.UserCreate(ctx, trial); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !store.UserCanLogin(trial) {
+		t.Fatal("Trial user MUST be able to log in")
+	}
+
+	if err := store.UserUpdate(ctx, trial.SetStatus("suspended")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if store.UserCanLogin(trial) {
+		t.Fatal("Suspended user MUST NOT be able to log in")
+	}
+
+	err = store.UserUpdate(ctx, trial.SetStatus("suspnded"))
+
+	if !errors.Is(err, ErrStatusInvalid) {
+		t.Fatal("Error MUST be ErrStatusInvalid, found:", err)
+	}
+
+	// the unknown statuses match no users, i.e. the statuses removed from the registry
+	list, err := store.UserList(ctx, NewUserQuery().SetStatus("suspnded"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 0 {
+		t.Fatal("Users MUST be 0, found:", len(list))
+	}
+
+	list, err = store.UserList(ctx, NewUserQuery().SetStatus("suspended"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 1 {
+		t.Fatal("Suspended users MUST be 1, found:", len(list))
+	}
+}
+
+func TestStoreStatusesInvalid(t *testing.T) {
+	cases := []NewStoreOptions{
+		{Statuses: []UserStatus{}},
+		{Statuses: []UserStatus{{Key: ""}}},
+		{Statuses: append(DefaultStatuses(), UserStatus{Key: USER_STATUS_ACTIVE})},
+		{Statuses: []UserStatus{{Key: USER_STATUS_ACTIVE}}},
+	}
+
+	for i, opts := range cases {
+		db, err := initDB(":memory:")
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		opts.DB = db
+		opts.UserTableName = "user_table"
+
+		if _, err := NewStore(opts); err == nil {
+			t.Fatal("Case", i, "MUST fail")
+		}
+
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
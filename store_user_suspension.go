@@ -0,0 +1,101 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+)
+
+// UserReinstateExpiredSuspensions lifts the suspensions, which have
+// expired, so that no stale suspension data is left on the users.
+// It is meant to be run periodically (i.e. by a cron job).
+//
+// Returns the number of the reinstated users.
+func (store *store) UserReinstateExpiredSuspensions(ctx context.Context) (int, error) {
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.userTableName).
+		Prepared(true).
+		Select(COLUMN_ID).
+		Where(goqu.C(COLUMN_SUSPENDED_UNTIL).Gt(sb.NULL_DATETIME)).
+		Where(goqu.C(COLUMN_SUSPENDED_UNTIL).Lte(now)).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(now)).
+		ToSQL()
+
+	if errSql != nil {
+		return 0, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	reinstated := 0
+
+	for _, modelMap := range modelMaps {
+		user, err := store.UserFindByID(ctx, modelMap[COLUMN_ID])
+
+		if err != nil {
+			return reinstated, err
+		}
+
+		if user == nil || user.IsSuspended() {
+			continue
+		}
+
+		if err := store.UserSuspensionLift(ctx, user); err != nil {
+			return reinstated, err
+		}
+
+		reinstated++
+	}
+
+	return reinstated, nil
+}
+
+// UserSuspend suspends the user until the specified datetime (UTC),
+// recording the reason and the actor from the context. Use
+// sb.MAX_DATETIME for a suspension, which does not expire.
+//
+// Returns ErrSuspensionUntilInvalid if the datetime is not in the future.
+func (store *store) UserSuspend(ctx context.Context, user UserInterface, until string, reason string) error {
+	if user == nil {
+		return errors.New("at user suspend > user is nil")
+	}
+
+	if !carbon.Parse(until, carbon.UTC).Compare(">", carbon.Now(carbon.UTC)) {
+		return ErrSuspensionUntilInvalid
+	}
+
+	user.SetSuspendedUntil(carbon.Parse(until, carbon.UTC).ToDateTimeString(carbon.UTC))
+	user.SetSuspendedBy(ActorFromContext(ctx))
+	user.SetSuspensionReason(reason)
+
+	return store.UserUpdate(ctx, user)
+}
+
+// UserSuspensionLift lifts the suspension of the user, and clears
+// the suspension reason and the suspending actor
+func (store *store) UserSuspensionLift(ctx context.Context, user UserInterface) error {
+	if user == nil {
+		return errors.New("at user suspension lift > user is nil")
+	}
+
+	user.SetSuspendedUntil(sb.NULL_DATETIME)
+	user.SetSuspendedBy("")
+	user.SetSuspensionReason("")
+
+	return store.UserUpdate(ctx, user)
+}
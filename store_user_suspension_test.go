@@ -0,0 +1,248 @@
+package userstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/sb"
+)
+
+func TestStoreUserSuspend(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+		carbon.ClearTestNow()
+	}()
+
+	carbon.SetTestNow(carbon.Parse("2025-01-01 10:00:00", carbon.UTC))
+
+	user := NewUser().SetStatus(USER_STATUS_ACTIVE)
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if user.IsSuspended() {
+		t.Fatal("User MUST NOT be suspended")
+	}
+
+	ctx := WithActor(context.Background(), "admin_id")
+
+	err = store.UserSuspend(ctx, user, "2025-01-01 09:00:00", "Spam")
+
+	if err != ErrSuspensionUntilInvalid {
+		t.Fatal("Error MUST be ErrSuspensionUntilInvalid, found:", err)
+	}
+
+	err = store.UserSuspend(ctx, user, "2025-01-08 10:00:00", "Spam")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !userFound.IsSuspended() {
+		t.Fatal("User MUST be suspended")
+	}
+
+	if store.UserCanLogin(userFound) {
+		t.Fatal("Suspended user MUST NOT be able to log in")
+	}
+
+	if userFound.SuspendedUntilCarbon().ToDateTimeString(carbon.UTC) != "2025-01-08 10:00:00" {
+		t.Fatal("Suspended until MUST be 2025-01-08 10:00:00, found:", userFound.SuspendedUntilCarbon().ToDateTimeString(carbon.UTC))
+	}
+
+	if userFound.SuspendedBy() != "admin_id" {
+		t.Fatal("Suspended by MUST be admin_id, found:", userFound.SuspendedBy())
+	}
+
+	if userFound.SuspensionReason() != "Spam" {
+		t.Fatal("Suspension reason MUST be Spam, found:", userFound.SuspensionReason())
+	}
+
+	carbon.SetTestNow(carbon.Parse("2025-01-08 10:00:01", carbon.UTC))
+
+	if userFound.IsSuspended() {
+		t.Fatal("User MUST NOT be suspended after the suspension expired")
+	}
+
+	if !store.UserCanLogin(userFound) {
+		t.Fatal("User MUST be able to log in after the suspension expired")
+	}
+}
+
+func TestStoreUserSuspendIndefinitely(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser()
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSuspend(context.Background(), user, sb.MAX_DATETIME, "Fraud"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !user.IsSuspended() {
+		t.Fatal("User MUST be suspended")
+	}
+
+	reinstated, err := store.UserReinstateExpiredSuspensions(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if reinstated != 0 {
+		t.Fatal("Reinstated MUST be 0, found:", reinstated)
+	}
+}
+
+func TestStoreUserSuspensionLift(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser()
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	until := carbon.Now(carbon.UTC).AddDays(1).ToDateTimeString(carbon.UTC)
+
+	if err := store.UserSuspend(context.Background(), user, until, "Spam"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSuspensionLift(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.IsSuspended() {
+		t.Fatal("User MUST NOT be suspended")
+	}
+
+	if userFound.SuspendedBy() != "" || userFound.SuspensionReason() != "" {
+		t.Fatal("Suspension data MUST be cleared, found:", userFound.SuspendedBy(), userFound.SuspensionReason())
+	}
+}
+
+func TestStoreUserReinstateExpiredSuspensions(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+		carbon.ClearTestNow()
+	}()
+
+	carbon.SetTestNow(carbon.Parse("2025-01-01 10:00:00", carbon.UTC))
+
+	expiring := NewUser()
+	ongoing := NewUser()
+
+	for _, user := range []UserInterface{expiring, ongoing} {
+		if err := store.UserCreate(context.Background(), user); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if err := store.UserSuspend(context.Background(), expiring, "2025-01-02 10:00:00", "Spam"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSuspend(context.Background(), ongoing, "2025-02-01 10:00:00", "Fraud"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	carbon.SetTestNow(carbon.Parse("2025-01-03 10:00:00", carbon.UTC))
+
+	reinstated, err := store.UserReinstateExpiredSuspensions(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if reinstated != 1 {
+		t.Fatal("Reinstated MUST be 1, found:", reinstated)
+	}
+
+	expiringFound, err := store.UserFindByID(context.Background(), expiring.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if expiringFound.SuspendedUntilCarbon().ToDateTimeString(carbon.UTC) != sb.NULL_DATETIME {
+		t.Fatal("Suspended until MUST be cleared, found:", expiringFound.SuspendedUntilCarbon().ToDateTimeString(carbon.UTC))
+	}
+
+	if expiringFound.SuspensionReason() != "" {
+		t.Fatal("Suspension reason MUST be cleared, found:", expiringFound.SuspensionReason())
+	}
+
+	ongoingFound, err := store.UserFindByID(context.Background(), ongoing.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !ongoingFound.IsSuspended() {
+		t.Fatal("Ongoing suspension MUST NOT be lifted")
+	}
+
+	reinstated, err = store.UserReinstateExpiredSuspensions(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if reinstated != 0 {
+		t.Fatal("Reinstated MUST be 0 on the second run, found:", reinstated)
+	}
+}
@@ -2,9 +2,14 @@ package userstore
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/dromara/carbon/v2"
 	"github.com/gouniverse/base/database"
 	"github.com/gouniverse/sb"
 )
@@ -318,6 +323,190 @@ func TestStoreUserFindByEmail(t *testing.T) {
 	}
 }
 
+func TestStoreUserFindByEmailNormalized(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail(" Test@Test.com ")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if user.Email() != "Test@Test.com" {
+		t.Fatal("Email MUST be trimmed, found:", user.Email())
+	}
+
+	userFound, err := store.UserFindByEmail(context.Background(), "test@test.com ")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound == nil || userFound.ID() != user.ID() {
+		t.Fatal("User MUST be found by the email in another case")
+	}
+
+	err = store.UserCreate(context.Background(), NewUser().SetEmail("TEST@test.com"))
+
+	if err == nil {
+		t.Fatal("Email MUST be unique regardless of its case")
+	}
+}
+
+func TestStoreUserFindByEmailOrCreate(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user, err := store.UserFindByEmailOrCreate(context.Background(), "test@test.com", USER_STATUS_UNVERIFIED)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if user == nil {
+		t.Fatal("User MUST NOT be nil")
+	}
+
+	if user.Status() != USER_STATUS_UNVERIFIED {
+		t.Fatal("Status MUST be unverified, found:", user.Status())
+	}
+
+	userAgain, err := store.UserFindByEmailOrCreate(context.Background(), "test@test.com", USER_STATUS_ACTIVE)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userAgain.ID() != user.ID() {
+		t.Fatal("The same user MUST be returned")
+	}
+
+	// the unique email index rejects duplicates, which bypass the check
+	err = store.UserCreate(context.Background(), NewUser().SetEmail("test@test.com"))
+
+	if err == nil {
+		t.Fatal("Error MUST NOT be nil for a duplicate email")
+	}
+
+	// soft deleted users and users without email are not constrained
+	if err := store.UserSoftDelete(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserCreate(context.Background(), NewUser().SetEmail("test@test.com")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserCreate(context.Background(), NewUser()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserCreate(context.Background(), NewUser()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestStoreUserFindByEmailOrCreateConcurrent(t *testing.T) {
+	// a file database, so the goroutines use separate connections,
+	// waiting for each other's write locks
+	dbPath := filepath.Join(t.TempDir(), "test_user_find_by_email_or_create.db")
+
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(10000)")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	const goroutines = 20
+
+	ids := make(chan string, goroutines)
+	errs := make(chan error, goroutines)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			user, err := store.UserFindByEmailOrCreate(context.Background(), "test@test.com", USER_STATUS_ACTIVE)
+
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			ids <- user.ID()
+		}()
+	}
+
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userIDs := map[string]bool{}
+
+	for id := range ids {
+		userIDs[id] = true
+	}
+
+	if len(userIDs) != 1 {
+		t.Fatal("All the calls MUST return the same user, found:", len(userIDs))
+	}
+
+	count, err := store.UserCount(context.Background(), NewUserQuery().SetEmail("test@test.com"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("Users MUST be 1, found:", count)
+	}
+}
+
 func TestStoreUserFindByID(t *testing.T) {
 	store, err := initStore(":memory:")
 
@@ -475,6 +664,262 @@ func TestStoreUserList(t *testing.T) {
 	}
 }
 
+func TestStoreUserListOnlySoftDeleted(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+		carbon.ClearTestNow()
+	}()
+
+	carbon.SetTestNow(carbon.Parse("2025-01-01 10:00:00", carbon.UTC))
+
+	userActive := NewUser()
+	userDeletedJanuary := NewUser()
+	userDeletedFebruary := NewUser()
+
+	for _, user := range []UserInterface{userActive, userDeletedJanuary, userDeletedFebruary} {
+		if err := store.UserCreate(context.Background(), user); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	carbon.SetTestNow(carbon.Parse("2025-01-15 10:00:00", carbon.UTC))
+
+	if err := store.UserSoftDelete(context.Background(), userDeletedJanuary); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	carbon.SetTestNow(carbon.Parse("2025-02-15 10:00:00", carbon.UTC))
+
+	if err := store.UserSoftDelete(context.Background(), userDeletedFebruary); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	carbon.SetTestNow(carbon.Parse("2025-03-01 10:00:00", carbon.UTC))
+
+	list, err := store.UserList(context.Background(), NewUserQuery().
+		SetOnlySoftDeleted(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 2 {
+		t.Fatal("Soft deleted users MUST be 2, found:", len(list))
+	}
+
+	for _, user := range list {
+		if !user.IsSoftDeleted() {
+			t.Fatal("User MUST be soft deleted:", user.ID())
+		}
+	}
+
+	// only soft deleted takes precedence over soft deleted included
+	count, err := store.UserCount(context.Background(), NewUserQuery().
+		SetOnlySoftDeleted(true).
+		SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("Soft deleted users MUST be 2, found:", count)
+	}
+
+	list, err = store.UserList(context.Background(), NewUserQuery().
+		SetOnlySoftDeleted(true).
+		SetSoftDeletedAtGte("2025-02-01 00:00:00").
+		SetSoftDeletedAtLte("2025-02-28 23:59:59"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 1 {
+		t.Fatal("Users soft deleted in February MUST be 1, found:", len(list))
+	}
+
+	if list[0].ID() != userDeletedFebruary.ID() {
+		t.Fatal("User MUST be the one deleted in February, found:", list[0].ID())
+	}
+
+	_, err = store.UserList(context.Background(), NewUserQuery().
+		SetSoftDeletedAtLte(""))
+
+	if err == nil {
+		t.Fatal("Error MUST be returned for an empty soft_deleted_at_lte")
+	}
+}
+
+func TestStoreUserRestore(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSoftDelete(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := WithActor(context.Background(), "admin_id")
+
+	if err := store.UserRestore(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound == nil {
+		t.Fatal("User MUST be restored")
+	}
+
+	if userFound.IsSoftDeleted() {
+		t.Fatal("User MUST NOT be soft deleted")
+	}
+
+	audits, err := store.AuditList(context.Background(), NewAuditQuery().
+		SetEntityID(user.ID()).
+		SetAction(AUDIT_ACTION_RESTORE))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(audits) != 1 {
+		t.Fatal("Restore audits MUST be 1, found:", len(audits))
+	}
+
+	if audits[0].ActorID() != "admin_id" {
+		t.Fatal("Audit actor MUST be admin_id, found:", audits[0].ActorID())
+	}
+
+	// restoring a user, which is not soft deleted, does nothing
+	if err := store.UserRestore(ctx, userFound); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	audits, err = store.AuditList(context.Background(), NewAuditQuery().
+		SetEntityID(user.ID()).
+		SetAction(AUDIT_ACTION_RESTORE))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(audits) != 1 {
+		t.Fatal("Restore audits MUST still be 1, found:", len(audits))
+	}
+}
+
+func TestStoreUserRestoreByID(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSoftDeleteByID(context.Background(), user.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserRestoreByID(context.Background(), "not_existing"); err != ErrUserNotFound {
+		t.Fatal("Error MUST be ErrUserNotFound, found:", err)
+	}
+
+	if err := store.UserRestoreByID(context.Background(), user.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound == nil {
+		t.Fatal("User MUST be restored")
+	}
+}
+
+func TestStoreUserRestoreEmailTaken(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	if err := store.UserCreate(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSoftDelete(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserCreate(context.Background(), NewUser().SetEmail("test@test.com")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserRestoreByID(context.Background(), user.ID()); err != ErrEmailTaken {
+		t.Fatal("Error MUST be ErrEmailTaken, found:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound != nil {
+		t.Fatal("User MUST remain soft deleted")
+	}
+}
+
 func TestStoreUserSoftDelete(t *testing.T) {
 	store, err := initStore(":memory:")
 
@@ -668,3 +1113,206 @@ func TestStoreUserMetaLike(t *testing.T) {
 		t.Errorf("Incorrect user returned, expected ID %s, but got %s", user.ID(), users[0].ID())
 	}
 }
+
+func TestStoreUserHooks(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	errBlocked := errors.New("blocked")
+	calls := []string{}
+
+	var store StoreInterface
+
+	store, err = NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		BeforeUserCreate: func(ctx context.Context, user UserInterface) error {
+			calls = append(calls, "before_create")
+
+			if user.Email() == "blocked@test.com" {
+				return errBlocked
+			}
+
+			return nil
+		},
+		AfterUserCreate: func(ctx context.Context, user UserInterface) error {
+			calls = append(calls, "after_create")
+
+			// runs inside the transaction, so the user is visible
+			found, err := store.UserFindByID(ctx, user.ID())
+
+			if err != nil {
+				return err
+			}
+
+			if found == nil {
+				return errors.New("user not found in after create")
+			}
+
+			return nil
+		},
+		BeforeUserUpdate: func(ctx context.Context, user UserInterface, dataChanged map[string]string) error {
+			calls = append(calls, "before_update")
+
+			if dataChanged[COLUMN_FIRST_NAME] == "Blocked" {
+				return errBlocked
+			}
+
+			return nil
+		},
+		AfterUserUpdate: func(ctx context.Context, user UserInterface, dataChanged map[string]string) error {
+			calls = append(calls, "after_update:"+dataChanged[COLUMN_FIRST_NAME])
+			return nil
+		},
+		BeforeUserDelete: func(ctx context.Context, user UserInterface) error {
+			calls = append(calls, "before_delete:"+user.FirstName())
+			return nil
+		},
+		AfterUserDelete: func(ctx context.Context, user UserInterface) error {
+			calls = append(calls, "after_delete:"+user.FirstName())
+			return nil
+		},
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	blocked := NewUser().SetEmail("blocked@test.com")
+
+	err = store.UserCreate(context.Background(), blocked)
+
+	if !errors.Is(err, errBlocked) {
+		t.Fatal("Error MUST be the before hook error, found:", err)
+	}
+
+	count, err := store.UserCount(context.Background(), NewUserQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 0 {
+		t.Fatal("User MUST NOT be created, found:", count)
+	}
+
+	user := NewUser().SetEmail("test@test.com").SetFirstName("John")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserUpdate(context.Background(), user.SetFirstName("Blocked"))
+
+	if !errors.Is(err, errBlocked) {
+		t.Fatal("Error MUST be the before hook error, found:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.FirstName() != "John" {
+		t.Fatal("User MUST NOT be updated, found:", userFound.FirstName())
+	}
+
+	err = store.UserUpdate(context.Background(), user.SetFirstName("Jane"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.UserDeleteByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := []string{
+		"before_create",
+		"before_create",
+		"after_create",
+		"before_update",
+		"before_update",
+		"after_update:Jane",
+		"before_delete:Jane",
+		"after_delete:Jane",
+	}
+
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Fatal("Calls MUST be", expected, "found:", calls)
+	}
+}
+
+func TestStoreUserHooksCallerTransaction(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		AfterUserCreate: func(ctx context.Context, user UserInterface) error {
+			if _, ok := ctx.(database.QueryableContext); !ok {
+				return errors.New("context MUST be queryable")
+			}
+
+			return errors.New("rejected")
+		},
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	user := NewUser().SetEmail("test@test.com")
+
+	err = store.UserCreate(database.Context(context.Background(), tx), user)
+
+	if err == nil || err.Error() != "rejected" {
+		t.Fatal("Error MUST be the after hook error, found:", err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound != nil {
+		t.Fatal("User MUST NOT be created, when the after hook fails")
+	}
+}
@@ -0,0 +1,285 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/uid"
+	"github.com/gouniverse/utils"
+)
+
+// The one-time tokens of all the flows (email verification, password
+// reset, etc) share the token table, told apart by the type column.
+// Only the hashes of the tokens are stored.
+
+const tokenAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+const tokenLength = 40
+
+// tokenCreate creates a new token of the specified type for the user,
+// valid for the specified duration, and returns it
+func (store *store) tokenCreate(ctx context.Context, userID string, tokenType string, payload string, ttl time.Duration) (string, error) {
+	token, err := randomFromAlphabet(tokenLength, tokenAlphabet)
+
+	if err != nil {
+		return "", err
+	}
+
+	err = store.tokenInsert(ctx, userID, tokenType, utils.StrToSHA256Hash(token), payload, ttl)
+
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// tokenInsert stores the token hash of the specified type for the user,
+// valid for the specified duration
+func (store *store) tokenInsert(ctx context.Context, userID string, tokenType string, tokenHash string, payload string, ttl time.Duration) error {
+	if userID == "" {
+		return errors.New("user id is empty")
+	}
+
+	if ttl <= 0 {
+		return errors.New("token ttl must be greater than 0")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.tokenTableName).
+		Prepared(true).
+		Rows(map[string]string{
+			COLUMN_ID:         uid.HumanUid(),
+			COLUMN_USER_ID:    userID,
+			COLUMN_TYPE:       tokenType,
+			COLUMN_TOKEN_HASH: tokenHash,
+			COLUMN_PAYLOAD:    payload,
+			COLUMN_ATTEMPTS:   "0",
+			COLUMN_EXPIRES_AT: carbon.Now(carbon.UTC).AddDuration(ttl.String()).ToDateTimeString(carbon.UTC),
+			COLUMN_CREATED_AT: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		}).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// tokenConsume validates the token of the specified type, and deletes it,
+// so it can be used only once.
+//
+// Returns the user ID and the payload the token was created with,
+// or ErrTokenInvalid if the token is not found or expired.
+func (store *store) tokenConsume(ctx context.Context, tokenType string, token string) (userID string, payload string, err error) {
+	if token == "" {
+		return "", "", ErrTokenInvalid
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.tokenTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_TYPE).Eq(tokenType)).
+		Where(goqu.C(COLUMN_TOKEN_HASH).Eq(utils.StrToSHA256Hash(token))).
+		Limit(1).
+		ToSQL()
+
+	if errSql != nil {
+		return "", "", errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(modelMaps) < 1 {
+		return "", "", ErrTokenInvalid
+	}
+
+	// the token is deleted even if expired, it is of no use anymore,
+	// unless the transaction of the caller is rolled back on the error,
+	// in which case the expired token stays, still being rejected
+	affected, err := store.tokenDeleteByID(ctx, modelMaps[0][COLUMN_ID])
+
+	if err != nil {
+		return "", "", err
+	}
+
+	// deleted by a concurrent request, which consumed it first
+	if affected < 1 {
+		return "", "", ErrTokenInvalid
+	}
+
+	expiresAt := carbon.Parse(modelMaps[0][COLUMN_EXPIRES_AT], carbon.UTC)
+
+	if expiresAt.Compare("<", carbon.Now(carbon.UTC)) {
+		return "", "", ErrTokenInvalid
+	}
+
+	return modelMaps[0][COLUMN_USER_ID], modelMaps[0][COLUMN_PAYLOAD], nil
+}
+
+func (store *store) tokenDeleteByID(ctx context.Context, id string) (int64, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.tokenTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_ID).Eq(id)).
+		ToSQL()
+
+	if errSql != nil {
+		return 0, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// tokenCountByUserID returns the number of unexpired tokens
+// of the specified type the user has
+func (store *store) tokenCountByUserID(ctx context.Context, userID string, tokenType string) (int64, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.tokenTableName).
+		Prepared(true).
+		Select(goqu.COUNT(goqu.Star()).As("count")).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Where(goqu.C(COLUMN_TYPE).Eq(tokenType)).
+		Where(goqu.C(COLUMN_EXPIRES_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))).
+		ToSQL()
+
+	if errSql != nil {
+		return -1, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	mapped, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return -1, err
+	}
+
+	if len(mapped) < 1 {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(mapped[0]["count"], 10, 64)
+}
+
+// tokenDeleteByUserID deletes all the tokens of the specified type the user has
+func (store *store) tokenDeleteByUserID(ctx context.Context, userID string, tokenType string) error {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.tokenTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Where(goqu.C(COLUMN_TYPE).Eq(tokenType)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// tokenFindByUserID returns the most recent unexpired token
+// of the specified type the user has, or nil if none
+func (store *store) tokenFindByUserID(ctx context.Context, userID string, tokenType string) (map[string]string, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.tokenTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_USER_ID).Eq(userID)).
+		Where(goqu.C(COLUMN_TYPE).Eq(tokenType)).
+		Where(goqu.C(COLUMN_EXPIRES_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))).
+		Order(goqu.C(COLUMN_CREATED_AT).Desc()).
+		Limit(1).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(modelMaps) < 1 {
+		return nil, nil
+	}
+
+	return modelMaps[0], nil
+}
+
+// tokenAttemptRecord counts an attempt to use the token, unless the
+// maximum number of attempts was reached. Returns false if it was.
+func (store *store) tokenAttemptRecord(ctx context.Context, id string, maxAttempts int) (bool, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.tokenTableName).
+		Prepared(true).
+		Set(goqu.Record{COLUMN_ATTEMPTS: goqu.L(COLUMN_ATTEMPTS + " + 1")}).
+		Where(goqu.C(COLUMN_ID).Eq(id)).
+		Where(goqu.C(COLUMN_ATTEMPTS).Lt(maxAttempts)).
+		ToSQL()
+
+	if errSql != nil {
+		return false, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
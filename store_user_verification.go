@@ -0,0 +1,86 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+)
+
+// UserVerificationTokenCreate creates a one-time token for verifying
+// the email address of the user, valid for the specified duration.
+//
+// The token is returned to be sent to the user (usually as a link),
+// only its hash is stored. The token verifies only the current email
+// of the user, it becomes invalid if the email changes.
+func (store *store) UserVerificationTokenCreate(ctx context.Context, userID string, ttl time.Duration) (string, error) {
+	if userID == "" {
+		return "", errors.New("user id is empty")
+	}
+
+	user, err := store.UserFindByID(ctx, userID)
+
+	if err != nil {
+		return "", err
+	}
+
+	if user == nil {
+		return "", ErrUserNotFound
+	}
+
+	if user.Email() == "" {
+		return "", errors.New("user email is empty")
+	}
+
+	return store.tokenCreate(ctx, userID, TOKEN_TYPE_EMAIL_VERIFICATION, user.Email(), ttl)
+}
+
+// UserVerifyEmail verifies the email address of the user the token
+// was created for, and invalidates the token.
+//
+// Unverified users become active, if the status transitions allow it,
+// the status of the other users is left as it is. Returns
+// ErrTokenInvalid if the token is not found or expired, or if
+// the email of the user changed since the token was created.
+func (store *store) UserVerifyEmail(ctx context.Context, token string) (UserInterface, error) {
+	var user UserInterface
+
+	err := store.withTransaction(ctx, func(txCtx database.QueryableContext) error {
+		userID, email, err := store.tokenConsume(txCtx, TOKEN_TYPE_EMAIL_VERIFICATION, token)
+
+		if err != nil {
+			return err
+		}
+
+		user, err = store.UserFindByID(txCtx, userID)
+
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return ErrUserNotFound
+		}
+
+		// the token was mailed to the email it was created for
+		if user.Email() != email {
+			return ErrTokenInvalid
+		}
+
+		if user.IsUnverified() && store.statusTransitions.IsAllowed(user.Status(), USER_STATUS_ACTIVE) {
+			user.SetStatus(USER_STATUS_ACTIVE)
+		}
+
+		user.SetVerifiedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+		return store.UserUpdate(withStatusReason(txCtx, "Email verified"), user)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,266 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/sb"
+)
+
+func TestStoreUserVerifyEmail(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	token, err := store.UserVerificationTokenCreate(context.Background(), user.ID(), time.Hour)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(token) != 40 {
+		t.Fatal("Token MUST be 40 characters long, found:", token)
+	}
+
+	var tokenHashStored string
+
+	err = store.DB().
+		QueryRow("SELECT token_hash FROM user_table_token WHERE user_id = ?", user.ID()).
+		Scan(&tokenHashStored)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if tokenHashStored == token {
+		t.Fatal("Token MUST NOT be stored in plain text")
+	}
+
+	userVerified, err := store.UserVerifyEmail(context.Background(), token)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userVerified.ID() != user.ID() {
+		t.Fatal("IDs do not match")
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !userFound.IsActive() {
+		t.Fatal("User MUST be active, found:", userFound.Status())
+	}
+
+	if userFound.VerifiedAtCarbon().Year() < 2000 {
+		t.Fatal("Verified at MUST be set, found:", userFound.VerifiedAt())
+	}
+
+	history, err := store.UserStatusHistory(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(history) != 1 || history[0].Reason() != "Email verified" {
+		t.Fatal("History MUST contain the activation, found:", history)
+	}
+
+	_, err = store.UserVerifyEmail(context.Background(), token)
+
+	if !errors.Is(err, ErrTokenInvalid) {
+		t.Fatal("Token MUST NOT be accepted twice, found:", err)
+	}
+}
+
+func TestStoreUserVerifyEmailExpired(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	err = store.UserCreate(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	token, err := store.UserVerificationTokenCreate(context.Background(), user.ID(), time.Hour)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	carbon.SetTestNow(carbon.Now(carbon.UTC).AddHours(2))
+	defer carbon.ClearTestNow()
+
+	_, err = store.UserVerifyEmail(context.Background(), token)
+
+	if !errors.Is(err, ErrTokenInvalid) {
+		t.Fatal("Expired token MUST NOT be accepted, found:", err)
+	}
+
+	_, err = store.UserVerifyEmail(context.Background(), "unknown_token")
+
+	if !errors.Is(err, ErrTokenInvalid) {
+		t.Fatal("Unknown token MUST NOT be accepted, found:", err)
+	}
+
+	userFound, err := store.UserFindByID(context.Background(), user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !userFound.IsUnverified() {
+		t.Fatal("User MUST still be unverified, found:", userFound.Status())
+	}
+}
+
+func TestStoreUserVerifyEmailChanged(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	user := NewUser().SetEmail("test@test.com")
+
+	if err := store.UserCreate(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	token, err := store.UserVerificationTokenCreate(ctx, user.ID(), time.Hour)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserUpdate(ctx, user.SetEmail("other@test.com")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = store.UserVerifyEmail(ctx, token)
+
+	if !errors.Is(err, ErrTokenInvalid) {
+		t.Fatal("Token MUST NOT verify another email, found:", err)
+	}
+
+	userFound, err := store.UserFindByID(ctx, user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.VerifiedAtCarbon().Year() > 2000 {
+		t.Fatal("Email MUST NOT be verified, found:", userFound.VerifiedAt())
+	}
+}
+
+func TestStoreUserUpdateEmailUnverifies(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	verifiedAt := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	user := NewUser().SetEmail("test@test.com").SetVerifiedAt(verifiedAt)
+
+	if err := store.UserCreate(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// other changes keep the verification
+	if err := store.UserUpdate(ctx, user.SetFirstName("John")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err := store.UserFindByID(ctx, user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.VerifiedAtCarbon().ToDateTimeString(carbon.UTC) != verifiedAt {
+		t.Fatal("Verified at MUST be kept, found:", userFound.VerifiedAt())
+	}
+
+	if err := store.UserUpdate(ctx, user.SetEmail("other@test.com")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err = store.UserFindByID(ctx, user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.VerifiedAtCarbon().ToDateTimeString(carbon.UTC) != sb.NULL_DATETIME {
+		t.Fatal("Verified at MUST be cleared, found:", userFound.VerifiedAt())
+	}
+
+	// the change may verify the new email itself
+	if err := store.UserUpdate(ctx, user.SetEmail("third@test.com").SetVerifiedAt(verifiedAt)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	userFound, err = store.UserFindByID(ctx, user.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if userFound.VerifiedAtCarbon().ToDateTimeString(carbon.UTC) != verifiedAt {
+		t.Fatal("Verified at MUST be set, found:", userFound.VerifiedAt())
+	}
+}
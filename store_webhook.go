@@ -0,0 +1,580 @@
+package userstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// The webhook requests are POST requests with the event as JSON body.
+// The timestamp of the request (Unix seconds, in the WebhookHeaderTimestamp
+// header) and the body are signed with HMAC-SHA256 using the secret of the
+// webhook. The signature is sent in the WebhookHeaderSignature header as
+// "sha256=<hex>", and can be checked with WebhookSign. The receivers are
+// expected to reject the requests with an old timestamp (i.e. older than
+// 5 minutes), so the captured requests cannot be replayed.
+
+const WebhookHeaderDeliveryID = "X-Webhook-Delivery"
+const WebhookHeaderEvent = "X-Webhook-Event"
+const WebhookHeaderSignature = "X-Webhook-Signature"
+const WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+
+const webhookBackoffMax = 24 * time.Hour
+const webhookSecretLength = 32
+
+// webhookClaimLease is how long a claimed delivery is not attempted by
+// the other workers, if the worker dies it is attempted after the lease
+const webhookClaimLease = 5 * time.Minute
+
+// WebhookSign returns the signature of the timestamp and the body, as sent
+// in the WebhookHeaderSignature header of the webhook requests
+func WebhookSign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookCreate creates the webhook, which is expected to have the URL
+// and the event types set. The signing secret is generated.
+//
+// Returns the signing secret, to be shared with the receiver.
+func (store *store) WebhookCreate(ctx context.Context, webhook WebhookInterface) (string, error) {
+	if webhook == nil {
+		return "", errors.New("webhook is nil")
+	}
+
+	if webhook.URL() == "" {
+		return "", errors.New("webhook url is empty")
+	}
+
+	if len(webhook.EventTypes()) < 1 {
+		return "", errors.New("webhook event types are empty")
+	}
+
+	if store.encryptionKey == "" {
+		return "", errors.New("userstore: encryption key is required for webhooks")
+	}
+
+	secret, err := randomFromAlphabet(webhookSecretLength, tokenAlphabet)
+
+	if err != nil {
+		return "", err
+	}
+
+	secretEncrypted, err := encryptString(secret, store.encryptionKey)
+
+	if err != nil {
+		return "", err
+	}
+
+	webhook.SetSecret(secretEncrypted)
+	webhook.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	webhook.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.webhookTableName).
+		Prepared(true).
+		Rows(webhook.Data()).
+		ToSQL()
+
+	if errSql != nil {
+		return "", errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err = database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return "", err
+	}
+
+	webhook.MarkAsNotDirty()
+
+	return secret, nil
+}
+
+// WebhookDelete deletes the webhook, its pending deliveries are not
+// delivered anymore, and become dead on the next delivery attempt
+func (store *store) WebhookDelete(ctx context.Context, webhookID string) error {
+	if webhookID == "" {
+		return errors.New("webhook id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.webhookTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_ID).Eq(webhookID)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// WebhookDeliver attempts up to limit pending deliveries, which are due.
+// A failed delivery is retried with exponential backoff, and after
+// the maximum number of attempts it becomes dead.
+//
+// Expected to be called periodically by one or more workers, each
+// delivery is claimed by one worker only. Returns the number of the
+// attempted deliveries.
+func (store *store) WebhookDeliver(ctx context.Context, limit int) (int, error) {
+	if limit < 1 {
+		return 0, errors.New("limit must be positive")
+	}
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.webhookDeliveryTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_STATUS).Eq(WEBHOOK_DELIVERY_STATUS_PENDING)).
+		Where(goqu.C(COLUMN_NEXT_ATTEMPT_AT).Lte(now)).
+		Order(goqu.C(COLUMN_NEXT_ATTEMPT_AT).Asc(), goqu.C(COLUMN_ID).Asc()).
+		Limit(cast.ToUint(limit)).
+		ToSQL()
+
+	if errSql != nil {
+		return 0, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(modelMaps) < 1 {
+		return 0, nil
+	}
+
+	deliveries := lo.Map(modelMaps, func(modelMap map[string]string, _ int) WebhookDeliveryInterface {
+		return NewWebhookDeliveryFromExistingData(modelMap)
+	})
+
+	webhookIDs := lo.Uniq(lo.Map(deliveries, func(delivery WebhookDeliveryInterface, _ int) string {
+		return delivery.WebhookID()
+	}))
+
+	webhooks, err := store.webhookList(ctx, goqu.C(COLUMN_ID).In(webhookIDs))
+
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+
+	for _, delivery := range deliveries {
+		claimed, err := store.webhookDeliveryClaim(ctx, delivery)
+
+		if err != nil {
+			return attempted, err
+		}
+
+		if !claimed {
+			continue // claimed by another worker
+		}
+
+		attempted++
+
+		webhook, found := lo.Find(webhooks, func(webhook WebhookInterface) bool {
+			return webhook.ID() == delivery.WebhookID()
+		})
+
+		errDelivery := errors.New("webhook not found or inactive")
+
+		if found && webhook.IsActive() {
+			errDelivery = store.webhookSend(ctx, webhook, delivery)
+		}
+
+		if err := store.webhookDeliveryResult(ctx, delivery, errDelivery, found && webhook.IsActive()); err != nil {
+			return attempted, err
+		}
+	}
+
+	return attempted, nil
+}
+
+// WebhookDeliveryList returns the deliveries matching the query, the newest first
+func (store *store) WebhookDeliveryList(ctx context.Context, query WebhookDeliveryQueryInterface) ([]WebhookDeliveryInterface, error) {
+	if query == nil {
+		return nil, errors.New("webhook delivery query is nil")
+	}
+
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	q := goqu.Dialect(store.dbDriverName).From(store.webhookDeliveryTableName)
+
+	if query.HasID() {
+		q = q.Where(goqu.C(COLUMN_ID).Eq(query.ID()))
+	}
+
+	if query.HasStatus() {
+		q = q.Where(goqu.C(COLUMN_STATUS).Eq(query.Status()))
+	}
+
+	if query.HasUserID() {
+		q = q.Where(goqu.C(COLUMN_USER_ID).Eq(query.UserID()))
+	}
+
+	if query.HasWebhookID() {
+		q = q.Where(goqu.C(COLUMN_WEBHOOK_ID).Eq(query.WebhookID()))
+	}
+
+	if query.HasLimit() {
+		q = q.Limit(cast.ToUint(query.Limit()))
+	}
+
+	if query.HasOffset() {
+		q = q.Offset(cast.ToUint(query.Offset()))
+	}
+
+	sqlStr, params, errSql := q.Prepared(true).
+		Order(goqu.C(COLUMN_CREATED_AT).Desc(), goqu.C(COLUMN_ID).Desc()).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := []WebhookDeliveryInterface{}
+
+	for _, modelMap := range modelMaps {
+		list = append(list, NewWebhookDeliveryFromExistingData(modelMap))
+	}
+
+	return list, nil
+}
+
+// WebhookList returns all the webhooks, the oldest first
+func (store *store) WebhookList(ctx context.Context) ([]WebhookInterface, error) {
+	return store.webhookList(ctx)
+}
+
+// WebhookRedeliver queues the delivery to be delivered again on the next
+// WebhookDeliver, with the attempts reset. Used for the dead deliveries,
+// but a delivered one can be redelivered too.
+//
+// Returns ErrWebhookDeliveryNotFound, if the delivery does not exist.
+func (store *store) WebhookRedeliver(ctx context.Context, deliveryID string) error {
+	if deliveryID == "" {
+		return errors.New("webhook delivery id is empty")
+	}
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.webhookDeliveryTableName).
+		Prepared(true).
+		Set(map[string]any{
+			COLUMN_STATUS:          WEBHOOK_DELIVERY_STATUS_PENDING,
+			COLUMN_ATTEMPTS:        0,
+			COLUMN_NEXT_ATTEMPT_AT: now,
+			COLUMN_UPDATED_AT:      now,
+		}).
+		Where(goqu.C(COLUMN_ID).Eq(deliveryID)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected < 1 {
+		return ErrWebhookDeliveryNotFound
+	}
+
+	return nil
+}
+
+// webhookBackoffAfter returns the delay before the next attempt,
+// after the specified number of failed attempts
+func (store *store) webhookBackoffAfter(attempts int) time.Duration {
+	backoff := store.webhookBackoff
+
+	for i := 1; i < attempts && backoff < webhookBackoffMax; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, webhookBackoffMax)
+}
+
+// webhookDeliveriesCreate queues the deliveries of the event for
+// the active webhooks subscribed to its type. Called inside the
+// transaction of the change, together with the outbox event
+func (store *store) webhookDeliveriesCreate(ctx context.Context, eventID, eventType, userID, body string) error {
+	// the event types are matched exactly below, the LIKE only narrows
+	// the webhooks loaded with every change
+	webhooks, err := store.webhookList(ctx,
+		goqu.C(COLUMN_STATUS).Eq(WEBHOOK_STATUS_ACTIVE),
+		goqu.C(COLUMN_EVENT_TYPES).Like("%"+eventType+"%"))
+
+	if err != nil {
+		return err
+	}
+
+	webhooks = lo.Filter(webhooks, func(webhook WebhookInterface, _ int) bool {
+		return webhook.IsActive() && webhook.HasEventType(eventType)
+	})
+
+	if len(webhooks) < 1 {
+		return nil
+	}
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	rows := lo.Map(webhooks, func(webhook WebhookInterface, _ int) any {
+		return map[string]any{
+			COLUMN_ID:              uid.HumanUid(),
+			COLUMN_WEBHOOK_ID:      webhook.ID(),
+			COLUMN_EVENT_ID:        eventID,
+			COLUMN_TYPE:            eventType,
+			COLUMN_USER_ID:         userID,
+			COLUMN_PAYLOAD:         body,
+			COLUMN_STATUS:          WEBHOOK_DELIVERY_STATUS_PENDING,
+			COLUMN_ATTEMPTS:        0,
+			COLUMN_NEXT_ATTEMPT_AT: now,
+			COLUMN_LAST_ERROR:      "",
+			COLUMN_DELIVERED_AT:    sb.NULL_DATETIME,
+			COLUMN_CREATED_AT:      now,
+			COLUMN_UPDATED_AT:      now,
+		}
+	})
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.webhookDeliveryTableName).
+		Prepared(true).
+		Rows(rows...).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err = database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// webhookDeliveryClaim claims the delivery for the worker, by moving its
+// next attempt past the lease. Returns false, if the delivery was claimed
+// by another worker since it was selected.
+func (store *store) webhookDeliveryClaim(ctx context.Context, delivery WebhookDeliveryInterface) (bool, error) {
+	now := carbon.Now(carbon.UTC)
+	lease := max(webhookClaimLease, 2*store.webhookHTTPClient.Timeout)
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.webhookDeliveryTableName).
+		Prepared(true).
+		Set(map[string]any{
+			COLUMN_NEXT_ATTEMPT_AT: now.Copy().AddDuration(lease.String()).ToDateTimeString(carbon.UTC),
+			COLUMN_UPDATED_AT:      now.ToDateTimeString(carbon.UTC),
+		}).
+		Where(goqu.C(COLUMN_ID).Eq(delivery.ID())).
+		Where(goqu.C(COLUMN_STATUS).Eq(WEBHOOK_DELIVERY_STATUS_PENDING)).
+		Where(goqu.C(COLUMN_NEXT_ATTEMPT_AT).Eq(delivery.NextAttemptAtCarbon().ToDateTimeString(carbon.UTC))).
+		ToSQL()
+
+	if errSql != nil {
+		return false, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// webhookDeliveryResult stores the result of the delivery attempt.
+// When not retryable (i.e. the webhook was deleted), a failed
+// delivery becomes dead immediately
+func (store *store) webhookDeliveryResult(ctx context.Context, delivery WebhookDeliveryInterface, errDelivery error, retryable bool) error {
+	now := carbon.Now(carbon.UTC)
+	attempts := delivery.Attempts() + 1
+
+	columns := map[string]any{
+		COLUMN_ATTEMPTS:   attempts,
+		COLUMN_UPDATED_AT: now.ToDateTimeString(carbon.UTC),
+	}
+
+	if errDelivery == nil {
+		columns[COLUMN_STATUS] = WEBHOOK_DELIVERY_STATUS_DELIVERED
+		columns[COLUMN_DELIVERED_AT] = now.ToDateTimeString(carbon.UTC)
+		columns[COLUMN_LAST_ERROR] = ""
+	} else if !retryable || attempts >= store.webhookMaxAttempts {
+		columns[COLUMN_STATUS] = WEBHOOK_DELIVERY_STATUS_DEAD
+		columns[COLUMN_LAST_ERROR] = errDelivery.Error()
+	} else {
+		columns[COLUMN_NEXT_ATTEMPT_AT] = now.Copy().AddDuration(store.webhookBackoffAfter(attempts).String()).ToDateTimeString(carbon.UTC)
+		columns[COLUMN_LAST_ERROR] = errDelivery.Error()
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.webhookDeliveryTableName).
+		Prepared(true).
+		Set(columns).
+		Where(goqu.C(COLUMN_ID).Eq(delivery.ID())).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+func (store *store) webhookList(ctx context.Context, conditions ...goqu.Expression) ([]WebhookInterface, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.webhookTableName).
+		Prepared(true).
+		Where(conditions...).
+		Order(goqu.C(COLUMN_CREATED_AT).Asc(), goqu.C(COLUMN_ID).Asc()).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	if store.debugEnabled {
+		log.Println(sqlStr)
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := []WebhookInterface{}
+
+	for _, modelMap := range modelMaps {
+		list = append(list, NewWebhookFromExistingData(modelMap))
+	}
+
+	return list, nil
+}
+
+// webhookSend sends the delivery to the webhook, any response
+// other than 2xx is an error
+func (store *store) webhookSend(ctx context.Context, webhook WebhookInterface, delivery WebhookDeliveryInterface) error {
+	secret, err := decryptString(webhook.Secret(), store.encryptionKey)
+
+	if err != nil {
+		return err
+	}
+
+	body := []byte(delivery.Payload())
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL(), bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookHeaderDeliveryID, delivery.ID())
+	request.Header.Set(WebhookHeaderEvent, delivery.Type())
+	timestamp := strconv.FormatInt(carbon.Now(carbon.UTC).Timestamp(), 10)
+
+	request.Header.Set(WebhookHeaderTimestamp, timestamp)
+	request.Header.Set(WebhookHeaderSignature, WebhookSign(secret, timestamp, body))
+
+	response, err := store.webhookHTTPClient.Do(request)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	// drained, so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return errors.New("unexpected response status " + strconv.Itoa(response.StatusCode))
+	}
+
+	return nil
+}
@@ -0,0 +1,357 @@
+package userstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookReceiver is a test receiver, which verifies the signatures
+// and fails the requests while failures is greater than 0
+type webhookReceiver struct {
+	mutex    sync.Mutex
+	secret   string
+	failures int
+	events   []map[string]any
+}
+
+func (receiver *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+
+	timestamp := r.Header.Get(WebhookHeaderTimestamp)
+
+	if timestamp == "" || r.Header.Get(WebhookHeaderSignature) != WebhookSign(receiver.secret, timestamp, body) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if receiver.failures > 0 {
+		receiver.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	event := map[string]any{}
+
+	if err := json.Unmarshal(body, &event); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	receiver.events = append(receiver.events, event)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func initStoreWithWebhooks(maxAttempts int) (StoreInterface, error) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStore(NewStoreOptions{
+		DB:                 db,
+		UserTableName:      "user_table",
+		AutomigrateEnabled: true,
+		EncryptionKey:      "test_encryption_key",
+		WebhookBackoff:     time.Millisecond, // retries are due immediately
+		WebhookMaxAttempts: maxAttempts,
+	})
+}
+
+func TestStoreWebhookDeliver(t *testing.T) {
+	store, err := initStoreWithWebhooks(3)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	receiver := &webhookReceiver{failures: 1}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	ctx := context.Background()
+
+	receiver.secret, err = store.WebhookCreate(ctx, NewWebhook().
+		SetURL(server.URL).
+		SetEventTypes([]string{OUTBOX_EVENT_USER_CREATED}))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if receiver.secret == "" {
+		t.Fatal("Secret MUST NOT be empty")
+	}
+
+	user := NewUser().SetEmail("test@test.com")
+
+	if err := store.UserCreate(ctx, user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// not subscribed to the updates
+	if err := store.UserUpdate(ctx, user.SetFirstName("John")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	attempted, err := store.WebhookDeliver(ctx, 10)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if attempted != 1 {
+		t.Fatal("Attempted MUST be 1, found:", attempted)
+	}
+
+	deliveries, err := store.WebhookDeliveryList(ctx, NewWebhookDeliveryQuery().SetUserID(user.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(deliveries) != 1 {
+		t.Fatal("Deliveries MUST be 1, found:", len(deliveries))
+	}
+
+	if !deliveries[0].IsPending() || deliveries[0].Attempts() != 1 || deliveries[0].LastError() == "" {
+		t.Fatal("Failed delivery MUST be retried, found:", deliveries[0].Data())
+	}
+
+	if _, err := store.WebhookDeliver(ctx, 10); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	deliveries, err = store.WebhookDeliveryList(ctx, NewWebhookDeliveryQuery().SetUserID(user.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !deliveries[0].IsDelivered() || deliveries[0].Attempts() != 2 {
+		t.Fatal("Delivery MUST be delivered on retry, found:", deliveries[0].Data())
+	}
+
+	if len(receiver.events) != 1 {
+		t.Fatal("Events received MUST be 1, found:", len(receiver.events))
+	}
+
+	if receiver.events[0]["type"] != OUTBOX_EVENT_USER_CREATED || receiver.events[0]["user_id"] != user.ID() {
+		t.Fatal("Event MUST be user created, found:", receiver.events[0])
+	}
+
+	attempted, err = store.WebhookDeliver(ctx, 10)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if attempted != 0 {
+		t.Fatal("Delivered MUST NOT be attempted again, found:", attempted)
+	}
+}
+
+func TestStoreWebhookDeadAndRedeliver(t *testing.T) {
+	store, err := initStoreWithWebhooks(2)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	receiver := &webhookReceiver{failures: 2}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	ctx := context.Background()
+
+	receiver.secret, err = store.WebhookCreate(ctx, NewWebhook().
+		SetURL(server.URL).
+		SetEventTypes([]string{OUTBOX_EVENT_USER_CREATED}))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserCreate(ctx, NewUser()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := store.WebhookDeliver(ctx, 10); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	dead, err := store.WebhookDeliveryList(ctx, NewWebhookDeliveryQuery().SetStatus(WEBHOOK_DELIVERY_STATUS_DEAD))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(dead) != 1 || dead[0].Attempts() != 2 {
+		t.Fatal("Delivery MUST be dead after 2 attempts, found:", len(dead))
+	}
+
+	if err := store.WebhookRedeliver(ctx, dead[0].ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := store.WebhookDeliver(ctx, 10); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	deliveries, err := store.WebhookDeliveryList(ctx, NewWebhookDeliveryQuery().SetID(dead[0].ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !deliveries[0].IsDelivered() {
+		t.Fatal("Redelivered MUST be delivered, found:", deliveries[0].Data())
+	}
+
+	err = store.WebhookRedeliver(ctx, "missing")
+
+	if !errors.Is(err, ErrWebhookDeliveryNotFound) {
+		t.Fatal("Error MUST be ErrWebhookDeliveryNotFound, found:", err)
+	}
+}
+
+func TestStoreWebhookDeleted(t *testing.T) {
+	store, err := initStoreWithWebhooks(5)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	webhook := NewWebhook().
+		SetURL("http://127.0.0.1:1").
+		SetEventTypes([]string{OUTBOX_EVENT_USER_CREATED})
+
+	if _, err := store.WebhookCreate(ctx, webhook); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserCreate(ctx, NewUser()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.WebhookDelete(ctx, webhook.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := store.WebhookDeliver(ctx, 10); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	dead, err := store.WebhookDeliveryList(ctx, NewWebhookDeliveryQuery().SetStatus(WEBHOOK_DELIVERY_STATUS_DEAD))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(dead) != 1 {
+		t.Fatal("Delivery of a deleted webhook MUST be dead, found:", len(dead))
+	}
+}
+
+func TestStoreWebhookDeliveryClaim(t *testing.T) {
+	store, err := initStoreWithWebhooks(5)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	if _, err := store.WebhookCreate(ctx, NewWebhook().
+		SetURL("http://127.0.0.1:1").
+		SetEventTypes([]string{OUTBOX_EVENT_USER_CREATED})); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserCreate(ctx, NewUser()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	deliveries, err := store.WebhookDeliveryList(ctx, NewWebhookDeliveryQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(deliveries) != 1 {
+		t.Fatal("Deliveries MUST be 1, found:", len(deliveries))
+	}
+
+	worker := store.(interface {
+		webhookDeliveryClaim(ctx context.Context, delivery WebhookDeliveryInterface) (bool, error)
+	})
+
+	// two workers, which selected the same delivery
+	claimed, err := worker.webhookDeliveryClaim(ctx, deliveries[0])
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !claimed {
+		t.Fatal("Delivery MUST be claimed by the first worker")
+	}
+
+	claimed, err = worker.webhookDeliveryClaim(ctx, deliveries[0])
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if claimed {
+		t.Fatal("Delivery MUST NOT be claimed by the second worker")
+	}
+
+	attempted, err := store.WebhookDeliver(ctx, 10)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if attempted != 0 {
+		t.Fatal("Claimed delivery MUST NOT be attempted during the lease, found:", attempted)
+	}
+}
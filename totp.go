@@ -0,0 +1,104 @@
+package userstore
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP as defined in RFC 6238, using the defaults supported by
+// all the common authenticator apps (SHA1, 6 digits, 30 seconds)
+
+const totpDigits = 6
+const totpPeriod = 30
+const totpSecretSize = 20
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// totpSecretGenerate generates a new random base32 encoded secret
+func totpSecretGenerate() (string, error) {
+	secret := make([]byte, totpSecretSize)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// totpStep returns the time step the specified time falls into
+func totpStep(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// totpCode returns the code for the specified time step
+func totpCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+
+	if err != nil {
+		return "", errors.New("totp: secret is not valid base32")
+	}
+
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", value%1000000), nil
+}
+
+// totpMatch checks the code against the steps around the specified time,
+// allowing for clock drift of up to drift steps in either direction.
+//
+// Returns the matched step, or false if the code did not match any step.
+func totpMatch(secret, code string, t time.Time, drift int) (step int64, matched bool, err error) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+
+	if len(code) != totpDigits {
+		return 0, false, nil
+	}
+
+	current := totpStep(t)
+
+	for i := -drift; i <= drift; i++ {
+		expected, err := totpCode(secret, current+int64(i))
+
+		if err != nil {
+			return 0, false, err
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return current + int64(i), true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// totpProvisioningURI returns the otpauth:// URI, which authenticator
+// apps use (usually as a QR code) to add the account
+func totpProvisioningURI(issuer, accountName, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(accountName)
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
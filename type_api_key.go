@@ -0,0 +1,189 @@
+package userstore
+
+import (
+	"strings"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+	"github.com/samber/lo"
+)
+
+// == CLASS ===================================================================
+
+type apiKey struct {
+	dataobject.DataObject
+}
+
+var _ APIKeyInterface = (*apiKey)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewAPIKey() APIKeyInterface {
+	o := &apiKey{}
+
+	o.SetID(uid.HumanUid()).
+		SetUserID("").
+		SetName("").
+		SetKeyPrefix("").
+		SetKeyHash("").
+		SetScopes([]string{}).
+		SetExpiresAt(sb.MAX_DATETIME).
+		SetLastUsedAt(sb.NULL_DATETIME).
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetSoftDeletedAt(sb.MAX_DATETIME)
+
+	return o
+}
+
+func NewAPIKeyFromExistingData(data map[string]string) APIKeyInterface {
+	o := &apiKey{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+func (o *apiKey) HasScope(scope string) bool {
+	return lo.Contains(o.Scopes(), scope)
+}
+
+func (o *apiKey) IsExpired() bool {
+	return o.ExpiresAtCarbon().Compare("<", carbon.Now(carbon.UTC))
+}
+
+func (o *apiKey) IsRevoked() bool {
+	return o.SoftDeletedAtCarbon().Compare("<", carbon.Now(carbon.UTC))
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *apiKey) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *apiKey) CreatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *apiKey) SetCreatedAt(createdAt string) APIKeyInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+func (o *apiKey) ExpiresAt() string {
+	return o.Get(COLUMN_EXPIRES_AT)
+}
+
+func (o *apiKey) ExpiresAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.ExpiresAt(), carbon.UTC)
+}
+
+func (o *apiKey) SetExpiresAt(expiresAt string) APIKeyInterface {
+	o.Set(COLUMN_EXPIRES_AT, expiresAt)
+	return o
+}
+
+func (o *apiKey) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *apiKey) SetID(id string) APIKeyInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+// KeyHash returns the SHA256 hash of the secret part of the key
+func (o *apiKey) KeyHash() string {
+	return o.Get(COLUMN_KEY_HASH)
+}
+
+func (o *apiKey) SetKeyHash(keyHash string) APIKeyInterface {
+	o.Set(COLUMN_KEY_HASH, keyHash)
+	return o
+}
+
+// KeyPrefix returns the public part of the key, which identifies it
+func (o *apiKey) KeyPrefix() string {
+	return o.Get(COLUMN_KEY_PREFIX)
+}
+
+func (o *apiKey) SetKeyPrefix(keyPrefix string) APIKeyInterface {
+	o.Set(COLUMN_KEY_PREFIX, keyPrefix)
+	return o
+}
+
+func (o *apiKey) LastUsedAt() string {
+	return o.Get(COLUMN_LAST_USED_AT)
+}
+
+func (o *apiKey) LastUsedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.LastUsedAt(), carbon.UTC)
+}
+
+func (o *apiKey) SetLastUsedAt(lastUsedAt string) APIKeyInterface {
+	o.Set(COLUMN_LAST_USED_AT, lastUsedAt)
+	return o
+}
+
+func (o *apiKey) Name() string {
+	return o.Get(COLUMN_NAME)
+}
+
+func (o *apiKey) SetName(name string) APIKeyInterface {
+	o.Set(COLUMN_NAME, name)
+	return o
+}
+
+func (o *apiKey) Scopes() []string {
+	scopes := strings.Split(o.Get(COLUMN_SCOPES), ",")
+
+	return lo.Compact(scopes)
+}
+
+// SetScopes stores the scopes as a comma separated string
+func (o *apiKey) SetScopes(scopes []string) APIKeyInterface {
+	scopes = lo.Map(scopes, func(scope string, _ int) string {
+		return strings.TrimSpace(scope)
+	})
+
+	o.Set(COLUMN_SCOPES, strings.Join(lo.Uniq(lo.Compact(scopes)), ","))
+	return o
+}
+
+func (o *apiKey) SoftDeletedAt() string {
+	return o.Get(COLUMN_SOFT_DELETED_AT)
+}
+
+func (o *apiKey) SoftDeletedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.SoftDeletedAt(), carbon.UTC)
+}
+
+func (o *apiKey) SetSoftDeletedAt(softDeletedAt string) APIKeyInterface {
+	o.Set(COLUMN_SOFT_DELETED_AT, softDeletedAt)
+	return o
+}
+
+func (o *apiKey) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *apiKey) UpdatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.UpdatedAt(), carbon.UTC)
+}
+
+func (o *apiKey) SetUpdatedAt(updatedAt string) APIKeyInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}
+
+func (o *apiKey) UserID() string {
+	return o.Get(COLUMN_USER_ID)
+}
+
+func (o *apiKey) SetUserID(userID string) APIKeyInterface {
+	o.Set(COLUMN_USER_ID, userID)
+	return o
+}
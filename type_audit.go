@@ -0,0 +1,98 @@
+package userstore
+
+import (
+	"encoding/json"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+)
+
+// == CLASS ===================================================================
+
+type audit struct {
+	dataobject.DataObject
+}
+
+var _ AuditInterface = (*audit)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewAuditFromExistingData(data map[string]string) AuditInterface {
+	o := &audit{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+// DiffMap returns the diff as column => {"before": value, "after": value},
+// or an empty map if the audit event has no diff
+func (o *audit) DiffMap() map[string]map[string]string {
+	diff := map[string]map[string]string{}
+
+	if o.Diff() == "" {
+		return diff
+	}
+
+	if err := json.Unmarshal([]byte(o.Diff()), &diff); err != nil {
+		return map[string]map[string]string{}
+	}
+
+	return diff
+}
+
+// MetadataMap returns the request metadata,
+// or an empty map if the audit event has no metadata
+func (o *audit) MetadataMap() map[string]string {
+	metadata := map[string]string{}
+
+	if o.Metadata() == "" {
+		return metadata
+	}
+
+	if err := json.Unmarshal([]byte(o.Metadata()), &metadata); err != nil {
+		return map[string]string{}
+	}
+
+	return metadata
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *audit) Action() string {
+	return o.Get(COLUMN_ACTION)
+}
+
+func (o *audit) ActorID() string {
+	return o.Get(COLUMN_ACTOR_ID)
+}
+
+func (o *audit) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *audit) CreatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+// Diff returns the diff as JSON
+func (o *audit) Diff() string {
+	return o.Get(COLUMN_DIFF)
+}
+
+func (o *audit) Entity() string {
+	return o.Get(COLUMN_ENTITY)
+}
+
+func (o *audit) EntityID() string {
+	return o.Get(COLUMN_ENTITY_ID)
+}
+
+func (o *audit) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+// Metadata returns the request metadata as JSON
+func (o *audit) Metadata() string {
+	return o.Get(COLUMN_METADATA)
+}
@@ -0,0 +1,114 @@
+package userstore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/uid"
+)
+
+// == CLASS ===================================================================
+
+type identity struct {
+	dataobject.DataObject
+}
+
+var _ IdentityInterface = (*identity)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewIdentity() IdentityInterface {
+	o := &identity{}
+
+	o.SetID(uid.HumanUid()).
+		SetUserID("").
+		SetProvider("").
+		SetSubject("").
+		SetEmail("").
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return o
+}
+
+func NewIdentityFromExistingData(data map[string]string) IdentityInterface {
+	o := &identity{}
+	o.Hydrate(data)
+	return o
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *identity) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *identity) CreatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *identity) SetCreatedAt(createdAt string) IdentityInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+// Email returns the email reported by the provider, when the identity was linked
+func (o *identity) Email() string {
+	return o.Get(COLUMN_EMAIL)
+}
+
+func (o *identity) SetEmail(email string) IdentityInterface {
+	o.Set(COLUMN_EMAIL, email)
+	return o
+}
+
+func (o *identity) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *identity) SetID(id string) IdentityInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+// Provider returns the name of the identity provider (i.e. google, github)
+func (o *identity) Provider() string {
+	return o.Get(COLUMN_PROVIDER)
+}
+
+func (o *identity) SetProvider(provider string) IdentityInterface {
+	o.Set(COLUMN_PROVIDER, provider)
+	return o
+}
+
+// Subject returns the stable ID of the user at the provider
+// (i.e. the "sub" claim of OpenID Connect)
+func (o *identity) Subject() string {
+	return o.Get(COLUMN_SUBJECT)
+}
+
+func (o *identity) SetSubject(subject string) IdentityInterface {
+	o.Set(COLUMN_SUBJECT, subject)
+	return o
+}
+
+func (o *identity) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *identity) UpdatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.UpdatedAt(), carbon.UTC)
+}
+
+func (o *identity) SetUpdatedAt(updatedAt string) IdentityInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}
+
+func (o *identity) UserID() string {
+	return o.Get(COLUMN_USER_ID)
+}
+
+func (o *identity) SetUserID(userID string) IdentityInterface {
+	o.Set(COLUMN_USER_ID, userID)
+	return o
+}
@@ -0,0 +1,138 @@
+package userstore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+	"github.com/spf13/cast"
+)
+
+// == CLASS ===================================================================
+
+type mfa struct {
+	dataobject.DataObject
+}
+
+var _ MfaInterface = (*mfa)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewMfa() MfaInterface {
+	o := &mfa{}
+
+	o.SetID(uid.HumanUid()).
+		SetStatus(MFA_STATUS_PENDING).
+		SetUserID("").
+		SetSecret("").
+		SetLastUsedStep(0).
+		SetEnabledAt(sb.NULL_DATETIME).
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return o
+}
+
+func NewMfaFromExistingData(data map[string]string) MfaInterface {
+	o := &mfa{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+func (o *mfa) IsEnabled() bool {
+	return o.Status() == MFA_STATUS_ENABLED
+}
+
+func (o *mfa) IsPending() bool {
+	return o.Status() == MFA_STATUS_PENDING
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *mfa) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *mfa) CreatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *mfa) SetCreatedAt(createdAt string) MfaInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+func (o *mfa) EnabledAt() string {
+	return o.Get(COLUMN_ENABLED_AT)
+}
+
+func (o *mfa) EnabledAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.EnabledAt(), carbon.UTC)
+}
+
+func (o *mfa) SetEnabledAt(enabledAt string) MfaInterface {
+	o.Set(COLUMN_ENABLED_AT, enabledAt)
+	return o
+}
+
+func (o *mfa) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *mfa) SetID(id string) MfaInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+func (o *mfa) LastUsedStep() int64 {
+	return cast.ToInt64(o.Get(COLUMN_LAST_USED_STEP))
+}
+
+func (o *mfa) SetLastUsedStep(lastUsedStep int64) MfaInterface {
+	o.Set(COLUMN_LAST_USED_STEP, cast.ToString(lastUsedStep))
+	return o
+}
+
+// Secret returns the TOTP secret, as stored (encrypted)
+func (o *mfa) Secret() string {
+	return o.Get(COLUMN_SECRET)
+}
+
+// SetSecret sets the TOTP secret, it is expected to be already encrypted
+func (o *mfa) SetSecret(secret string) MfaInterface {
+	o.Set(COLUMN_SECRET, secret)
+	return o
+}
+
+func (o *mfa) Status() string {
+	return o.Get(COLUMN_STATUS)
+}
+
+func (o *mfa) SetStatus(status string) MfaInterface {
+	o.Set(COLUMN_STATUS, status)
+	return o
+}
+
+func (o *mfa) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *mfa) UpdatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.UpdatedAt(), carbon.UTC)
+}
+
+func (o *mfa) SetUpdatedAt(updatedAt string) MfaInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}
+
+func (o *mfa) UserID() string {
+	return o.Get(COLUMN_USER_ID)
+}
+
+func (o *mfa) SetUserID(userID string) MfaInterface {
+	o.Set(COLUMN_USER_ID, userID)
+	return o
+}
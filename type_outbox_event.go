@@ -0,0 +1,66 @@
+package userstore
+
+import (
+	"encoding/json"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+)
+
+// == CLASS ===================================================================
+
+type outboxEvent struct {
+	dataobject.DataObject
+}
+
+var _ OutboxEventInterface = (*outboxEvent)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewOutboxEventFromExistingData(data map[string]string) OutboxEventInterface {
+	o := &outboxEvent{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+// PayloadMap returns the payload of the event,
+// or an empty map if the payload cannot be read
+func (o *outboxEvent) PayloadMap() map[string]any {
+	payload := map[string]any{}
+
+	if err := json.Unmarshal([]byte(o.Payload()), &payload); err != nil {
+		return map[string]any{}
+	}
+
+	return payload
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *outboxEvent) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *outboxEvent) CreatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *outboxEvent) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+// Payload returns the payload of the event as JSON
+func (o *outboxEvent) Payload() string {
+	return o.Get(COLUMN_PAYLOAD)
+}
+
+// Type returns the type of the event, one of the OUTBOX_EVENT_ constants
+func (o *outboxEvent) Type() string {
+	return o.Get(COLUMN_TYPE)
+}
+
+func (o *outboxEvent) UserID() string {
+	return o.Get(COLUMN_USER_ID)
+}
@@ -0,0 +1,188 @@
+package userstore
+
+import (
+	"strings"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// == CLASS ===================================================================
+
+type passkey struct {
+	dataobject.DataObject
+}
+
+var _ PasskeyInterface = (*passkey)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewPasskey() PasskeyInterface {
+	o := &passkey{}
+
+	o.SetID(uid.HumanUid()).
+		SetUserID("").
+		SetCredentialID("").
+		SetPublicKey("").
+		SetSignCount(0).
+		SetAAGUID("").
+		SetTransports([]string{}).
+		SetNickname("").
+		SetLastUsedAt(sb.NULL_DATETIME).
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetSoftDeletedAt(sb.MAX_DATETIME)
+
+	return o
+}
+
+func NewPasskeyFromExistingData(data map[string]string) PasskeyInterface {
+	o := &passkey{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+func (o *passkey) IsRevoked() bool {
+	return o.SoftDeletedAtCarbon().Compare("<", carbon.Now(carbon.UTC))
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+// AAGUID returns the authenticator attestation GUID,
+// which identifies the model of the authenticator
+func (o *passkey) AAGUID() string {
+	return o.Get(COLUMN_AAGUID)
+}
+
+func (o *passkey) SetAAGUID(aaguid string) PasskeyInterface {
+	o.Set(COLUMN_AAGUID, aaguid)
+	return o
+}
+
+func (o *passkey) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *passkey) CreatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *passkey) SetCreatedAt(createdAt string) PasskeyInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+// CredentialID returns the credential ID, base64url encoded
+func (o *passkey) CredentialID() string {
+	return o.Get(COLUMN_CREDENTIAL_ID)
+}
+
+func (o *passkey) SetCredentialID(credentialID string) PasskeyInterface {
+	o.Set(COLUMN_CREDENTIAL_ID, credentialID)
+	return o
+}
+
+func (o *passkey) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *passkey) SetID(id string) PasskeyInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+func (o *passkey) LastUsedAt() string {
+	return o.Get(COLUMN_LAST_USED_AT)
+}
+
+func (o *passkey) LastUsedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.LastUsedAt(), carbon.UTC)
+}
+
+func (o *passkey) SetLastUsedAt(lastUsedAt string) PasskeyInterface {
+	o.Set(COLUMN_LAST_USED_AT, lastUsedAt)
+	return o
+}
+
+func (o *passkey) Nickname() string {
+	return o.Get(COLUMN_NICKNAME)
+}
+
+func (o *passkey) SetNickname(nickname string) PasskeyInterface {
+	o.Set(COLUMN_NICKNAME, nickname)
+	return o
+}
+
+// PublicKey returns the COSE encoded public key, base64url encoded
+func (o *passkey) PublicKey() string {
+	return o.Get(COLUMN_PUBLIC_KEY)
+}
+
+func (o *passkey) SetPublicKey(publicKey string) PasskeyInterface {
+	o.Set(COLUMN_PUBLIC_KEY, publicKey)
+	return o
+}
+
+func (o *passkey) SignCount() int64 {
+	return cast.ToInt64(o.Get(COLUMN_SIGN_COUNT))
+}
+
+func (o *passkey) SetSignCount(signCount int64) PasskeyInterface {
+	o.Set(COLUMN_SIGN_COUNT, cast.ToString(signCount))
+	return o
+}
+
+func (o *passkey) SoftDeletedAt() string {
+	return o.Get(COLUMN_SOFT_DELETED_AT)
+}
+
+func (o *passkey) SoftDeletedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.SoftDeletedAt(), carbon.UTC)
+}
+
+func (o *passkey) SetSoftDeletedAt(softDeletedAt string) PasskeyInterface {
+	o.Set(COLUMN_SOFT_DELETED_AT, softDeletedAt)
+	return o
+}
+
+// Transports returns the transports supported by the authenticator,
+// i.e. "usb", "nfc", "ble", "internal", "hybrid"
+func (o *passkey) Transports() []string {
+	transports := strings.Split(o.Get(COLUMN_TRANSPORTS), ",")
+
+	return lo.Compact(transports)
+}
+
+// SetTransports stores the transports as a comma separated string
+func (o *passkey) SetTransports(transports []string) PasskeyInterface {
+	o.Set(COLUMN_TRANSPORTS, strings.Join(lo.Compact(transports), ","))
+	return o
+}
+
+func (o *passkey) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *passkey) UpdatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.UpdatedAt(), carbon.UTC)
+}
+
+func (o *passkey) SetUpdatedAt(updatedAt string) PasskeyInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}
+
+func (o *passkey) UserID() string {
+	return o.Get(COLUMN_USER_ID)
+}
+
+func (o *passkey) SetUserID(userID string) PasskeyInterface {
+	o.Set(COLUMN_USER_ID, userID)
+	return o
+}
@@ -0,0 +1,66 @@
+package userstore
+
+import (
+	"encoding/json"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/spf13/cast"
+)
+
+// == CLASS ===================================================================
+
+type revision struct {
+	dataobject.DataObject
+}
+
+var _ RevisionInterface = (*revision)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewRevisionFromExistingData(data map[string]string) RevisionInterface {
+	o := &revision{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+// SnapshotMap returns the data of the user at this revision,
+// or an empty map if the snapshot cannot be read
+func (o *revision) SnapshotMap() map[string]string {
+	snapshot := map[string]string{}
+
+	if err := json.Unmarshal([]byte(o.Snapshot()), &snapshot); err != nil {
+		return map[string]string{}
+	}
+
+	return snapshot
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *revision) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *revision) CreatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *revision) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *revision) Revision() int {
+	return cast.ToInt(o.Get(COLUMN_REVISION))
+}
+
+// Snapshot returns the data of the user at this revision as JSON
+func (o *revision) Snapshot() string {
+	return o.Get(COLUMN_SNAPSHOT)
+}
+
+func (o *revision) UserID() string {
+	return o.Get(COLUMN_USER_ID)
+}
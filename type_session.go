@@ -0,0 +1,182 @@
+package userstore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+)
+
+// == CLASS ===================================================================
+
+type session struct {
+	dataobject.DataObject
+}
+
+var _ SessionInterface = (*session)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+// NewSession creates a new session, which expires after the session TTL
+// of the store, unless an expiry is set with SetExpiresAt
+func NewSession() SessionInterface {
+	o := &session{}
+
+	o.SetID(uid.HumanUid()).
+		SetTokenHash("").
+		SetUserID("").
+		SetIPAddress("").
+		SetUserAgent("").
+		SetDevice("").
+		SetLastSeenAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetExpiresAt("").
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetSoftDeletedAt(sb.MAX_DATETIME)
+
+	return o
+}
+
+func NewSessionFromExistingData(data map[string]string) SessionInterface {
+	o := &session{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+func (o *session) IsActive() bool {
+	return !o.IsExpired() && !o.IsRevoked()
+}
+
+func (o *session) IsExpired() bool {
+	return o.ExpiresAtCarbon().Compare("<", carbon.Now(carbon.UTC))
+}
+
+func (o *session) IsRevoked() bool {
+	return o.SoftDeletedAtCarbon().Compare("<", carbon.Now(carbon.UTC))
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *session) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *session) CreatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *session) SetCreatedAt(createdAt string) SessionInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+// Device returns the label of the device (i.e. "Chrome on Windows")
+func (o *session) Device() string {
+	return o.Get(COLUMN_DEVICE)
+}
+
+func (o *session) SetDevice(device string) SessionInterface {
+	o.Set(COLUMN_DEVICE, device)
+	return o
+}
+
+func (o *session) ExpiresAt() string {
+	return o.Get(COLUMN_EXPIRES_AT)
+}
+
+func (o *session) ExpiresAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.ExpiresAt(), carbon.UTC)
+}
+
+func (o *session) SetExpiresAt(expiresAt string) SessionInterface {
+	o.Set(COLUMN_EXPIRES_AT, expiresAt)
+	return o
+}
+
+func (o *session) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *session) SetID(id string) SessionInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+func (o *session) IPAddress() string {
+	return o.Get(COLUMN_IP_ADDRESS)
+}
+
+func (o *session) SetIPAddress(ipAddress string) SessionInterface {
+	o.Set(COLUMN_IP_ADDRESS, ipAddress)
+	return o
+}
+
+func (o *session) LastSeenAt() string {
+	return o.Get(COLUMN_LAST_SEEN_AT)
+}
+
+func (o *session) LastSeenAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.LastSeenAt(), carbon.UTC)
+}
+
+func (o *session) SetLastSeenAt(lastSeenAt string) SessionInterface {
+	o.Set(COLUMN_LAST_SEEN_AT, lastSeenAt)
+	return o
+}
+
+func (o *session) SoftDeletedAt() string {
+	return o.Get(COLUMN_SOFT_DELETED_AT)
+}
+
+func (o *session) SoftDeletedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.SoftDeletedAt(), carbon.UTC)
+}
+
+func (o *session) SetSoftDeletedAt(softDeletedAt string) SessionInterface {
+	o.Set(COLUMN_SOFT_DELETED_AT, softDeletedAt)
+	return o
+}
+
+// TokenHash returns the SHA256 hash of the token presented by the client,
+// the token itself is not stored
+func (o *session) TokenHash() string {
+	return o.Get(COLUMN_TOKEN_HASH)
+}
+
+func (o *session) SetTokenHash(tokenHash string) SessionInterface {
+	o.Set(COLUMN_TOKEN_HASH, tokenHash)
+	return o
+}
+
+func (o *session) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *session) UpdatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.UpdatedAt(), carbon.UTC)
+}
+
+func (o *session) SetUpdatedAt(updatedAt string) SessionInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}
+
+func (o *session) UserAgent() string {
+	return o.Get(COLUMN_USER_AGENT)
+}
+
+func (o *session) SetUserAgent(userAgent string) SessionInterface {
+	o.Set(COLUMN_USER_AGENT, userAgent)
+	return o
+}
+
+func (o *session) UserID() string {
+	return o.Get(COLUMN_USER_ID)
+}
+
+func (o *session) SetUserID(userID string) SessionInterface {
+	o.Set(COLUMN_USER_ID, userID)
+	return o
+}
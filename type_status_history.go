@@ -0,0 +1,58 @@
+package userstore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+)
+
+// == CLASS ===================================================================
+
+type statusHistory struct {
+	dataobject.DataObject
+}
+
+var _ StatusHistoryInterface = (*statusHistory)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewStatusHistoryFromExistingData(data map[string]string) StatusHistoryInterface {
+	o := &statusHistory{}
+	o.Hydrate(data)
+	return o
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+// ActorID returns the ID of the user, who changed the status,
+// or empty if changed by the system
+func (o *statusHistory) ActorID() string {
+	return o.Get(COLUMN_ACTOR_ID)
+}
+
+func (o *statusHistory) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *statusHistory) CreatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *statusHistory) FromStatus() string {
+	return o.Get(COLUMN_FROM_STATUS)
+}
+
+func (o *statusHistory) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *statusHistory) Reason() string {
+	return o.Get(COLUMN_REASON)
+}
+
+func (o *statusHistory) ToStatus() string {
+	return o.Get(COLUMN_TO_STATUS)
+}
+
+func (o *statusHistory) UserID() string {
+	return o.Get(COLUMN_USER_ID)
+}
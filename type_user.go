@@ -7,6 +7,7 @@ import (
 	"github.com/gouniverse/sb"
 	"github.com/gouniverse/uid"
 	"github.com/gouniverse/utils"
+	"github.com/spf13/cast"
 )
 
 // == CLASS ===================================================================
@@ -36,6 +37,15 @@ func NewUser() UserInterface {
 		SetTimezone("").
 		SetCountry("").
 		SetMemo("").
+		SetFailedLoginCount(0).
+		SetFailedLoginFirstAt(sb.NULL_DATETIME).
+		SetLockedUntil(sb.NULL_DATETIME).
+		SetLockoutCount(0).
+		SetSuspendedUntil(sb.NULL_DATETIME).
+		SetSuspendedBy("").
+		SetSuspensionReason("").
+		SetVerifiedAt(sb.NULL_DATETIME).
+		SetPhoneVerifiedAt(sb.NULL_DATETIME).
 		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
 		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
 		SetSoftDeletedAt(sb.MAX_DATETIME)
@@ -77,6 +87,18 @@ func (o *user) IsUnverified() bool {
 	return o.Status() == USER_STATUS_UNVERIFIED
 }
 
+// IsLocked returns true if the user is locked out due to too many
+// failed logins, and the lock has not expired yet
+func (o *user) IsLocked() bool {
+	return o.LockedUntilCarbon().Compare(">", carbon.Now(carbon.UTC))
+}
+
+// IsSuspended returns true if the user is suspended, and the
+// suspension has not expired yet
+func (o *user) IsSuspended() bool {
+	return o.SuspendedUntilCarbon().Compare(">", carbon.Now(carbon.UTC))
+}
+
 func (o *user) IsAdministrator() bool {
 	return o.Role() == USER_ROLE_ADMINISTRATOR
 }
@@ -171,6 +193,28 @@ func (o *user) SetEmail(email string) UserInterface {
 	return o
 }
 
+func (o *user) FailedLoginCount() int {
+	return cast.ToInt(o.Get(COLUMN_FAILED_LOGIN_COUNT))
+}
+
+func (o *user) SetFailedLoginCount(failedLoginCount int) UserInterface {
+	o.Set(COLUMN_FAILED_LOGIN_COUNT, cast.ToString(failedLoginCount))
+	return o
+}
+
+func (o *user) FailedLoginFirstAt() string {
+	return o.Get(COLUMN_FAILED_LOGIN_FIRST_AT)
+}
+
+func (o *user) FailedLoginFirstAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.FailedLoginFirstAt(), carbon.UTC)
+}
+
+func (o *user) SetFailedLoginFirstAt(failedLoginFirstAt string) UserInterface {
+	o.Set(COLUMN_FAILED_LOGIN_FIRST_AT, failedLoginFirstAt)
+	return o
+}
+
 func (o *user) FirstName() string {
 	return o.Get(COLUMN_FIRST_NAME)
 }
@@ -198,6 +242,28 @@ func (o *user) SetLastName(lastName string) UserInterface {
 	return o
 }
 
+func (o *user) LockedUntil() string {
+	return o.Get(COLUMN_LOCKED_UNTIL)
+}
+
+func (o *user) LockedUntilCarbon() *carbon.Carbon {
+	return carbon.Parse(o.LockedUntil(), carbon.UTC)
+}
+
+func (o *user) SetLockedUntil(lockedUntil string) UserInterface {
+	o.Set(COLUMN_LOCKED_UNTIL, lockedUntil)
+	return o
+}
+
+func (o *user) LockoutCount() int {
+	return cast.ToInt(o.Get(COLUMN_LOCKOUT_COUNT))
+}
+
+func (o *user) SetLockoutCount(lockoutCount int) UserInterface {
+	o.Set(COLUMN_LOCKOUT_COUNT, cast.ToString(lockoutCount))
+	return o
+}
+
 func (o *user) Memo() string {
 	return o.Get(COLUMN_MEMO)
 }
@@ -311,6 +377,38 @@ func (o *user) SetPhone(phone string) UserInterface {
 	return o
 }
 
+// SetPhoneAndNormalize normalizes the phone number to the E.164 format
+// before saving, using the country of the user as the default region.
+// Changing the phone number resets its verification.
+func (o *user) SetPhoneAndNormalize(phone string) error {
+	normalized, err := PhoneNormalize(phone, o.Country())
+
+	if err != nil {
+		return err
+	}
+
+	if normalized != o.Phone() {
+		o.SetPhoneVerifiedAt(sb.NULL_DATETIME)
+	}
+
+	o.SetPhone(normalized)
+
+	return nil
+}
+
+func (o *user) PhoneVerifiedAt() string {
+	return o.Get(COLUMN_PHONE_VERIFIED_AT)
+}
+
+func (o *user) PhoneVerifiedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.PhoneVerifiedAt(), carbon.UTC)
+}
+
+func (o *user) SetPhoneVerifiedAt(phoneVerifiedAt string) UserInterface {
+	o.Set(COLUMN_PHONE_VERIFIED_AT, phoneVerifiedAt)
+	return o
+}
+
 func (o *user) ProfileImageUrl() string {
 	return o.Get(COLUMN_PROFILE_IMAGE_URL)
 }
@@ -348,6 +446,38 @@ func (o *user) SetStatus(status string) UserInterface {
 	return o
 }
 
+// SuspendedBy returns the ID of the actor, who suspended the user
+func (o *user) SuspendedBy() string {
+	return o.Get(COLUMN_SUSPENDED_BY)
+}
+
+func (o *user) SetSuspendedBy(suspendedBy string) UserInterface {
+	o.Set(COLUMN_SUSPENDED_BY, suspendedBy)
+	return o
+}
+
+func (o *user) SuspendedUntil() string {
+	return o.Get(COLUMN_SUSPENDED_UNTIL)
+}
+
+func (o *user) SuspendedUntilCarbon() *carbon.Carbon {
+	return carbon.Parse(o.SuspendedUntil(), carbon.UTC)
+}
+
+func (o *user) SetSuspendedUntil(suspendedUntil string) UserInterface {
+	o.Set(COLUMN_SUSPENDED_UNTIL, suspendedUntil)
+	return o
+}
+
+func (o *user) SuspensionReason() string {
+	return o.Get(COLUMN_SUSPENSION_REASON)
+}
+
+func (o *user) SetSuspensionReason(suspensionReason string) UserInterface {
+	o.Set(COLUMN_SUSPENSION_REASON, suspensionReason)
+	return o
+}
+
 func (o *user) Timezone() string {
 	return o.Get(COLUMN_TIMEZONE)
 }
@@ -369,3 +499,16 @@ func (o *user) SetUpdatedAt(updatedAt string) UserInterface {
 	o.Set(COLUMN_UPDATED_AT, updatedAt)
 	return o
 }
+
+func (o *user) VerifiedAt() string {
+	return o.Get(COLUMN_VERIFIED_AT)
+}
+
+func (o *user) VerifiedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.VerifiedAt(), carbon.UTC)
+}
+
+func (o *user) SetVerifiedAt(verifiedAt string) UserInterface {
+	o.Set(COLUMN_VERIFIED_AT, verifiedAt)
+	return o
+}
@@ -0,0 +1,134 @@
+package userstore
+
+import (
+	"strings"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/uid"
+	"github.com/samber/lo"
+)
+
+// == CLASS ===================================================================
+
+type webhook struct {
+	dataobject.DataObject
+}
+
+var _ WebhookInterface = (*webhook)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewWebhook() WebhookInterface {
+	o := &webhook{}
+
+	o.SetID(uid.HumanUid()).
+		SetURL("").
+		SetEventTypes([]string{}).
+		SetSecret("").
+		SetStatus(WEBHOOK_STATUS_ACTIVE).
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return o
+}
+
+func NewWebhookFromExistingData(data map[string]string) WebhookInterface {
+	o := &webhook{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+func (o *webhook) HasEventType(eventType string) bool {
+	return lo.Contains(o.EventTypes(), eventType)
+}
+
+func (o *webhook) IsActive() bool {
+	return o.Status() == WEBHOOK_STATUS_ACTIVE
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *webhook) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *webhook) CreatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *webhook) SetCreatedAt(createdAt string) WebhookInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+// EventTypes returns the event types delivered to the webhook,
+// the OUTBOX_EVENT_ constants
+func (o *webhook) EventTypes() []string {
+	eventTypes := strings.Split(o.Get(COLUMN_EVENT_TYPES), ",")
+
+	return lo.Compact(eventTypes)
+}
+
+// SetEventTypes stores the event types as a comma separated string
+func (o *webhook) SetEventTypes(eventTypes []string) WebhookInterface {
+	eventTypes = lo.Map(eventTypes, func(eventType string, _ int) string {
+		return strings.TrimSpace(eventType)
+	})
+
+	o.Set(COLUMN_EVENT_TYPES, strings.Join(lo.Uniq(lo.Compact(eventTypes)), ","))
+	return o
+}
+
+func (o *webhook) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *webhook) SetID(id string) WebhookInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+// Secret returns the signing secret, as stored (encrypted)
+func (o *webhook) Secret() string {
+	return o.Get(COLUMN_SECRET)
+}
+
+// SetSecret sets the signing secret, it is expected to be already encrypted
+func (o *webhook) SetSecret(secret string) WebhookInterface {
+	o.Set(COLUMN_SECRET, secret)
+	return o
+}
+
+func (o *webhook) Status() string {
+	return o.Get(COLUMN_STATUS)
+}
+
+func (o *webhook) SetStatus(status string) WebhookInterface {
+	o.Set(COLUMN_STATUS, status)
+	return o
+}
+
+func (o *webhook) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *webhook) UpdatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.UpdatedAt(), carbon.UTC)
+}
+
+func (o *webhook) SetUpdatedAt(updatedAt string) WebhookInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}
+
+func (o *webhook) URL() string {
+	return o.Get(COLUMN_URL)
+}
+
+func (o *webhook) SetURL(url string) WebhookInterface {
+	o.Set(COLUMN_URL, url)
+	return o
+}
@@ -0,0 +1,111 @@
+package userstore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/spf13/cast"
+)
+
+// == CLASS ===================================================================
+
+type webhookDelivery struct {
+	dataobject.DataObject
+}
+
+var _ WebhookDeliveryInterface = (*webhookDelivery)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewWebhookDeliveryFromExistingData(data map[string]string) WebhookDeliveryInterface {
+	o := &webhookDelivery{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+func (o *webhookDelivery) IsDead() bool {
+	return o.Status() == WEBHOOK_DELIVERY_STATUS_DEAD
+}
+
+func (o *webhookDelivery) IsDelivered() bool {
+	return o.Status() == WEBHOOK_DELIVERY_STATUS_DELIVERED
+}
+
+func (o *webhookDelivery) IsPending() bool {
+	return o.Status() == WEBHOOK_DELIVERY_STATUS_PENDING
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *webhookDelivery) Attempts() int {
+	return cast.ToInt(o.Get(COLUMN_ATTEMPTS))
+}
+
+func (o *webhookDelivery) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *webhookDelivery) CreatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *webhookDelivery) DeliveredAt() string {
+	return o.Get(COLUMN_DELIVERED_AT)
+}
+
+func (o *webhookDelivery) DeliveredAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.DeliveredAt(), carbon.UTC)
+}
+
+func (o *webhookDelivery) EventID() string {
+	return o.Get(COLUMN_EVENT_ID)
+}
+
+func (o *webhookDelivery) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+// LastError returns the error of the last failed attempt
+func (o *webhookDelivery) LastError() string {
+	return o.Get(COLUMN_LAST_ERROR)
+}
+
+func (o *webhookDelivery) NextAttemptAt() string {
+	return o.Get(COLUMN_NEXT_ATTEMPT_AT)
+}
+
+func (o *webhookDelivery) NextAttemptAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.NextAttemptAt(), carbon.UTC)
+}
+
+// Payload returns the request body, the event as JSON
+func (o *webhookDelivery) Payload() string {
+	return o.Get(COLUMN_PAYLOAD)
+}
+
+// Status returns one of the WEBHOOK_DELIVERY_STATUS_ constants
+func (o *webhookDelivery) Status() string {
+	return o.Get(COLUMN_STATUS)
+}
+
+// Type returns the type of the event, one of the OUTBOX_EVENT_ constants
+func (o *webhookDelivery) Type() string {
+	return o.Get(COLUMN_TYPE)
+}
+
+func (o *webhookDelivery) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *webhookDelivery) UpdatedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.UpdatedAt(), carbon.UTC)
+}
+
+func (o *webhookDelivery) UserID() string {
+	return o.Get(COLUMN_USER_ID)
+}
+
+func (o *webhookDelivery) WebhookID() string {
+	return o.Get(COLUMN_WEBHOOK_ID)
+}